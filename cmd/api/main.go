@@ -1,9 +1,13 @@
 package main
 
 import (
-	_ "bisnode/docs"
+	"bisnode/docs"
 	"bisnode/internal/config"
+	"bisnode/internal/enrich"
+	"bisnode/internal/geo"
 	"bisnode/internal/handlers"
+	"bisnode/internal/jobs"
+	"bisnode/internal/openapi"
 	"bisnode/internal/routes"
 	bisnodeservice "bisnode/internal/services/bisnode"
 	"context"
@@ -11,10 +15,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
-
-	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 // @title           Bisnode API
@@ -42,11 +45,69 @@ func main() {
 	motorVehicleClient := bisnodeservice.NewMotorVehicleClient(&cfg.Bisnode)
 
 	// Initialize services
-	directoryService := bisnodeservice.NewDirectoryService(directoryClient)
+	directoryService := bisnodeservice.NewDirectoryService(directoryClient, &cfg.Search)
+	motorVehicleService := bisnodeservice.NewMotorVehicleService(motorVehicleClient)
+
+	// Wrap the lookups in caching, then metrics, then auditing, so cached
+	// responses are counted and every lookup is audited. Services built on
+	// the lookups, such as geo search and screening, must use the wrapped ones.
+	var persons bisnodeservice.PersonSearcher = bisnodeservice.NewCachedPersonSearcher(directoryService, &cfg.Cache)
+	var organizations bisnodeservice.OrganizationLookup = bisnodeservice.NewCachedOrganizationLookup(directoryService, &cfg.Cache)
+	var vehicles bisnodeservice.VehicleLookup = bisnodeservice.NewCachedVehicleLookup(motorVehicleService, &cfg.Cache)
+
+	metrics := bisnodeservice.NewMetrics()
+	persons = bisnodeservice.NewMeteredPersonSearcher(persons, metrics)
+	organizations = bisnodeservice.NewMeteredOrganizationLookup(organizations, metrics)
+	vehicles = bisnodeservice.NewMeteredVehicleLookup(vehicles, metrics)
+
+	if cfg.Audit.File != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.Audit.File), 0o755); err != nil {
+			log.Fatalf("Failed to create audit log directory: %v", err)
+		}
+		auditFile, err := os.OpenFile(cfg.Audit.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer auditFile.Close()
+
+		auditLog := bisnodeservice.NewAuditLog(auditFile)
+		persons = bisnodeservice.NewAuditedPersonSearcher(persons, auditLog)
+		organizations = bisnodeservice.NewAuditedOrganizationLookup(organizations, auditLog)
+		vehicles = bisnodeservice.NewAuditedVehicleLookup(vehicles, auditLog)
+	}
+
+	// Geo search needs a zip code index and is disabled without one
+	var zipIndex *geo.ZipIndex
+	if cfg.Geo.ZipCodesFile != "" {
+		zipIndex, err = geo.LoadZipIndex(cfg.Geo.ZipCodesFile)
+		if err != nil {
+			log.Fatalf("Failed to load zip codes: %v", err)
+		}
+		log.Printf("Loaded %d zip codes for geo search", zipIndex.Len())
+	}
+	geoService := bisnodeservice.NewGeoSearchService(persons, zipIndex, &cfg.Geo, &cfg.Search)
+	screeningService := bisnodeservice.NewScreeningService(persons, &cfg.Screening)
+	batchService := bisnodeservice.NewBatchService(persons, organizations, vehicles, &cfg.Batch)
+
+	// CSV uploads are enriched within the request, so they are limited to the batch size
+	enricher := enrich.New(batchService.LookupItem, cfg.Batch.Concurrency, cfg.Batch.MaxItems)
+
+	// Jobs left unfinished by the last shutdown resume here
+	jobManager, err := jobs.NewManager(&cfg.Jobs, handlers.NewBatchJobProcessor(batchService))
+	if err != nil {
+		log.Fatalf("Failed to open jobs: %v", err)
+	}
 
 	// Initialize handlers
-	directoryHandler := handlers.NewDirectoryHandler(directoryService)
-	motorVehicleHandler := handlers.NewMotorVehicleHandler(motorVehicleClient)
+	directoryHandler := handlers.NewDirectoryHandler(persons, organizations)
+	motorVehicleHandler := handlers.NewMotorVehicleHandler(vehicles)
+	geoHandler := handlers.NewGeoHandler(geoService)
+	screeningHandler := handlers.NewScreeningHandler(screeningService)
+	batchHandler := handlers.NewBatchHandler(batchService)
+	jobHandler := handlers.NewJobHandler(jobManager)
+	enrichHandler := handlers.NewEnrichHandler(enricher)
+	metricsHandler := handlers.NewMetricsHandler(metrics)
+	docsHandler := handlers.NewDocsHandler(docs.OpenAPI)
 
 	// Setup router
 	mux := http.NewServeMux()
@@ -54,25 +115,31 @@ func main() {
 	// Register routes
 	routes.RegisterDirectoryRoutes(mux, directoryHandler)
 	routes.RegisterMotorVehicleRoutes(mux, motorVehicleHandler)
-
-	// Swagger documentation
-	docURL := "/swagger/doc.json"
-	mux.Handle("/swagger/", httpSwagger.Handler(
-		httpSwagger.URL(docURL), // The url pointing to API definition
-		httpSwagger.DeepLinking(true),
-		httpSwagger.DocExpansion("none"),
-		httpSwagger.DomID("swagger-ui"),
-		httpSwagger.PersistAuthorization(true),
-	))
-
-	// Serve the swagger.json file
-	mux.HandleFunc(docURL, func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		http.ServeFile(w, r, "./docs/swagger.json")
+	routes.RegisterGeoRoutes(mux, geoHandler)
+	routes.RegisterScreeningRoutes(mux, screeningHandler)
+	routes.RegisterBatchRoutes(mux, batchHandler)
+	routes.RegisterJobRoutes(mux, jobHandler)
+	routes.RegisterEnrichRoutes(mux, enrichHandler)
+	routes.RegisterMetricsRoutes(mux, metricsHandler)
+	routes.RegisterDocsRoutes(mux, docsHandler)
+
+	// Swagger UI showing /openapi.json, also at the old /swagger/index.html
+	mux.HandleFunc("GET /swagger/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(docs.SwaggerUI)
 	})
 
-	router := mux
+	router := handlers.AuditCaller(mux)
+
+	// Check requests and responses against the OpenAPI document, for staging
+	if cfg.Contract.Mode != "" {
+		doc, err := openapi.Parse(docs.OpenAPI)
+		if err != nil {
+			log.Fatalf("Failed to load OpenAPI document: %v", err)
+		}
+		router = openapi.ValidateContract(router, doc, openapi.ContractOptions{Fail: cfg.Contract.Mode == "fail"})
+		log.Printf("Checking requests and responses against the OpenAPI document (%s mode)", cfg.Contract.Mode)
+	}
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -107,5 +174,8 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Stop running jobs; they resume on the next start
+	jobManager.Close()
+
 	log.Println("Server exited properly")
 }
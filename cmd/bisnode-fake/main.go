@@ -0,0 +1,53 @@
+// Command bisnode-fake serves a fake Bisnode API for local development.
+//
+// Usage:
+//
+//	bisnode-fake [-addr :9090] [-fixtures fixtures.json] [-latency 200ms] [-user id -password secret]
+//
+// Point bisnode.base_url in config.json at the fake to run the API server
+// without Bisnode credentials. Faults are injected and recorded requests are
+// inspected through /_fake/faults and /_fake/requests.
+package main
+
+import (
+	"bisnode/internal/bisnodefake"
+	"flag"
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	fixturesPath := flag.String("fixtures", "", "JSON file with directory and vehicle fixtures (default built-in fixtures)")
+	latency := flag.Duration("latency", 0, "latency added to every response")
+	user := flag.String("user", "", "client ID required in basic auth (default any credentials are accepted)")
+	password := flag.String("password", "", "client secret required in basic auth")
+	failStatus := flag.Int("fail-status", 0, "respond to every search with this HTTP status, such as 401, 429 or 503")
+	malformed := flag.Bool("malformed", false, "respond to every search with malformed JSON")
+	flag.Parse()
+
+	opts := bisnodefake.Options{
+		ClientID:     *user,
+		ClientSecret: *password,
+		Latency:      *latency,
+	}
+	if *fixturesPath != "" {
+		fixtures, err := bisnodefake.LoadFixtures(*fixturesPath)
+		if err != nil {
+			log.Fatalf("Failed to load fixtures: %v", err)
+		}
+		opts.Fixtures = fixtures
+	}
+
+	server := bisnodefake.New(opts)
+	if *failStatus != 0 || *malformed {
+		if err := server.Fail(bisnodefake.Fault{Status: *failStatus, Malformed: *malformed}); err != nil {
+			log.Fatalf("Invalid -fail-status: %v", err)
+		}
+	}
+
+	log.Printf("Fake Bisnode API listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
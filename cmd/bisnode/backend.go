@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bisnode/internal/config"
+	"bisnode/internal/domain"
+	"bisnode/internal/models"
+	bisnodeservice "bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// backend performs lookups, either directly against Bisnode or through a running API server
+type backend interface {
+	person(ctx context.Context, mobileNumber string) (domain.SearchResult, error)
+	persons(ctx context.Context, query models.PersonSearchQuery) (domain.SearchResult, error)
+	organization(ctx context.Context, orgNo string) (domain.SearchResult, error)
+	vehicle(ctx context.Context, id string) (domain.VehicleSearchResult, error)
+	batch(ctx context.Context, items []bisnodeservice.BatchItem) ([]batchRow, error)
+}
+
+// batchRow is the outcome of one batch item as printed by the CLI
+type batchRow struct {
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Status string `json:"status"`
+	// Name is the name of the first listing, or the brand and model of the first vehicle
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// options are the flags shared by every command
+type options struct {
+	format   string
+	server   string
+	user     string
+	password string
+}
+
+// addOptions registers the shared flags on fs
+func addOptions(fs *flag.FlagSet) *options {
+	o := &options{}
+	fs.StringVar(&o.format, "format", "table", "output format: table, json or csv")
+	fs.StringVar(&o.server, "server", os.Getenv("BISNODE_SERVER"), "URL of a running API server; without it Bisnode is called directly using config.json")
+	fs.StringVar(&o.user, "user", os.Getenv("BISNODE_USER"), "user name for the API server")
+	fs.StringVar(&o.password, "password", os.Getenv("BISNODE_PASSWORD"), "password for the API server")
+	return o
+}
+
+// backend returns the backend selected by the flags
+func (o *options) backend() (backend, error) {
+	switch o.format {
+	case "table", "json", "csv":
+	default:
+		return nil, usagef("-format must be one of table, json, csv")
+	}
+
+	if o.server != "" {
+		return newRemoteBackend(o.server, o.user, o.password), nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return newLocalBackend(cfg), nil
+}
+
+// localBackend calls Bisnode directly through the services used by the API server
+type localBackend struct {
+	directory     bisnodeservice.PersonSearcher
+	organizations bisnodeservice.OrganizationLookup
+	vehicles      bisnodeservice.VehicleLookup
+	batches       *bisnodeservice.BatchService
+}
+
+// newLocalBackend creates the services from the configuration
+func newLocalBackend(cfg *config.Config) *localBackend {
+	directoryClient := bisnodeservice.NewDirectoryClient(&cfg.Bisnode)
+	motorVehicleClient := bisnodeservice.NewMotorVehicleClient(&cfg.Bisnode)
+	directoryService := bisnodeservice.NewDirectoryService(directoryClient, &cfg.Search)
+	motorVehicleService := bisnodeservice.NewMotorVehicleService(motorVehicleClient)
+
+	persons := bisnodeservice.NewCachedPersonSearcher(directoryService, &cfg.Cache)
+	organizations := bisnodeservice.NewCachedOrganizationLookup(directoryService, &cfg.Cache)
+	vehicles := bisnodeservice.NewCachedVehicleLookup(motorVehicleService, &cfg.Cache)
+
+	return &localBackend{
+		directory:     persons,
+		organizations: organizations,
+		vehicles:      vehicles,
+		batches:       bisnodeservice.NewBatchService(persons, organizations, vehicles, &cfg.Batch),
+	}
+}
+
+func (b *localBackend) person(ctx context.Context, mobileNumber string) (domain.SearchResult, error) {
+	result, err := b.directory.SearchByMobileNumber(ctx, mobileNumber, models.SearchOptions{})
+	if err != nil {
+		return domain.SearchResult{}, err
+	}
+	return domain.NewSearchResult(&result.DirectorySearchResponse), nil
+}
+
+func (b *localBackend) persons(ctx context.Context, query models.PersonSearchQuery) (domain.SearchResult, error) {
+	result, err := b.directory.SearchPersons(ctx, query)
+	if err != nil {
+		return domain.SearchResult{}, err
+	}
+	return domain.NewSearchResult(&result.DirectorySearchResponse), nil
+}
+
+func (b *localBackend) organization(ctx context.Context, orgNo string) (domain.SearchResult, error) {
+	result, err := b.organizations.SearchByOrganizationNumber(ctx, orgNo)
+	if err != nil {
+		return domain.SearchResult{}, err
+	}
+	return domain.NewSearchResult(&result.DirectorySearchResponse), nil
+}
+
+func (b *localBackend) vehicle(ctx context.Context, id string) (domain.VehicleSearchResult, error) {
+	result, err := b.vehicles.SearchByLicenseNumber(ctx, id)
+	if err != nil {
+		return domain.VehicleSearchResult{}, err
+	}
+	return domain.NewVehicleSearchResult(&result.MotorVehicleSearchResponse, result.Identifier), nil
+}
+
+func (b *localBackend) batch(ctx context.Context, items []bisnodeservice.BatchItem) ([]batchRow, error) {
+	outcomes, err := b.batches.Lookup(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]batchRow, len(outcomes))
+	for i, o := range outcomes {
+		row := batchRow{Type: string(o.Item.Type), Value: o.Item.Value}
+		switch {
+		case o.Err != nil:
+			row.Status = "error"
+			if _, ok := validation.As(o.Err); ok {
+				row.Status = "invalid"
+			}
+			row.Error = o.Err.Error()
+		case !o.Found():
+			row.Status = "not_found"
+		default:
+			row.Status = "ok"
+			if o.Directory != nil {
+				row.Name = domain.NewListing(o.Directory.Result[0]).Name
+			} else {
+				row.Name = vehicleName(o.Vehicle.Result[0])
+			}
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// vehicleName describes a vehicle as its brand and model
+func vehicleName(v models.MotorVehicle) string {
+	return strings.TrimSpace(strings.TrimSpace(v.BrandName) + " " + strings.TrimSpace(v.Model))
+}
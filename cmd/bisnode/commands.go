@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bisnode/internal/models"
+	bisnodeservice "bisnode/internal/services/bisnode"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// runPerson looks up a person by mobile number, or searches by name and address
+func runPerson(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("person", flag.ContinueOnError)
+	opts := addOptions(fs)
+	var query models.PersonSearchQuery
+	fs.StringVar(&query.FirstName, "first", "", "first name")
+	fs.StringVar(&query.LastName, "last", "", "last name")
+	fs.StringVar(&query.Street, "street", "", "street name, optionally followed by house number")
+	fs.StringVar(&query.ZipCode, "zip", "", "four digit zip code")
+	fs.StringVar(&query.City, "city", "", "city")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: bisnode person [flags] <mobile number>")
+		fmt.Fprintln(fs.Output(), "       bisnode person [flags] -first <name> -last <name> [-street ...] [-zip ...] [-city ...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	byName := query.FirstName != "" || query.LastName != "" || query.Street != ""
+	switch {
+	case byName && fs.NArg() > 0:
+		return usagef("a mobile number cannot be combined with name and address flags")
+	case !byName && fs.NArg() != 1:
+		return usagef("expected one mobile number, or name and address flags")
+	}
+
+	b, err := opts.backend()
+	if err != nil {
+		return err
+	}
+
+	if byName {
+		result, err := b.persons(ctx, query)
+		if err != nil {
+			return err
+		}
+		return printListings(stdout, opts.format, result)
+	}
+
+	result, err := b.person(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printListings(stdout, opts.format, result)
+}
+
+// runOrganization looks up an organization by organization number
+func runOrganization(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("org", flag.ContinueOnError)
+	opts := addOptions(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: bisnode org [flags] <organization number>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("expected one organization number")
+	}
+
+	b, err := opts.backend()
+	if err != nil {
+		return err
+	}
+
+	result, err := b.organization(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printListings(stdout, opts.format, result)
+}
+
+// runVehicle looks up a vehicle by license plate or VIN
+func runVehicle(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("vehicle", flag.ContinueOnError)
+	opts := addOptions(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: bisnode vehicle [flags] <license plate or VIN>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("expected one license plate or VIN")
+	}
+
+	b, err := opts.backend()
+	if err != nil {
+		return err
+	}
+
+	result, err := b.vehicle(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printVehicles(stdout, opts.format, result)
+}
+
+// runBatch looks up a list of type,value lines, such as "orgno,923609016"
+func runBatch(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	opts := addOptions(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: bisnode batch [flags] [file]")
+		fmt.Fprintln(fs.Output(), "Reads lines of type,value from file or standard input, where type is orgno, mobile, plate or vin.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usagef("expected at most one file")
+	}
+
+	var in io.Reader = os.Stdin
+	if fs.NArg() == 1 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	items, err := readBatchItems(in)
+	if err != nil {
+		return err
+	}
+
+	b, err := opts.backend()
+	if err != nil {
+		return err
+	}
+
+	rows, err := b.batch(ctx, items)
+	if err != nil {
+		return err
+	}
+	if err := printBatch(stdout, opts.format, rows); err != nil {
+		return err
+	}
+
+	return batchResult(rows)
+}
+
+// readBatchItems reads lines of type,value. Blank lines, lines starting with
+// # and a type,value header are skipped.
+func readBatchItems(in io.Reader) ([]bisnodeservice.BatchItem, error) {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	var items []bisnodeservice.BatchItem
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, usagef("invalid input: %v", err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(record) != 2 {
+			return nil, usagef("line %d: expected type,value", line)
+		}
+
+		itemType := strings.ToLower(strings.TrimSpace(record[0]))
+		if len(items) == 0 && itemType == "type" {
+			continue
+		}
+		items = append(items, bisnodeservice.BatchItem{
+			Type:  bisnodeservice.BatchItemType(itemType),
+			Value: strings.TrimSpace(record[1]),
+		})
+	}
+
+	if len(items) == 0 {
+		return nil, usagef("no items to look up")
+	}
+	return items, nil
+}
+
+// batchResult returns an error when any item failed, or errNotFound when any item was not found
+func batchResult(rows []batchRow) error {
+	var failed, notFound int
+	for _, row := range rows {
+		switch row.Status {
+		case "ok":
+		case "not_found":
+			notFound++
+		default:
+			failed++
+		}
+	}
+
+	switch {
+	case failed > 0:
+		return fmt.Errorf("%d of %d items failed", failed, len(rows))
+	case notFound > 0:
+		return fmt.Errorf("%d of %d items: %w", notFound, len(rows), errNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Command bisnode looks up persons, organizations and vehicles from the
+// command line.
+//
+// Usage:
+//
+//	bisnode person [flags] <mobile number>
+//	bisnode person [flags] -first Ola -last Nordmann [-street ...] [-zip ...] [-city ...]
+//	bisnode org [flags] <organization number>
+//	bisnode vehicle [flags] <license plate or VIN>
+//	bisnode batch [flags] [file]
+//
+// By default the Bisnode API is called directly, using config.json in the
+// working directory. With -server, the lookups are sent to a running API
+// server instead.
+//
+// Exit codes: 0 when everything was found, 3 when something was not found,
+// 2 for usage errors and invalid input, and 1 for any other error.
+package main
+
+import (
+	"bisnode/internal/validation"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+// errNotFound is returned by commands when a lookup had no result
+var errNotFound = errors.New("not found")
+
+// command is a subcommand of the CLI
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string, stdout io.Writer) error
+}
+
+var commands = []command{
+	{name: "person", summary: "look up a person by mobile number, or search by name and address", run: runPerson},
+	{name: "org", summary: "look up an organization by organization number", run: runOrganization},
+	{name: "vehicle", summary: "look up a vehicle by license plate or VIN", run: runVehicle},
+	{name: "batch", summary: "look up a list of type,value lines from a file or standard input", run: runBatch},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr)
+		return exitUsage
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		err := cmd.run(ctx, args[1:], stdout)
+		switch {
+		case err == nil:
+			return exitOK
+		case errors.Is(err, flag.ErrHelp):
+			return exitUsage
+		case errors.Is(err, errNotFound):
+			fmt.Fprintf(stderr, "bisnode %s: %v\n", cmd.name, err)
+			return exitNotFound
+		case errors.As(err, new(*usageError)), errors.As(err, new(*validation.Error)):
+			fmt.Fprintf(stderr, "bisnode %s: %v\n", cmd.name, err)
+			return exitUsage
+		default:
+			fmt.Fprintf(stderr, "bisnode %s: %v\n", cmd.name, err)
+			return exitError
+		}
+	}
+
+	fmt.Fprintf(stderr, "bisnode: unknown command %q\n\n", args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+// usage prints the list of commands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: bisnode <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run bisnode <command> -h for the flags of a command.")
+}
+
+// usageError is returned for invalid arguments
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// usagef returns a usageError
+func usagef(format string, args ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bisnode/internal/domain"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printListings prints directory listings, returning errNotFound when there are none
+func printListings(w io.Writer, format string, result domain.SearchResult) error {
+	if format == "json" {
+		if err := printJSON(w, result); err != nil {
+			return err
+		}
+	} else {
+		header := []string{"type", "name", "organization_number", "address", "zip_code", "city", "mobile", "telephone"}
+		rows := make([][]string, len(result.Result))
+		for i, l := range result.Result {
+			rows[i] = []string{
+				string(l.Type), l.Name, l.OrganizationNumber, l.Address.Line(),
+				l.Address.ZipCode, l.Address.City, l.Mobile, l.Telephone,
+			}
+		}
+		if err := printRows(w, format, header, rows); err != nil {
+			return err
+		}
+	}
+
+	if len(result.Result) == 0 {
+		return errNotFound
+	}
+	return nil
+}
+
+// printVehicles prints motor vehicles, returning errNotFound when there are none
+func printVehicles(w io.Writer, format string, result domain.VehicleSearchResult) error {
+	if format == "json" {
+		if err := printJSON(w, result); err != nil {
+			return err
+		}
+	} else {
+		header := []string{"regno", "vin", "brand", "model", "model_year", "owner", "next_inspection"}
+		rows := make([][]string, len(result.Result))
+		for i, v := range result.Result {
+			rows[i] = []string{
+				v.RegNo, v.ChassisNo, v.BrandName, v.Model, v.ModelYear,
+				v.Owner.Name, v.NextInspectionDate,
+			}
+		}
+		if err := printRows(w, format, header, rows); err != nil {
+			return err
+		}
+	}
+
+	if len(result.Result) == 0 {
+		return errNotFound
+	}
+	return nil
+}
+
+// printBatch prints the outcome of every batch item
+func printBatch(w io.Writer, format string, rows []batchRow) error {
+	if format == "json" {
+		return printJSON(w, rows)
+	}
+
+	header := []string{"type", "value", "status", "name", "error"}
+	records := make([][]string, len(rows))
+	for i, r := range rows {
+		records[i] = []string{r.Type, r.Value, r.Status, r.Name, r.Error}
+	}
+	return printRows(w, format, header, records)
+}
+
+// printJSON prints v as indented JSON
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printRows prints a header and rows as CSV, or as an aligned table
+func printRows(w io.Writer, format string, header []string, rows [][]string) error {
+	if format == "csv" {
+		writer := csv.NewWriter(w)
+		writer.Write(header)
+		writer.WriteAll(rows)
+		return writer.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
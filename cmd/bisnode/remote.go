@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bisnode/internal/domain"
+	"bisnode/internal/handlers"
+	"bisnode/internal/models"
+	bisnodeservice "bisnode/internal/services/bisnode"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// remoteBackend sends lookups to a running API server
+type remoteBackend struct {
+	baseURL  string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// newRemoteBackend creates a backend for the API server at baseURL
+func newRemoteBackend(baseURL, user, password string) *remoteBackend {
+	return &remoteBackend{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (b *remoteBackend) person(ctx context.Context, mobileNumber string) (domain.SearchResult, error) {
+	var result domain.SearchResult
+	err := b.get(ctx, "/api/v1/directory/persons/search", url.Values{"mobileNumber": {mobileNumber}}, &result)
+	return result, err
+}
+
+func (b *remoteBackend) persons(ctx context.Context, query models.PersonSearchQuery) (domain.SearchResult, error) {
+	params := url.Values{}
+	for name, value := range map[string]string{
+		"firstName": query.FirstName,
+		"lastName":  query.LastName,
+		"street":    query.Street,
+		"zipCode":   query.ZipCode,
+		"city":      query.City,
+	} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+
+	var result domain.SearchResult
+	err := b.get(ctx, "/api/v1/directory/persons/search", params, &result)
+	return result, err
+}
+
+func (b *remoteBackend) organization(ctx context.Context, orgNo string) (domain.SearchResult, error) {
+	var result domain.SearchResult
+	err := b.get(ctx, "/api/v1/directory/organizations/search", url.Values{"organizationNumber": {orgNo}}, &result)
+	return result, err
+}
+
+func (b *remoteBackend) vehicle(ctx context.Context, id string) (domain.VehicleSearchResult, error) {
+	var result domain.VehicleSearchResult
+	err := b.get(ctx, "/api/v1/motor-vehicles/search", url.Values{"licenseNumber": {id}}, &result)
+	return result, err
+}
+
+func (b *remoteBackend) batch(ctx context.Context, items []bisnodeservice.BatchItem) ([]batchRow, error) {
+	req := handlers.BatchRequest{Items: make([]handlers.BatchItemRequest, len(items))}
+	for i, item := range items {
+		req.Items[i] = handlers.BatchItemRequest{Type: string(item.Type), Value: item.Value}
+	}
+
+	var resp struct {
+		Items []struct {
+			handlers.BatchItemResult
+			Result json.RawMessage `json:"result"`
+		} `json:"items"`
+	}
+	if err := b.do(ctx, http.MethodPost, "/api/v1/batch", nil, req, &resp); err != nil {
+		return nil, err
+	}
+
+	rows := make([]batchRow, len(resp.Items))
+	for i, item := range resp.Items {
+		row := batchRow{Type: item.Type, Value: item.Value, Status: string(item.Status), Error: item.Error}
+		if len(item.Details) > 0 {
+			row.Error = fmt.Sprintf("%s %s", item.Details[0].Field, item.Details[0].Message)
+		}
+		if item.Status == handlers.BatchItemOK {
+			row.Name = batchResultName(item.Type, item.Result)
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// batchResultName returns the name of the first match in a batch item result
+func batchResultName(itemType string, raw json.RawMessage) string {
+	if itemType == string(bisnodeservice.BatchLicensePlate) || itemType == string(bisnodeservice.BatchVIN) {
+		var vehicle models.MotorVehicleSearchResponse
+		if json.Unmarshal(raw, &vehicle) == nil && len(vehicle.Result) > 0 {
+			return vehicleName(vehicle.Result[0])
+		}
+		return ""
+	}
+
+	var result domain.SearchResult
+	if json.Unmarshal(raw, &result) == nil && len(result.Result) > 0 {
+		return result.Result[0].Name
+	}
+	return ""
+}
+
+// get sends a GET request with query parameters and decodes the JSON response into dst
+func (b *remoteBackend) get(ctx context.Context, path string, params url.Values, dst interface{}) error {
+	return b.do(ctx, http.MethodGet, path, params, nil, dst)
+}
+
+// do sends a request to the API server and decodes the JSON response into dst
+func (b *remoteBackend) do(ctx context.Context, method, path string, params url.Values, body, dst interface{}) error {
+	target := b.baseURL + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.user != "" {
+		req.SetBasicAuth(b.user, b.password)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return remoteError(resp.StatusCode, data)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// remoteError describes an error response from the API server
+func remoteError(status int, body []byte) error {
+	var errResp handlers.ErrorResponse
+	if json.Unmarshal(body, &errResp) != nil || errResp.Error == "" {
+		return fmt.Errorf("server returned status %d: %s", status, strings.TrimSpace(string(body)))
+	}
+
+	message := errResp.Error
+	for _, d := range errResp.Details {
+		message += fmt.Sprintf("; %s %s", d.Field, d.Message)
+	}
+	if status == http.StatusBadRequest {
+		return usagef("%s", message)
+	}
+	return fmt.Errorf("server returned status %d: %s", status, message)
+}
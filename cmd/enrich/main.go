@@ -0,0 +1,96 @@
+// Command enrich appends directory and motor vehicle data to a CSV file.
+//
+// Usage:
+//
+//	enrich [-in file.csv] [-out enriched.csv] [-column header] [-type orgno|mobile|plate|vin] [-fields name,address]
+//
+// The file is read from standard input and written to standard output unless
+// -in and -out are given. It uses the Bisnode credentials in config.json in
+// the working directory, like the API server.
+package main
+
+import (
+	"bisnode/internal/config"
+	"bisnode/internal/enrich"
+	bisnodeservice "bisnode/internal/services/bisnode"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+func main() {
+	in := flag.String("in", "", "CSV file to enrich (default standard input)")
+	out := flag.String("out", "", "file to write the enriched CSV to (default standard output)")
+	column := flag.String("column", "", "header of the column to look up (default guessed from the header)")
+	itemType := flag.String("type", "", "lookup held by the column: orgno, mobile, plate or vin (default guessed from the header)")
+	fields := flag.String("fields", "", "comma separated fields to append (default depends on the type)")
+	concurrency := flag.Int("concurrency", 0, "rows looked up in parallel (default batch.concurrency from config.json)")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if *concurrency <= 0 {
+		*concurrency = cfg.Batch.Concurrency
+	}
+
+	directoryClient := bisnodeservice.NewDirectoryClient(&cfg.Bisnode)
+	motorVehicleClient := bisnodeservice.NewMotorVehicleClient(&cfg.Bisnode)
+	directoryService := bisnodeservice.NewDirectoryService(directoryClient, &cfg.Search)
+	motorVehicleService := bisnodeservice.NewMotorVehicleService(motorVehicleClient)
+	batchService := bisnodeservice.NewBatchService(
+		bisnodeservice.NewCachedPersonSearcher(directoryService, &cfg.Cache),
+		bisnodeservice.NewCachedOrganizationLookup(directoryService, &cfg.Cache),
+		bisnodeservice.NewCachedVehicleLookup(motorVehicleService, &cfg.Cache),
+		&cfg.Batch,
+	)
+
+	// Files from the command line are not limited to the API's batch size
+	enricher := enrich.New(batchService.LookupItem, *concurrency, 0)
+
+	var reader io.Reader = os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			log.Fatalf("Failed to open input: %v", err)
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	var writer io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create output: %v", err)
+		}
+		defer f.Close()
+		writer = f
+	}
+
+	opts := enrich.Options{
+		Column: *column,
+		Type:   bisnodeservice.BatchItemType(strings.ToLower(*itemType)),
+	}
+	if *fields != "" {
+		opts.Fields = strings.Split(*fields, ",")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	summary, err := enricher.Enrich(ctx, reader, writer, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "enrich: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d rows: %d enriched, %d not found, %d failed\n",
+		summary.Rows, summary.Enriched, summary.NotFound, summary.Failed)
+}
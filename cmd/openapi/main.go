@@ -0,0 +1,36 @@
+// Command openapi generates the OpenAPI 3.1 document of the API from the
+// handler annotations and the Go types of requests and responses.
+//
+// Usage:
+//
+//	openapi [-root dir] [-o openapi.json]
+//
+// It is run by go generate in the docs package.
+package main
+
+import (
+	"bisnode/internal/openapi/generator"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+)
+
+func main() {
+	root := flag.String("root", ".", "root directory of the module")
+	out := flag.String("o", "docs/openapi.json", "file to write the document to")
+	flag.Parse()
+
+	doc, err := generator.Generate(*root)
+	if err != nil {
+		log.Fatalf("Failed to generate OpenAPI document: %v", err)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode OpenAPI document: %v", err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+		log.Fatalf("Failed to write OpenAPI document: %v", err)
+	}
+}
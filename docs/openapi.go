@@ -0,0 +1,16 @@
+package docs
+
+import _ "embed"
+
+//go:generate go run ../cmd/openapi -root .. -o openapi.json
+
+// OpenAPI is the OpenAPI 3.1 document of the API, generated from the handlers
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// SwaggerUI is a Swagger UI page showing the OpenAPI document. Swagger UI 5
+// is loaded from a CDN, since older versions cannot render OpenAPI 3.1.
+//
+//go:embed swagger.html
+var SwaggerUI []byte
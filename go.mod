@@ -3,21 +3,3 @@ module bisnode
 go 1.23.0
 
 toolchain go1.23.3
-
-require (
-	github.com/KyleBanks/depth v1.2.1
-	github.com/go-openapi/jsonpointer v0.21.1
-	github.com/go-openapi/jsonreference v0.21.0
-	github.com/go-openapi/spec v0.21.0
-	github.com/go-openapi/swag v0.23.1
-	github.com/josharian/intern v1.0.0
-	github.com/mailru/easyjson v0.9.0
-	github.com/swaggo/files v1.0.1
-	github.com/swaggo/http-swagger v1.3.4
-	github.com/swaggo/swag v1.16.4
-	golang.org/x/net v0.40.0
-	golang.org/x/sys v0.33.0
-	golang.org/x/tools v0.33.0
-	gopkg.in/yaml.v2 v2.4.0
-	gopkg.in/yaml.v3 v3.0.1
-)
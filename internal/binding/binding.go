@@ -0,0 +1,220 @@
+package binding
+
+import (
+	"bisnode/internal/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxBodyBytes is the largest request body accepted by Bind
+const DefaultMaxBodyBytes = 1 << 20 // 1 MiB
+
+// ErrBodyTooLarge is returned when the request body exceeds the configured limit
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// Binder decodes HTTP requests into typed request structs
+type Binder struct {
+	// MaxBodyBytes limits the size of JSON bodies
+	MaxBodyBytes int64
+}
+
+// Default is the Binder used by Bind
+var Default = Binder{MaxBodyBytes: DefaultMaxBodyBytes}
+
+// Bind decodes and validates r into dst using the Default binder
+func Bind(r *http.Request, dst interface{}) error {
+	return Default.Bind(r, dst)
+}
+
+// Bind decodes r into dst and validates the result.
+// GET and DELETE requests are read from the query string using the `query`
+// struct tag, which may list aliases separated by commas. Other methods are
+// read from the JSON body, rejecting unknown fields and bodies larger than
+// MaxBodyBytes; fields the body leaves empty are then filled from the query
+// string, so the body may be omitted when the query string is given.
+// Validation is described by the `validate` struct tag; see Validate.
+func (b Binder) Bind(r *http.Request, dst interface{}) error {
+	var err error
+	if r.Method == http.MethodGet || r.Method == http.MethodDelete {
+		err = bindQuery(r, dst, false)
+	} else if err = b.bindJSON(r, dst); err == nil {
+		err = bindQuery(r, dst, true)
+	}
+	if err != nil {
+		return err
+	}
+
+	return Validate(dst)
+}
+
+// bindJSON decodes the request body into dst
+func (b Binder) bindJSON(r *http.Request, dst interface{}) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		if r.URL.RawQuery != "" {
+			return nil
+		}
+		return &DecodeError{Message: "Request body is required"}
+	}
+
+	limit := b.MaxBodyBytes
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, limit))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		if errors.Is(err, io.EOF) && r.URL.RawQuery != "" {
+			return nil
+		}
+		return decodeError(err)
+	}
+
+	// Reject trailing data so that concatenated objects are not silently ignored
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return ErrBodyTooLarge
+		}
+		return &DecodeError{Message: "Request body must contain a single JSON object"}
+	}
+
+	return nil
+}
+
+// decodeError translates JSON decoding errors into client friendly messages
+func decodeError(err error) error {
+	var maxErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &maxErr):
+		return ErrBodyTooLarge
+	case errors.Is(err, io.EOF):
+		return &DecodeError{Message: "Request body is required"}
+	case errors.As(err, &syntaxErr):
+		return &DecodeError{Message: fmt.Sprintf("Malformed JSON at position %d", syntaxErr.Offset), Err: err}
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		return &DecodeError{Message: "Request body must be a JSON object", Err: err}
+	case errors.As(err, &typeErr):
+		return &validation.Error{Fields: []validation.FieldError{{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		}}}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &validation.Error{Fields: []validation.FieldError{{Field: field, Message: "unknown field"}}}
+	default:
+		return &DecodeError{Message: "Invalid request payload", Err: err}
+	}
+}
+
+// bindQuery decodes the URL query string into the struct pointed to by dst.
+// With onlyEmpty, fields that already have a value are kept.
+func bindQuery(r *http.Request, dst interface{}, onlyEmpty bool) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: destination must be a pointer to a struct, got %T", dst)
+	}
+
+	if fieldErrs := bindQueryStruct(v.Elem(), r.URL.Query(), onlyEmpty); len(fieldErrs) > 0 {
+		return &validation.Error{Fields: fieldErrs}
+	}
+
+	return nil
+}
+
+// bindQueryStruct sets the fields of v from query, descending into embedded structs
+func bindQueryStruct(v reflect.Value, query url.Values, onlyEmpty bool) []validation.FieldError {
+	var fieldErrs []validation.FieldError
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fieldErrs = append(fieldErrs, bindQueryStruct(v.Field(i), query, onlyEmpty)...)
+			continue
+		}
+
+		tag := sf.Tag.Get("query")
+		if tag == "" || tag == "-" || !sf.IsExported() || (onlyEmpty && !v.Field(i).IsZero()) {
+			continue
+		}
+
+		names := strings.Split(tag, ",")
+		for _, name := range names {
+			values, ok := query[name]
+			if !ok || len(values) == 0 {
+				continue
+			}
+			if err := setField(v.Field(i), values); err != nil {
+				fieldErrs = append(fieldErrs, validation.FieldError{Field: names[0], Message: err.Error()})
+			}
+			break
+		}
+	}
+
+	return fieldErrs
+}
+
+// setField assigns the query values to a struct field of a supported kind
+func setField(f reflect.Value, values []string) error {
+	if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.String {
+		var items []string
+		for _, v := range values {
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		f.Set(reflect.ValueOf(items).Convert(f.Type()))
+		return nil
+	}
+
+	raw := strings.TrimSpace(values[0])
+	if f.Kind() == reflect.Pointer {
+		ptr := reflect.New(f.Type().Elem())
+		if err := setField(ptr.Elem(), []string{raw}); err != nil {
+			return err
+		}
+		f.Set(ptr)
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, f.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		f.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, f.Type().Bits())
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return errors.New("must be a number")
+		}
+		f.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		f.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported query parameter type %s", f.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package binding_test
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/validation"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// request is a request type using every validation rule
+type request struct {
+	Name    string   `json:"name" query:"name,n" validate:"required,min=2,max=5"`
+	Code    string   `json:"code" query:"code" validate:"len=3,digits"`
+	Mode    string   `json:"mode" query:"mode" validate:"oneof=exact smart"`
+	Phone   string   `json:"phone" query:"phone" validate:"required_without=Email"`
+	Email   string   `json:"email" query:"email"`
+	Limit   int      `json:"limit" query:"limit" validate:"min=1,max=10"`
+	Radius  float64  `json:"radius" query:"radius" validate:"min=1"`
+	Tags    []string `json:"tags" query:"tags" validate:"max=2"`
+	Enabled *bool    `json:"enabled" query:"enabled"`
+}
+
+// valid is a request that passes every rule
+const valid = `{"name": "Ola", "phone": "91234567"}`
+
+// bind binds a request with the default binder
+func bind(method, target, body string) (request, error) {
+	var r *http.Request
+	if body == "" {
+		r = httptest.NewRequest(method, target, nil)
+	} else {
+		r = httptest.NewRequest(method, target, strings.NewReader(body))
+	}
+	var req request
+	err := binding.Bind(r, &req)
+	return req, err
+}
+
+// wantField checks that err is a validation error for field only
+func wantField(t *testing.T, err error, field string) {
+	t.Helper()
+
+	ve, ok := validation.As(err)
+	if !ok {
+		t.Fatalf("error = %v, want a validation error for %s", err, field)
+	}
+	if len(ve.Fields) != 1 || ve.Fields[0].Field != field {
+		t.Errorf("fields = %+v, want only %s", ve.Fields, field)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"valid", valid, ""},
+		{"required", `{"phone": "91234567"}`, "name"},
+		{"required whitespace", `{"name": "  ", "phone": "91234567"}`, "name"},
+		{"min length", `{"name": "O", "phone": "91234567"}`, "name"},
+		{"max length", `{"name": "Nordmann", "phone": "91234567"}`, "name"},
+		{"max length counts runes", `{"name": "Ærlig", "phone": "91234567"}`, ""},
+		{"required without", `{"name": "Ola"}`, "phone"},
+		{"required without other set", `{"name": "Ola", "email": "ola@example.com"}`, ""},
+		{"len", `{"name": "Ola", "phone": "1", "code": "12"}`, "code"},
+		{"digits", `{"name": "Ola", "phone": "1", "code": "12a"}`, "code"},
+		{"len and digits", `{"name": "Ola", "phone": "1", "code": "123"}`, ""},
+		{"oneof", `{"name": "Ola", "phone": "1", "mode": "fuzzy"}`, "mode"},
+		{"oneof match", `{"name": "Ola", "phone": "1", "mode": "smart"}`, ""},
+		{"min number", `{"name": "Ola", "phone": "1", "limit": -1}`, "limit"},
+		{"max number", `{"name": "Ola", "phone": "1", "limit": 11}`, "limit"},
+		{"max items", `{"name": "Ola", "phone": "1", "tags": ["a", "b", "c"]}`, "tags"},
+		{"wrong type", `{"name": "Ola", "phone": "1", "limit": "ten"}`, "limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bind(http.MethodPost, "/", tt.body)
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("Bind() error = %v, want nil", err)
+				}
+				return
+			}
+			wantField(t, err, tt.field)
+		})
+	}
+}
+
+func TestValidateReportsEveryField(t *testing.T) {
+	_, err := bind(http.MethodPost, "/", `{"code": "x", "limit": 20}`)
+
+	ve, ok := validation.As(err)
+	if !ok {
+		t.Fatalf("error = %v, want a validation error", err)
+	}
+	var fields []string
+	for _, f := range ve.Fields {
+		fields = append(fields, f.Field)
+	}
+	if got := strings.Join(fields, " "); got != "name code phone limit" {
+		t.Errorf("fields = %s, want name code phone limit", got)
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   func(request) bool
+		field  string
+	}{
+		{"name", "/?name=Ola&phone=1", func(r request) bool { return r.Name == "Ola" }, ""},
+		{"alias", "/?n=Ola&phone=1", func(r request) bool { return r.Name == "Ola" }, ""},
+		{"first name wins", "/?name=Ola&n=Kari&phone=1", func(r request) bool { return r.Name == "Ola" }, ""},
+		{"trimmed", "/?name=+Ola+&phone=1", func(r request) bool { return r.Name == "Ola" }, ""},
+		{"integer", "/?name=Ola&phone=1&limit=5", func(r request) bool { return r.Limit == 5 }, ""},
+		{"not an integer", "/?name=Ola&phone=1&limit=five", nil, "limit"},
+		{"number", "/?name=Ola&phone=1&radius=2.5", func(r request) bool { return r.Radius == 2.5 }, ""},
+		{"NaN", "/?name=Ola&phone=1&radius=NaN", nil, "radius"},
+		{"infinity", "/?name=Ola&phone=1&radius=Inf", nil, "radius"},
+		{"list", "/?name=Ola&phone=1&tags=a,+b&tags=", func(r request) bool { return strings.Join(r.Tags, "|") == "a|b" }, ""},
+		{"pointer", "/?name=Ola&phone=1&enabled=false", func(r request) bool { return r.Enabled != nil && !*r.Enabled }, ""},
+		{"not a bool", "/?name=Ola&phone=1&enabled=maybe", nil, "enabled"},
+		{"validated", "/?name=O&phone=1", nil, "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := bind(http.MethodGet, tt.target, "")
+			if tt.field != "" {
+				wantField(t, err, tt.field)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Bind() error = %v", err)
+			}
+			if !tt.want(req) {
+				t.Errorf("request = %+v", req)
+			}
+		})
+	}
+}
+
+func TestBindBodyAndQuery(t *testing.T) {
+	req, err := bind(http.MethodPost, "/?n=Kari&phone=1&limit=3", `{"name": "Ola"}`)
+	if err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if req.Name != "Ola" || req.Phone != "1" || req.Limit != 3 {
+		t.Errorf("request = %+v, want the body name and the rest from the query", req)
+	}
+
+	req, err = bind(http.MethodPost, "/?name=Ola&phone=1", "")
+	if err != nil || req.Name != "Ola" {
+		t.Errorf("Bind() without body = %+v, %v, want the query", req, err)
+	}
+}
+
+func TestBindDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"missing body", "", "Request body is required"},
+		{"malformed", `{"name" "Ola"}`, "Malformed JSON at position"},
+		{"truncated", `{"name": `, "Invalid request payload"},
+		{"not an object", `[1]`, "Request body must be a JSON object"},
+		{"trailing data", valid + `{}`, "Request body must contain a single JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bind(http.MethodPost, "/", tt.body)
+			var de *binding.DecodeError
+			if !errors.As(err, &de) || !strings.HasPrefix(de.Message, tt.want) {
+				t.Errorf("Bind() error = %v, want a decode error %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindUnknownField(t *testing.T) {
+	_, err := bind(http.MethodPost, "/", `{"name": "Ola", "phone": "1", "nickname": "O"}`)
+	wantField(t, err, "nickname")
+}
+
+func TestBindBodyLimit(t *testing.T) {
+	binder := binding.Binder{MaxBodyBytes: 64}
+	padding := strings.Repeat(" ", 64)
+
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"within limit", valid, nil},
+		{"object too large", `{"name": "Ola",` + padding + `"phone": "1"}`, binding.ErrBodyTooLarge},
+		{"trailing data too large", valid + padding, binding.ErrBodyTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			var req request
+			if err := binder.Bind(r, &req); !errors.Is(err, tt.want) {
+				t.Errorf("Bind() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
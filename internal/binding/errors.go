@@ -0,0 +1,15 @@
+package binding
+
+// DecodeError is returned when the request could not be decoded at all
+type DecodeError struct {
+	Message string
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	return e.Message
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
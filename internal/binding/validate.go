@@ -0,0 +1,194 @@
+package binding
+
+import (
+	"bisnode/internal/validation"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Validator is implemented by request types that need checks beyond what the
+// `validate` struct tag can express. It is called after the tag rules pass.
+type Validator interface {
+	Validate() error
+}
+
+// Validate checks the struct pointed to by dst against the rules in its
+// `validate` struct tags, then calls its Validate method if it implements
+// Validator. Rules are separated by commas:
+//
+//	required               the field must not be empty
+//	required_without=Other the field must be set when the Go field Other is empty
+//	min=N, max=N           bounds on the length of strings and slices or the value of numbers
+//	len=N                  the exact length of a string
+//	digits                 a string may only contain the digits 0-9
+//	oneof=a b c            a string must be one of the space separated values
+//
+// Empty values skip every rule except required and required_without.
+func Validate(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fieldErrs := validateStruct(v)
+	if len(fieldErrs) > 0 {
+		return &validation.Error{Fields: fieldErrs}
+	}
+
+	if validator, ok := dst.(Validator); ok {
+		return validator.Validate()
+	}
+
+	return nil
+}
+
+// validateStruct applies the tag rules to the fields of v, descending into embedded structs
+func validateStruct(v reflect.Value) []validation.FieldError {
+	var fieldErrs []validation.FieldError
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fieldErrs = append(fieldErrs, validateStruct(v.Field(i))...)
+			continue
+		}
+
+		tag := sf.Tag.Get("validate")
+		if tag == "" || !sf.IsExported() {
+			continue
+		}
+
+		for _, rule := range strings.Split(tag, ",") {
+			if msg := checkRule(v, v.Field(i), rule); msg != "" {
+				fieldErrs = append(fieldErrs, validation.FieldError{Field: fieldName(sf), Message: msg})
+				break
+			}
+		}
+	}
+	return fieldErrs
+}
+
+// checkRule applies a single rule to f and returns a message if it fails
+func checkRule(parent, f reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+	switch name {
+	case "required":
+		if isEmpty(f) {
+			return "is required"
+		}
+		return ""
+	case "required_without":
+		if isEmpty(f) && isEmpty(parent.FieldByName(arg)) {
+			other, _ := parent.Type().FieldByName(arg)
+			return fmt.Sprintf("is required when %s is not provided", fieldName(other))
+		}
+		return ""
+	}
+
+	if isEmpty(f) {
+		return ""
+	}
+	for f.Kind() == reflect.Pointer {
+		f = f.Elem()
+	}
+
+	switch name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic(fmt.Sprintf("binding: invalid %s rule %q", name, rule))
+		}
+		size, isLength := measure(f)
+		if name == "min" && size < limit {
+			if isLength {
+				return fmt.Sprintf("must be at least %s characters", arg)
+			}
+			return fmt.Sprintf("must be at least %s", arg)
+		}
+		if name == "max" && size > limit {
+			if isLength {
+				return fmt.Sprintf("must be at most %s characters", arg)
+			}
+			return fmt.Sprintf("must be at most %s", arg)
+		}
+	case "len":
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			panic(fmt.Sprintf("binding: invalid len rule %q", rule))
+		}
+		if len([]rune(f.String())) != n {
+			return fmt.Sprintf("must be exactly %d characters", n)
+		}
+	case "digits":
+		for _, r := range f.String() {
+			if r < '0' || r > '9' {
+				return "must only contain digits"
+			}
+		}
+	case "oneof":
+		options := strings.Fields(arg)
+		for _, o := range options {
+			if f.String() == o {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(options, ", ")
+	default:
+		panic(fmt.Sprintf("binding: unknown validation rule %q", rule))
+	}
+
+	return ""
+}
+
+// measure returns the length of strings and slices, or the value of numbers
+func measure(f reflect.Value) (float64, bool) {
+	switch f.Kind() {
+	case reflect.String:
+		return float64(len([]rune(f.String()))), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(f.Len()), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(f.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(f.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return f.Float(), false
+	}
+	return 0, false
+}
+
+// isEmpty reports whether f holds its zero value; whitespace-only strings count as empty
+func isEmpty(f reflect.Value) bool {
+	if !f.IsValid() {
+		return true
+	}
+	switch f.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(f.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return f.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return f.IsNil()
+	}
+	return f.IsZero()
+}
+
+// fieldName returns the name callers use for a field: its JSON name, then its
+// first query name, then the Go name
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{"json", "query"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
@@ -0,0 +1,342 @@
+// Package bisnodefake is a fake Bisnode API for development and tests. It
+// serves directory and motor vehicle searches from fixtures, and can add
+// latency, inject errors and record the requests it receives.
+package bisnodefake
+
+import (
+	"bisnode/internal/models"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configures a Server
+type Options struct {
+	// Fixtures is the data to serve; the built-in fixtures are used when nil
+	Fixtures *Fixtures
+	// ClientID and ClientSecret are the basic auth credentials required by
+	// the fake. Any credentials are accepted when ClientID is empty.
+	ClientID     string
+	ClientSecret string
+	// Latency is added to every response
+	Latency time.Duration
+}
+
+// Fault makes matching requests fail
+type Fault struct {
+	// Path restricts the fault to requests whose path starts with it; empty matches every request
+	Path string `json:"path,omitempty"`
+	// Status is the HTTP status to respond with, such as 401, 429 or 503
+	Status int `json:"status,omitempty"`
+	// Malformed responds with 200 and a body that is not valid JSON, instead of Status
+	Malformed bool `json:"malformed,omitempty"`
+	// Count is the number of requests that fail before the fault is removed; 0 means every request
+	Count int `json:"count,omitempty"`
+}
+
+// Request is a request received by the fake
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body,omitempty"`
+	Time   time.Time   `json:"time"`
+}
+
+// Server is a fake Bisnode API. It implements http.Handler, so it can be
+// served with httptest.NewServer or http.ListenAndServe.
+type Server struct {
+	fixtures *Fixtures
+	auth     string
+	mux      *http.ServeMux
+	admin    *http.ServeMux
+
+	mu       sync.Mutex
+	latency  time.Duration
+	faults   []Fault
+	requests []Request
+}
+
+// New creates a new Server
+func New(opts Options) *Server {
+	s := &Server{
+		fixtures: opts.Fixtures,
+		latency:  opts.Latency,
+		mux:      http.NewServeMux(),
+		admin:    http.NewServeMux(),
+	}
+	if s.fixtures == nil {
+		s.fixtures = DefaultFixtures()
+	}
+	if opts.ClientID != "" {
+		s.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(opts.ClientID+":"+opts.ClientSecret))
+	}
+
+	s.mux.HandleFunc("POST /search/norway/directory", s.searchDirectory)
+	s.mux.HandleFunc("GET /search/norway/directory/{orgno}", s.searchOrganization)
+	s.mux.HandleFunc("GET /search/norway/motorvehicle/v2/{term}", s.searchVehicle)
+
+	s.admin.HandleFunc("GET /_fake/requests", s.listRequests)
+	s.admin.HandleFunc("DELETE /_fake/requests", s.resetRequests)
+	s.admin.HandleFunc("POST /_fake/faults", s.addFault)
+	s.admin.HandleFunc("DELETE /_fake/faults", s.clearFaults)
+
+	return s
+}
+
+// ServeHTTP records the request, applies latency and faults, and serves it
+// from the fixtures. Requests under /_fake/ control the fake itself and are
+// neither recorded nor delayed.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/_fake/") {
+		s.admin.ServeHTTP(w, r)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Body:   body,
+		Time:   time.Now(),
+	})
+	latency := s.latency
+	fault, faulted := s.takeFault(r.URL.Path)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case faulted && fault.Malformed:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Result": [{"type": "person",`))
+		return
+	case faulted:
+		if fault.Status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		http.Error(w, http.StatusText(fault.Status), fault.Status)
+		return
+	case s.auth != "" && r.Header.Get("Authorization") != s.auth:
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// SetLatency changes the latency added to every response
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Fail adds a fault. Faults are checked in the order they were added. A
+// fault that is not malformed must have a status between 400 and 599.
+func (s *Server) Fail(f Fault) error {
+	if !f.Malformed && (f.Status < 400 || f.Status > 599) {
+		return fmt.Errorf("fault status must be between 400 and 599, got %d", f.Status)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, f)
+	return nil
+}
+
+// ClearFaults removes every fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests received so far, oldest first
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// Reset forgets the recorded requests
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// takeFault returns the first fault matching path, consuming one of its
+// count. The caller must hold s.mu.
+func (s *Server) takeFault(path string) (Fault, bool) {
+	for i, f := range s.faults {
+		if !strings.HasPrefix(path, f.Path) {
+			continue
+		}
+		if f.Count > 0 {
+			s.faults[i].Count--
+			if s.faults[i].Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f, true
+	}
+	return Fault{}, false
+}
+
+// listRequests returns the recorded requests
+func (s *Server) listRequests(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, s.Requests())
+}
+
+// resetRequests forgets the recorded requests
+func (s *Server) resetRequests(w http.ResponseWriter, r *http.Request) {
+	s.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addFault adds the fault in the request body
+func (s *Server) addFault(w http.ResponseWriter, r *http.Request) {
+	var f Fault
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		http.Error(w, "invalid fault", http.StatusBadRequest)
+		return
+	}
+	if err := s.Fail(f); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clearFaults removes every fault
+func (s *Server) clearFaults(w http.ResponseWriter, r *http.Request) {
+	s.ClearFaults()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// searchDirectory serves freetext directory searches
+func (s *Server) searchDirectory(w http.ResponseWriter, r *http.Request) {
+	var req models.DirectorySearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	words := strings.Fields(strings.ToLower(req.Form.SearchString))
+	results := []models.DirectoryResult{}
+	for _, d := range s.fixtures.Directory {
+		if !matchesListingType(d, req.Options.ListingType) || !matchesWords(d, words, req.Options.OnlyFoundWords) {
+			continue
+		}
+		results = append(results, d)
+		if req.Options.ResultLimit > 0 && len(results) == req.Options.ResultLimit {
+			break
+		}
+	}
+
+	respondWithJSON(w, directoryResponse(results))
+}
+
+// searchOrganization serves lookups by organization number
+func (s *Server) searchOrganization(w http.ResponseWriter, r *http.Request) {
+	orgNo := r.PathValue("orgno")
+
+	results := []models.DirectoryResult{}
+	for _, d := range s.fixtures.Directory {
+		if d.OrganizationNumber == orgNo {
+			results = append(results, d)
+		}
+	}
+
+	respondWithJSON(w, directoryResponse(results))
+}
+
+// searchVehicle serves lookups by license plate or VIN
+func (s *Server) searchVehicle(w http.ResponseWriter, r *http.Request) {
+	term := strings.ToUpper(r.PathValue("term"))
+
+	var resp models.MotorVehicleSearchResponse
+	resp.Result = []models.MotorVehicle{}
+	resp.Service.Dataset = "motorvehicle"
+	resp.Service.Version = "2"
+	resp.Service.Timestamp = time.Now().Format(time.RFC3339)
+	for _, v := range s.fixtures.Vehicles {
+		if strings.EqualFold(v.RegNo, term) || strings.EqualFold(v.ChassisNo, term) || strings.EqualFold(v.PersonalPlates, term) {
+			resp.Result = append(resp.Result, v)
+		}
+	}
+
+	respondWithJSON(w, resp)
+}
+
+// directoryResponse wraps results in a directory search response
+func directoryResponse(results []models.DirectoryResult) models.DirectorySearchResponse {
+	var resp models.DirectorySearchResponse
+	resp.Result = results
+	resp.Service.Dataset = "directory"
+	resp.Service.Version = "1"
+	resp.Service.Timestamp = time.Now().Format(time.RFC3339)
+	return resp
+}
+
+// matchesListingType reports whether d is of the requested listing type
+func matchesListingType(d models.DirectoryResult, t models.ListingType) bool {
+	switch t {
+	case models.ListingTypePerson:
+		return strings.EqualFold(d.Type, "person")
+	case models.ListingTypeCompany:
+		return strings.EqualFold(d.Type, "company")
+	}
+	return true
+}
+
+// matchesWords reports whether the searchable fields of d contain every
+// word, or any word when all is false
+func matchesWords(d models.DirectoryResult, words []string, all bool) bool {
+	if len(words) == 0 {
+		return false
+	}
+
+	fields := []string{
+		d.OrganizationNumber, d.FirstName, d.MiddleName, d.LastName,
+		d.StreetName, d.HouseNo + d.Entrance, d.ZipCode, d.City, d.Telephone, d.Mobile,
+	}
+	for _, p := range d.Phones {
+		fields = append(fields, p.Number)
+	}
+	haystack := strings.ToLower(strings.Join(fields, " "))
+
+	for _, word := range words {
+		found := strings.Contains(haystack, word)
+		if found && !all {
+			return true
+		}
+		if !found && all {
+			return false
+		}
+	}
+	return all
+}
+
+// respondWithJSON writes v as a JSON response
+func respondWithJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,225 @@
+package bisnodefake
+
+import (
+	"bisnode/internal/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	directoryPath = "/search/norway/directory"
+	vehiclePath   = "/search/norway/motorvehicle/v2/AB12345"
+)
+
+// do sends a request to s and returns the response
+func do(s *Server, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+// search sends a freetext directory search to s
+func search(s *Server, searchString string, listing models.ListingType) *httptest.ResponseRecorder {
+	var req models.DirectorySearchRequest
+	req.Form.Type = "Freetext"
+	req.Form.SearchString = searchString
+	req.Options.SearchMode = models.SearchModeSmart
+	req.Options.ListingType = listing
+	req.Options.OnlyFoundWords = true
+	body, _ := json.Marshal(req)
+	return do(s, http.MethodPost, directoryPath, string(body))
+}
+
+// statuses returns the status of each of n requests for path
+func statuses(s *Server, path string, n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = do(s, http.MethodGet, path, "").Code
+	}
+	return out
+}
+
+func TestFaultCount(t *testing.T) {
+	s := New(Options{})
+	s.Fail(Fault{Status: http.StatusServiceUnavailable, Count: 2})
+
+	got := statuses(s, vehiclePath, 3)
+	want := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}
+	if !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if len(s.faults) != 0 {
+		t.Errorf("faults = %+v, want the used up fault removed", s.faults)
+	}
+}
+
+func TestFaultWithoutCount(t *testing.T) {
+	s := New(Options{})
+	s.Fail(Fault{Status: http.StatusTooManyRequests})
+
+	for i, code := range statuses(s, vehiclePath, 3) {
+		if code != http.StatusTooManyRequests {
+			t.Errorf("request %d status = %d, want every request to fail", i, code)
+		}
+	}
+	if rec := do(s, http.MethodGet, vehiclePath, ""); rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q, want 1", rec.Header().Get("Retry-After"))
+	}
+
+	s.ClearFaults()
+	if code := do(s, http.MethodGet, vehiclePath, "").Code; code != http.StatusOK {
+		t.Errorf("status after ClearFaults() = %d, want 200", code)
+	}
+}
+
+func TestFaultPath(t *testing.T) {
+	s := New(Options{})
+	s.Fail(Fault{Path: "/search/norway/motorvehicle", Status: http.StatusInternalServerError, Count: 1})
+
+	if code := search(s, "91234567", models.ListingTypePerson).Code; code != http.StatusOK {
+		t.Errorf("directory status = %d, want 200 since the fault is for vehicles", code)
+	}
+	if code := do(s, http.MethodGet, vehiclePath, "").Code; code != http.StatusInternalServerError {
+		t.Errorf("vehicle status = %d, want 500", code)
+	}
+	if code := do(s, http.MethodGet, vehiclePath, "").Code; code != http.StatusOK {
+		t.Errorf("vehicle status = %d, want 200 after the fault was used up", code)
+	}
+}
+
+func TestFaultOrder(t *testing.T) {
+	s := New(Options{})
+	s.Fail(Fault{Path: directoryPath, Status: http.StatusUnauthorized, Count: 1})
+	s.Fail(Fault{Status: http.StatusBadGateway, Count: 1})
+
+	// The vehicle request skips the directory fault and uses up the second one
+	got := []int{
+		do(s, http.MethodGet, vehiclePath, "").Code,
+		do(s, http.MethodGet, directoryPath+"/923609016", "").Code,
+		do(s, http.MethodGet, directoryPath+"/923609016", "").Code,
+	}
+	want := []int{http.StatusBadGateway, http.StatusUnauthorized, http.StatusOK}
+	if !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+}
+
+func TestFaultMalformed(t *testing.T) {
+	s := New(Options{})
+	s.Fail(Fault{Malformed: true, Count: 1})
+
+	rec := do(s, http.MethodGet, vehiclePath, "")
+	if rec.Code != http.StatusOK || json.Valid(rec.Body.Bytes()) {
+		t.Errorf("response = %d %s, want 200 with invalid JSON", rec.Code, rec.Body)
+	}
+}
+
+func TestFailRejectsInvalidStatus(t *testing.T) {
+	s := New(Options{})
+
+	for _, f := range []Fault{{}, {Status: http.StatusOK}, {Status: 42}, {Status: 600}} {
+		if err := s.Fail(f); err == nil {
+			t.Errorf("Fail(%+v) error = nil, want the status rejected", f)
+		}
+	}
+	if code := do(s, http.MethodGet, vehiclePath, "").Code; code != http.StatusOK {
+		t.Errorf("status = %d, want 200 since no fault was added", code)
+	}
+	if err := s.Fail(Fault{Malformed: true}); err != nil {
+		t.Errorf("Fail() of a malformed fault without status error = %v", err)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	s := New(Options{ClientID: "client", ClientSecret: "secret"})
+
+	if code := do(s, http.MethodGet, vehiclePath, "").Code; code != http.StatusUnauthorized {
+		t.Errorf("status without credentials = %d, want 401", code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, vehiclePath, nil)
+	req.SetBasicAuth("client", "secret")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status with credentials = %d, want 200", rec.Code)
+	}
+}
+
+func TestSearches(t *testing.T) {
+	s := New(Options{})
+
+	tests := []struct {
+		name string
+		rec  *httptest.ResponseRecorder
+		want []string
+	}{
+		{"mobile number", search(s, "91234567", models.ListingTypePerson), []string{"Ola Nordmann"}},
+		{"every word", search(s, "Kari Nordmann", models.ListingTypePerson), []string{"Kari Nordmann"}},
+		{"listing type", search(s, "oslo", models.ListingTypeCompany), []string{"Eksempel AS"}},
+		{"no match", search(s, "Bergen", models.ListingTypeAll), nil},
+		{"organization number", do(s, http.MethodGet, directoryPath+"/923609016", ""), []string{"Eksempel AS"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp models.DirectorySearchResponse
+			if err := json.Unmarshal(tt.rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("response %s: %v", tt.rec.Body, err)
+			}
+			var names []string
+			for _, r := range resp.Result {
+				names = append(names, strings.TrimSpace(r.FirstName+" "+r.LastName))
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("results = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	// Vehicles are found by plate or chassis number in any case
+	for _, term := range []string{"ab12345", "WBAKG7C5XBE123456"} {
+		var resp models.MotorVehicleSearchResponse
+		json.Unmarshal(do(s, http.MethodGet, "/search/norway/motorvehicle/v2/"+term, "").Body.Bytes(), &resp)
+		if len(resp.Result) != 1 || resp.Result[0].RegNo != "AB12345" {
+			t.Errorf("vehicle search for %s = %+v, want AB12345", term, resp.Result)
+		}
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	s := New(Options{})
+
+	for _, body := range []string{`{"status": 200}`, `{"status": 42}`, `{}`, `not json`} {
+		if code := do(s, http.MethodPost, "/_fake/faults", body).Code; code != http.StatusBadRequest {
+			t.Errorf("POST /_fake/faults %s status = %d, want 400", body, code)
+		}
+	}
+	if code := do(s, http.MethodPost, "/_fake/faults", `{"path": "/search", "status": 503, "count": 1}`).Code; code != http.StatusNoContent {
+		t.Fatalf("POST /_fake/faults status = %d, want 204", code)
+	}
+	if code := do(s, http.MethodGet, vehiclePath, "").Code; code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want the fault added through the admin API", code)
+	}
+
+	do(s, http.MethodPost, "/_fake/faults", `{"status": 503}`)
+	do(s, http.MethodDelete, "/_fake/faults", "")
+	if code := do(s, http.MethodGet, vehiclePath, "").Code; code != http.StatusOK {
+		t.Errorf("status after DELETE /_fake/faults = %d, want 200", code)
+	}
+
+	// Only the two searches are recorded, not the admin requests
+	var requests []Request
+	json.Unmarshal(do(s, http.MethodGet, "/_fake/requests", "").Body.Bytes(), &requests)
+	if len(requests) != 2 || requests[0].Path != vehiclePath {
+		t.Errorf("requests = %+v, want the two vehicle searches", requests)
+	}
+	do(s, http.MethodDelete, "/_fake/requests", "")
+	if n := len(s.Requests()); n != 0 {
+		t.Errorf("%d requests after DELETE /_fake/requests, want 0", n)
+	}
+}
@@ -0,0 +1,46 @@
+package bisnodefake
+
+import (
+	"bisnode/internal/models"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed fixtures.json
+var defaultFixtures []byte
+
+// Fixtures is the data served by the fake, in Bisnode's own format
+type Fixtures struct {
+	Directory []models.DirectoryResult `json:"directory"`
+	Vehicles  []models.MotorVehicle    `json:"vehicles"`
+}
+
+// LoadFixtures reads fixtures from a JSON file
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseFixtures(data)
+}
+
+// DefaultFixtures returns a small built-in set of fictional persons,
+// companies and vehicles
+func DefaultFixtures() *Fixtures {
+	fixtures, err := parseFixtures(defaultFixtures)
+	if err != nil {
+		panic(fmt.Sprintf("bisnodefake: invalid built-in fixtures: %v", err))
+	}
+	return fixtures
+}
+
+// parseFixtures decodes fixtures from JSON
+func parseFixtures(data []byte) (*Fixtures, error) {
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("error decoding fixtures: %w", err)
+	}
+	return &fixtures, nil
+}
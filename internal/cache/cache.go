@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// State describes how current a value returned from the cache is
+type State string
+
+const (
+	// StateFresh means the value was fetched from upstream or is within its TTL
+	StateFresh State = "fresh"
+	// StateStale means the value is past its TTL and is being refreshed in the background
+	StateStale State = "stale"
+	// StateStaleIfError means the upstream failed and an expired value was served instead
+	StateStaleIfError State = "stale-if-error"
+)
+
+// Options configures the lifetime of cached values
+type Options struct {
+	// TTL is how long a value is considered fresh
+	TTL time.Duration
+	// StaleWhileRevalidate is how long after the TTL a value may be served while it is refreshed in the background
+	StaleWhileRevalidate time.Duration
+	// StaleIfError is how long after the TTL a value may be served when the upstream fails
+	StaleIfError time.Duration
+	// RefreshTimeout bounds background refreshes, which are detached from the caller's request
+	RefreshTimeout time.Duration
+	// MaxEntries caps the number of stored values, 0 means unlimited
+	MaxEntries int
+}
+
+// Result is a value returned from the cache together with its freshness
+type Result[V any] struct {
+	Value    V
+	State    State
+	StoredAt time.Time
+	Age      time.Duration
+	// Err is the upstream error that caused a stale value to be served, if any
+	Err error
+}
+
+// FetchFunc loads a value from the upstream source
+type FetchFunc[V any] func(ctx context.Context) (V, error)
+
+type entry[V any] struct {
+	value    V
+	storedAt time.Time
+}
+
+// Cache is an in-memory cache that supports stale-while-revalidate and stale-if-error serving
+type Cache[V any] struct {
+	opts       Options
+	mu         sync.Mutex
+	entries    map[string]entry[V]
+	refreshing map[string]bool
+	now        func() time.Time
+}
+
+// New creates a new Cache
+func New[V any](opts Options) *Cache[V] {
+	if opts.RefreshTimeout <= 0 {
+		opts.RefreshTimeout = 30 * time.Second
+	}
+
+	return &Cache[V]{
+		opts:       opts,
+		entries:    make(map[string]entry[V]),
+		refreshing: make(map[string]bool),
+		now:        time.Now,
+	}
+}
+
+// Get returns the value for key, calling fetch when there is no usable cached value.
+// A value past its TTL but within the stale-while-revalidate window is returned
+// immediately while fetch runs in the background. When fetch fails, a value within
+// the stale-if-error window is returned instead of the error, unless ctx was
+// cancelled or timed out.
+func (c *Cache[V]) Get(ctx context.Context, key string, fetch FetchFunc[V]) (Result[V], error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	now := c.now()
+	c.mu.Unlock()
+
+	if ok {
+		age := now.Sub(e.storedAt)
+		if age <= c.opts.TTL {
+			return Result[V]{Value: e.value, State: StateFresh, StoredAt: e.storedAt, Age: age}, nil
+		}
+		if age <= c.opts.TTL+c.opts.StaleWhileRevalidate {
+			c.refresh(ctx, key, fetch)
+			return Result[V]{Value: e.value, State: StateStale, StoredAt: e.storedAt, Age: age}, nil
+		}
+	}
+
+	value, err := fetch(ctx)
+	if err != nil {
+		// A caller that gave up gets its own error, not a stale value
+		if ok && !callerGaveUp(ctx, err) {
+			age := now.Sub(e.storedAt)
+			if age <= c.opts.TTL+c.opts.StaleIfError {
+				log.Printf("Serving stale value for %q after upstream error: %v", key, err)
+				return Result[V]{Value: e.value, State: StateStaleIfError, StoredAt: e.storedAt, Age: age, Err: err}, nil
+			}
+		}
+		var zero V
+		return Result[V]{Value: zero}, err
+	}
+
+	storedAt := c.set(key, value)
+	return Result[V]{Value: value, State: StateFresh, StoredAt: storedAt}, nil
+}
+
+// callerGaveUp reports whether fetch failed because the caller's context was
+// cancelled or timed out. Upstream timeouts are not the caller giving up.
+func callerGaveUp(ctx context.Context, err error) bool {
+	return ctx.Err() != nil || errors.Is(err, context.Canceled)
+}
+
+// refresh fetches key in the background unless a refresh for it is already running
+func (c *Cache[V]) refresh(ctx context.Context, key string, fetch FetchFunc[V]) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.RefreshTimeout)
+		defer cancel()
+
+		value, err := fetch(refreshCtx)
+		if err != nil {
+			log.Printf("Background refresh for %q failed: %v", key, err)
+			return
+		}
+		c.set(key, value)
+	}()
+}
+
+// set stores value under key and returns the time it was stored
+func (c *Cache[V]) set(key string, value V) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.opts.MaxEntries > 0 && len(c.entries) >= c.opts.MaxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = entry[V]{value: value, storedAt: now}
+	return now
+}
+
+// evict removes entries that can no longer be served, and if the cache is
+// still full, the oldest entry. The caller must hold c.mu.
+func (c *Cache[V]) evict(now time.Time) {
+	maxAge := c.opts.TTL + max(c.opts.StaleWhileRevalidate, c.opts.StaleIfError)
+
+	var oldestKey string
+	var oldest time.Time
+	for k, e := range c.entries {
+		if now.Sub(e.storedAt) > maxAge {
+			delete(c.entries, k)
+			continue
+		}
+		if oldestKey == "" || e.storedAt.Before(oldest) {
+			oldestKey, oldest = k, e.storedAt
+		}
+	}
+
+	if len(c.entries) >= c.opts.MaxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
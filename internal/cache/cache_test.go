@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// clock is a settable time source for a Cache
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestCache returns a cache of strings driven by a clock
+func newTestCache(opts Options) (*Cache[string], *clock) {
+	clk := &clock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	c := New[string](opts)
+	c.now = clk.Now
+	return c, clk
+}
+
+// fetchValue returns a FetchFunc that returns value and counts its calls
+func fetchValue(value string, calls *atomic.Int32) FetchFunc[string] {
+	return func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return value, nil
+	}
+}
+
+var errUpstream = errors.New("upstream failed")
+
+func fetchError(ctx context.Context) (string, error) {
+	return "", errUpstream
+}
+
+// waitForRefresh waits until no background refresh of key is running
+func waitForRefresh(t *testing.T, c *Cache[string], key string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mu.Lock()
+		refreshing := c.refreshing[key]
+		c.mu.Unlock()
+		if !refreshing {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("gave up waiting for the background refresh")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheStates(t *testing.T) {
+	opts := Options{TTL: time.Minute, StaleWhileRevalidate: time.Hour, StaleIfError: 24 * time.Hour}
+
+	tests := []struct {
+		name      string
+		age       time.Duration
+		fetch     FetchFunc[string]
+		wantValue string
+		wantState State
+		wantErr   error
+	}{
+		{"fresh", time.Minute, fetchError, "old", StateFresh, nil},
+		{"stale while revalidating", time.Minute + time.Hour, fetchError, "old", StateStale, nil},
+		{"expired", time.Minute + time.Hour + time.Second, fetchValue("new", new(atomic.Int32)), "new", StateFresh, nil},
+		{"stale if error", time.Minute + 24*time.Hour, fetchError, "old", StateStaleIfError, nil},
+		{"too old to serve on error", time.Minute + 24*time.Hour + time.Second, fetchError, "", "", errUpstream},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, clk := newTestCache(opts)
+			if _, err := c.Get(context.Background(), "key", fetchValue("old", new(atomic.Int32))); err != nil {
+				t.Fatal(err)
+			}
+			clk.Advance(tt.age)
+
+			got, err := c.Get(context.Background(), "key", tt.fetch)
+			waitForRefresh(t, c, "key")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Value != tt.wantValue || got.State != tt.wantState {
+				t.Errorf("Get() = %q %s, want %q %s", got.Value, got.State, tt.wantValue, tt.wantState)
+			}
+			if tt.wantState == StateStaleIfError && !errors.Is(got.Err, errUpstream) {
+				t.Errorf("Err = %v, want the upstream error", got.Err)
+			}
+		})
+	}
+}
+
+func TestCacheDisabledStaleWindows(t *testing.T) {
+	c, clk := newTestCache(Options{TTL: time.Minute})
+	var calls atomic.Int32
+	c.Get(context.Background(), "key", fetchValue("old", &calls))
+	clk.Advance(time.Minute + time.Second)
+
+	// Without stale-while-revalidate the caller waits for the new value
+	got, err := c.Get(context.Background(), "key", fetchValue("new", &calls))
+	if err != nil || got.Value != "new" || got.State != StateFresh {
+		t.Errorf("Get() = %+v, %v, want the new value", got, err)
+	}
+
+	// Without stale-if-error the failure is returned
+	clk.Advance(time.Minute + time.Second)
+	if _, err := c.Get(context.Background(), "key", fetchError); !errors.Is(err, errUpstream) {
+		t.Errorf("Get() error = %v, want the upstream error", err)
+	}
+}
+
+func TestCacheStaleIfErrorSkipsCallerCancellation(t *testing.T) {
+	c, clk := newTestCache(Options{TTL: time.Minute, StaleIfError: time.Hour})
+	var calls atomic.Int32
+	c.Get(context.Background(), "key", fetchValue("old", &calls))
+	clk.Advance(time.Minute + time.Second)
+
+	// The caller's own cancellation is returned instead of the stale value
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err := c.Get(ctx, "key", func(ctx context.Context) (string, error) {
+		return "", ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) || got.Value != "" {
+		t.Errorf("Get() = %+v, %v, want the cancellation", got, err)
+	}
+
+	// So is a cancellation reported by fetch while ctx is still live
+	got, err = c.Get(context.Background(), "key", func(ctx context.Context) (string, error) {
+		return "", fmt.Errorf("request failed: %w", context.Canceled)
+	})
+	if !errors.Is(err, context.Canceled) || got.Value != "" {
+		t.Errorf("Get() = %+v, %v, want the cancellation", got, err)
+	}
+
+	// An upstream failure still falls back to the stale value
+	got, err = c.Get(context.Background(), "key", fetchError)
+	if err != nil || got.Value != "old" || got.State != StateStaleIfError {
+		t.Errorf("Get() = %+v, %v, want the stale value", got, err)
+	}
+}
+
+func TestCacheRefreshesInBackground(t *testing.T) {
+	c, clk := newTestCache(Options{TTL: time.Minute, StaleWhileRevalidate: time.Hour})
+	c.Get(context.Background(), "key", fetchValue("old", new(atomic.Int32)))
+	clk.Advance(2 * time.Minute)
+
+	// Concurrent stale reads start a single refresh
+	release := make(chan struct{})
+	var calls atomic.Int32
+	refresh := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "new", nil
+	}
+	for i := 0; i < 5; i++ {
+		got, err := c.Get(context.Background(), "key", refresh)
+		if err != nil || got.Value != "old" || got.State != StateStale {
+			t.Fatalf("Get() = %+v, %v, want the stale value", got, err)
+		}
+	}
+	close(release)
+	waitForRefresh(t, c, "key")
+	if n := calls.Load(); n != 1 {
+		t.Errorf("refresh ran %d times, want 1", n)
+	}
+
+	got, _ := c.Get(context.Background(), "key", fetchError)
+	if got.Value != "new" || got.State != StateFresh {
+		t.Errorf("Get() after refresh = %q %s, want the refreshed value", got.Value, got.State)
+	}
+}
+
+func TestCacheRefreshOutlivesRequest(t *testing.T) {
+	c, clk := newTestCache(Options{TTL: time.Minute, StaleWhileRevalidate: time.Hour})
+	c.Get(context.Background(), "key", fetchValue("old", new(atomic.Int32)))
+	clk.Advance(2 * time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	c.Get(ctx, "key", func(ctx context.Context) (string, error) {
+		<-release
+		return "new", ctx.Err()
+	})
+	cancel()
+	close(release)
+	waitForRefresh(t, c, "key")
+
+	if got, _ := c.Get(context.Background(), "key", fetchError); got.Value != "new" {
+		t.Errorf("Get() = %q, want the value refreshed after the request was cancelled", got.Value)
+	}
+}
+
+func TestCacheFailedRefreshKeepsValue(t *testing.T) {
+	c, clk := newTestCache(Options{TTL: time.Minute, StaleWhileRevalidate: time.Hour})
+	c.Get(context.Background(), "key", fetchValue("old", new(atomic.Int32)))
+	clk.Advance(2 * time.Minute)
+
+	c.Get(context.Background(), "key", fetchError)
+	waitForRefresh(t, c, "key")
+
+	got, err := c.Get(context.Background(), "key", fetchError)
+	if err != nil || got.Value != "old" || got.State != StateStale {
+		t.Errorf("Get() = %+v, %v, want the stale value", got, err)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	c, clk := newTestCache(Options{TTL: time.Minute, StaleIfError: time.Hour, MaxEntries: 2})
+	var calls atomic.Int32
+
+	c.Get(context.Background(), "a", fetchValue("a", &calls))
+	clk.Advance(time.Second)
+	c.Get(context.Background(), "b", fetchValue("b", &calls))
+	clk.Advance(time.Second)
+
+	// The cache is full, so the oldest entry makes room
+	c.Get(context.Background(), "c", fetchValue("c", &calls))
+	if _, ok := c.entries["a"]; ok || len(c.entries) != 2 {
+		t.Errorf("entries = %v, want a evicted", c.entries)
+	}
+
+	// Every entry that can no longer be served is evicted, not just the oldest
+	clk.Advance(time.Minute + time.Hour + time.Second)
+	c.Get(context.Background(), "d", fetchValue("d", &calls))
+	if _, ok := c.entries["d"]; !ok || len(c.entries) != 1 {
+		t.Errorf("entries = %v, want only d", c.entries)
+	}
+}
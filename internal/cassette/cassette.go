@@ -0,0 +1,270 @@
+// Package cassette records HTTP traffic to files and replays it without
+// network. Each request and its response are saved to one file, named after
+// a keyed hash of the request, so a replayed request is answered with the
+// response recorded for an identical request.
+package cassette
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ModeRecord saves every response
+	ModeRecord = "record"
+	// ModeReplay serves saved responses instead of sending requests
+	ModeReplay = "replay"
+)
+
+// ErrNotRecorded is returned when replaying a request that was never recorded
+var ErrNotRecorded = errors.New("no recorded response for request")
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Only the method and endpoint are recorded:
+// headers hold credentials, and the query, body and rest of the path hold
+// search strings such as names, phone numbers and license plates. Replaying
+// only needs the hash in the file name.
+type Request struct {
+	Method string `json:"method"`
+	// Endpoint is the leading segments of the path that are made of lowercase
+	// letters only, such as "/search/norway/motorvehicle"
+	Endpoint string `json:"endpoint"`
+}
+
+// Response is a recorded response. Body holds JSON bodies as-is for
+// readability; Text holds any other body.
+type Response struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"contentType,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Text        string          `json:"text,omitempty"`
+}
+
+// Sanitizer rewrites a response body before it is recorded, such as to remove
+// personal data. It is called for every response, including errors, and
+// resp.Request is the request that was sent.
+type Sanitizer func(resp *http.Response, body []byte) ([]byte, error)
+
+// Recorder is an http.RoundTripper that sends requests with next and records
+// every response in dir. Callers receive the original response; only the
+// recorded copy is sanitized.
+type Recorder struct {
+	dir      string
+	secret   []byte
+	next     http.RoundTripper
+	sanitize Sanitizer
+}
+
+// NewRecorder creates a new Recorder. Recordings are named with a hash keyed
+// by secret, so the request cannot be guessed from the name without it; it
+// must not be empty, and replaying needs the same secret. sanitize may be nil.
+func NewRecorder(dir string, secret []byte, next http.RoundTripper, sanitize Sanitizer) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{dir: dir, secret: secret, next: next, sanitize: sanitize}
+}
+
+// RoundTrip sends req and records the response
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if reqBody != nil {
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	recorded := respBody
+	if r.sanitize != nil {
+		if resp.Request == nil {
+			resp.Request = req
+		}
+		if recorded, err = r.sanitize(resp, respBody); err != nil {
+			return nil, fmt.Errorf("cassette: failed to sanitize response: %w", err)
+		}
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method:   req.Method,
+			Endpoint: endpoint(req.URL.Path),
+		},
+		Response: Response{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+		},
+	}
+	if body := jsonOrNil(recorded); body != nil {
+		interaction.Response.Body = body
+	} else {
+		interaction.Response.Text = string(recorded)
+	}
+
+	if err := r.save(key(r.secret, req, reqBody), interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// save writes an interaction to its file, replacing any earlier recording
+func (r *Recorder) save(name string, interaction Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette: failed to encode interaction: %w", err)
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+
+	// Write to a temporary file first so a replay never reads half a recording
+	path := filepath.Join(r.dir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	return nil
+}
+
+// Replayer is an http.RoundTripper that answers requests with the responses
+// recorded in dir, without network
+type Replayer struct {
+	dir    string
+	secret []byte
+}
+
+// NewReplayer creates a new Replayer for recordings made with secret
+func NewReplayer(dir string, secret []byte) *Replayer {
+	return &Replayer{dir: dir, secret: secret}
+}
+
+// RoundTrip returns the recorded response for req
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	name := key(r.secret, req, reqBody)
+	data, err := os.ReadFile(filepath.Join(r.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cassette: %w: %s %s (%s)", ErrNotRecorded, req.Method, endpoint(req.URL.Path), name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal(data, &interaction); err != nil {
+		return nil, fmt.Errorf("cassette: invalid recording %s: %w", name, err)
+	}
+
+	body := []byte(interaction.Response.Body)
+	if body == nil {
+		body = []byte(interaction.Response.Text)
+	}
+	header := http.Header{}
+	if interaction.Response.ContentType != "" {
+		header.Set("Content-Type", interaction.Response.ContentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+		StatusCode:    interaction.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// readRequestBody reads the body of req, which may be nil
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read request: %w", err)
+	}
+	return body, nil
+}
+
+// key names the file of a request with an HMAC of the request keyed by
+// secret, since an unkeyed hash of a phone number is easily reversed. JSON
+// bodies are compacted first, so formatting does not change the key.
+func key(secret []byte, req *http.Request, body []byte) string {
+	var compact bytes.Buffer
+	if json.Compact(&compact, body) == nil {
+		body = compact.Bytes()
+	}
+
+	h := hmac.New(sha256.New, secret)
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL.RequestURI())
+	h.Write(body)
+	sum := hex.EncodeToString(h.Sum(nil))[:16]
+
+	// Prefix the hash with the endpoint so cassettes are easy to browse
+	name := strings.Trim(strings.ReplaceAll(endpoint(req.URL.Path), "/", "_"), "_")
+	if len(name) > 60 {
+		name = name[:60]
+	}
+	return fmt.Sprintf("%s_%s-%s.json", strings.ToLower(req.Method), name, sum)
+}
+
+// endpoint returns the leading segments of path that are made of lowercase
+// letters only. Search terms such as organization numbers and license plates
+// follow the endpoint, and are left out.
+func endpoint(path string) string {
+	var b strings.Builder
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "" || strings.TrimLeft(segment, "abcdefghijklmnopqrstuvwxyz") != "" {
+			break
+		}
+		b.WriteString("/" + segment)
+	}
+	return b.String()
+}
+
+// jsonOrNil returns data as raw JSON, or nil when it is empty or not JSON
+func jsonOrNil(data []byte) json.RawMessage {
+	if len(bytes.TrimSpace(data)) == 0 || !json.Valid(data) {
+		return nil
+	}
+	return json.RawMessage(data)
+}
@@ -0,0 +1,167 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// echo responds with the search string of the request, as Bisnode does
+func echo(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Query().Get("fail") != "" {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "nothing found for ` + r.URL.Query().Get("q") + `"}`))
+		return
+	}
+	w.Write([]byte(`{"mobile": "91234567", "search": ` + string(body) + `}`))
+}
+
+// hideMobile replaces the mobile number in successful bodies and the whole
+// body of errors
+func hideMobile(resp *http.Response, body []byte) ([]byte, error) {
+	if resp.StatusCode >= 300 {
+		return []byte(http.StatusText(resp.StatusCode)), nil
+	}
+	return bytes.ReplaceAll(body, []byte("91234567"), []byte("90000000")), nil
+}
+
+// secret keys the names of the recordings
+var secret = []byte("secret")
+
+// send sends a POST request with body through transport
+func send(t *testing.T, transport http.RoundTripper, url, body string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(data)
+}
+
+// recordings returns the contents of the files in dir
+func recordings(t *testing.T, dir string) string {
+	t.Helper()
+
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all strings.Builder
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all.WriteString(filepath.Base(name) + "\n" + string(data))
+	}
+	return all.String()
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echo))
+	defer server.Close()
+	dir := t.TempDir()
+
+	recorder := NewRecorder(dir, secret, nil, hideMobile)
+	resp, body := send(t, recorder, server.URL+"/search/directory?q=91234567", `{"searchString": "91234567"}`)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"mobile": "91234567"`) {
+		t.Errorf("recorded response = %d %s, want the original response", resp.StatusCode, body)
+	}
+
+	recorded := recordings(t, dir)
+	if strings.Contains(recorded, "91234567") {
+		t.Errorf("recording contains the search string:\n%s", recorded)
+	}
+	if !strings.Contains(recorded, `"endpoint": "/search/directory"`) || !strings.Contains(recorded, "90000000") {
+		t.Errorf("recording = %s, want the endpoint and the sanitized body", recorded)
+	}
+
+	replayer := NewReplayer(dir, secret)
+	resp, body = send(t, replayer, "http://bisnode.invalid/search/directory?q=91234567", "{\n  \"searchString\": \"91234567\"\n}")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("replayed response = %d %s, want 200 application/json", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(body, `"mobile": "90000000"`) {
+		t.Errorf("replayed body = %s, want the sanitized body", body)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://bisnode.invalid/search/directory?q=91234567", strings.NewReader(`{"searchString": "98765432"}`))
+	if _, err := replayer.RoundTrip(req); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("RoundTrip() with another body error = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestRecorderSanitizesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echo))
+	defer server.Close()
+	dir := t.TempDir()
+
+	recorder := NewRecorder(dir, secret, nil, hideMobile)
+	resp, body := send(t, recorder, server.URL+"/search/directory?fail=1&q=Ola+Nordmann", "")
+	if resp.StatusCode != http.StatusNotFound || !strings.Contains(body, "Ola Nordmann") {
+		t.Errorf("recorded response = %d %s, want the original error", resp.StatusCode, body)
+	}
+	if recorded := recordings(t, dir); strings.Contains(recorded, "Nordmann") {
+		t.Errorf("recording contains the search string:\n%s", recorded)
+	}
+
+	resp, body = send(t, NewReplayer(dir, secret), "http://bisnode.invalid/search/directory?fail=1&q=Ola+Nordmann", "")
+	if resp.StatusCode != http.StatusNotFound || body != "Not Found" {
+		t.Errorf("replayed response = %d %q, want 404 Not Found", resp.StatusCode, body)
+	}
+}
+
+func TestRecorderHidesSearchTerms(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echo))
+	defer server.Close()
+	dir := t.TempDir()
+
+	send(t, NewRecorder(dir, secret, nil, hideMobile), server.URL+"/search/norway/motorvehicle/v2/AB12345", "")
+	recorded := recordings(t, dir)
+	if strings.Contains(recorded, "AB12345") || strings.Contains(recorded, "v2") {
+		t.Errorf("recording contains the license plate:\n%s", recorded)
+	}
+	if !strings.Contains(recorded, `"endpoint": "/search/norway/motorvehicle"`) {
+		t.Errorf("recording = %s, want the endpoint", recorded)
+	}
+
+	// Without the secret the name cannot be matched to the request
+	req, _ := http.NewRequest(http.MethodPost, "http://bisnode.invalid/search/norway/motorvehicle/v2/AB12345", nil)
+	if _, err := NewReplayer(dir, []byte("guess")).RoundTrip(req); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("RoundTrip() with another secret error = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/search/norway/directory":              "/search/norway/directory",
+		"/search/norway/directory/923609016":    "/search/norway/directory",
+		"/search/norway/motorvehicle/v2/NORGE":  "/search/norway/motorvehicle",
+		"/search/norway/motorvehicle/v2/ab1234": "/search/norway/motorvehicle",
+		"/Search":                               "",
+		"/":                                     "",
+	}
+	for path, want := range tests {
+		if got := endpoint(path); got != want {
+			t.Errorf("endpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -1,19 +1,135 @@
 package config
 
 import (
+	"bisnode/internal/models"
 	"encoding/json"
+	"fmt"
 	"os"
 )
 
+// DefaultBisnodeBaseURL is the Bisnode API used when no base URL is configured
+const DefaultBisnodeBaseURL = "https://api.bisnode.no"
+
 // BisnodeConfig holds configuration for the Bisnode API
 type BisnodeConfig struct {
 	BaseURL      string `json:"base_url"`
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
+	// Cassette records Bisnode traffic to files or replays it without network
+	Cassette CassetteConfig `json:"cassette"`
+}
+
+// CassetteConfig holds configuration for recording and replaying Bisnode traffic
+type CassetteConfig struct {
+	// Mode is "record" to save every Bisnode response, "replay" to serve
+	// saved responses instead of calling Bisnode, or empty to do neither
+	Mode string `json:"mode"`
+	// Dir is where recorded responses are stored
+	Dir string `json:"dir"`
+	// PseudonymKey is the secret that pseudonyms and the names of recordings
+	// are derived from. Recordings made with the same key use the same
+	// pseudonym for the same person, and can only be replayed with that key.
+	// It is required when Mode is set.
+	PseudonymKey string `json:"pseudonym_key"`
+}
+
+// CacheConfig holds configuration for caching upstream responses
+type CacheConfig struct {
+	Enabled bool `json:"enabled"`
+	// TTLSeconds is how long a cached response is considered fresh
+	TTLSeconds int `json:"ttl_seconds"`
+	// StaleWhileRevalidateSeconds is how long past the TTL a response may be
+	// served while it is refreshed; 0 disables it and nil uses the default
+	StaleWhileRevalidateSeconds *int `json:"stale_while_revalidate_seconds"`
+	// StaleIfErrorSeconds is how long past the TTL a response may be served
+	// when Bisnode fails; 0 disables it and nil uses the default
+	StaleIfErrorSeconds *int `json:"stale_if_error_seconds"`
+	MaxEntries          int  `json:"max_entries"`
+}
+
+// SearchConfig holds the defaults and limits for Bisnode directory search options
+type SearchConfig struct {
+	// DefaultSearchMode is "exact", "phonetic" or "smart"
+	DefaultSearchMode     string `json:"default_search_mode"`
+	DefaultOnlyFoundWords *bool  `json:"default_only_found_words"`
+	DefaultResultLimit    int    `json:"default_result_limit"`
+	// MaxResultLimit is the largest result limit a caller may ask for
+	MaxResultLimit int `json:"max_result_limit"`
+}
+
+// GeoConfig holds configuration for searching the directory by location
+type GeoConfig struct {
+	// ZipCodesFile is a CSV file of zip code centers; geo search is disabled without it
+	ZipCodesFile string `json:"zip_codes_file"`
+	// MaxRadiusMeters is the largest search radius a caller may ask for
+	MaxRadiusMeters int `json:"max_radius_meters"`
+	// ZipMarginMeters is added to the radius when selecting zip codes, since
+	// addresses lie around the center of their zip code area
+	ZipMarginMeters int `json:"zip_margin_meters"`
+	// MaxZipCodes caps the number of directory searches for one geo search
+	MaxZipCodes int `json:"max_zip_codes"`
+}
+
+// ScreeningConfig holds configuration for screening phone lists against marketing reservations
+type ScreeningConfig struct {
+	// Concurrency is the number of phone numbers looked up in parallel per screening
+	Concurrency int `json:"concurrency"`
+	// MaxNumbers is the largest phone list accepted for one screening
+	MaxNumbers int `json:"max_numbers"`
+	// RetentionMinutes is how long a finished screening and its report are kept
+	RetentionMinutes int `json:"retention_minutes"`
+	// MaxRunning is the number of screenings run at the same time
+	MaxRunning int `json:"max_running"`
+	// MaxStored is the number of screenings kept in memory, running or finished
+	MaxStored int `json:"max_stored"`
+}
+
+// BatchConfig holds configuration for batch lookups
+type BatchConfig struct {
+	// MaxItems is the largest number of items accepted in one batch
+	MaxItems int `json:"max_items"`
+	// Concurrency is the number of items looked up in parallel per batch
+	Concurrency int `json:"concurrency"`
+}
+
+// JobsConfig holds configuration for background jobs
+type JobsConfig struct {
+	// Dir is where jobs and their results are stored
+	Dir string `json:"dir"`
+	// MaxItems is the largest number of items accepted in one job
+	MaxItems int `json:"max_items"`
+	// Concurrency is the number of items processed in parallel per job
+	Concurrency int `json:"concurrency"`
+	// MaxRunning is the number of jobs run at the same time; others wait in a queue
+	MaxRunning int `json:"max_running"`
+	// RetentionHours is how long a finished job and its results are kept
+	RetentionHours int `json:"retention_hours"`
+}
+
+// AuditConfig holds configuration for the audit log of directory and vehicle lookups
+type AuditConfig struct {
+	// File is where one JSON line per lookup is appended; auditing is disabled when empty
+	File string `json:"file"`
+}
+
+// ContractConfig holds configuration for checking requests and responses
+// against the OpenAPI document
+type ContractConfig struct {
+	// Mode is "log" to log mismatches, "fail" to also replace responses with
+	// mismatches by a 500 error, or empty to disable checking
+	Mode string `json:"mode"`
 }
 
 type Config struct {
-	Bisnode BisnodeConfig `json:"bisnode"`
+	Bisnode   BisnodeConfig   `json:"bisnode"`
+	Cache     CacheConfig     `json:"cache"`
+	Search    SearchConfig    `json:"search"`
+	Geo       GeoConfig       `json:"geo"`
+	Screening ScreeningConfig `json:"screening"`
+	Batch     BatchConfig     `json:"batch"`
+	Jobs      JobsConfig      `json:"jobs"`
+	Audit     AuditConfig     `json:"audit"`
+	Contract  ContractConfig  `json:"contract"`
 }
 
 // Load loads configuration from config.json
@@ -30,5 +146,130 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	cfg.applyDefaults()
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
+
+// applyDefaults fills in values that were not set in config.json
+func (c *Config) applyDefaults() {
+	if c.Bisnode.BaseURL == "" {
+		c.Bisnode.BaseURL = DefaultBisnodeBaseURL
+	}
+	if c.Bisnode.Cassette.Dir == "" {
+		c.Bisnode.Cassette.Dir = "testdata/cassettes"
+	}
+
+	if c.Cache.TTLSeconds <= 0 {
+		c.Cache.TTLSeconds = 300 // 5 minutes
+	}
+	if c.Cache.StaleWhileRevalidateSeconds == nil {
+		staleWhileRevalidate := 3600 // 1 hour
+		c.Cache.StaleWhileRevalidateSeconds = &staleWhileRevalidate
+	}
+	if c.Cache.StaleIfErrorSeconds == nil {
+		staleIfError := 86400 // 1 day
+		c.Cache.StaleIfErrorSeconds = &staleIfError
+	}
+	if c.Cache.MaxEntries <= 0 {
+		c.Cache.MaxEntries = 10000
+	}
+
+	if c.Search.DefaultSearchMode == "" {
+		c.Search.DefaultSearchMode = models.SearchModeSmart.String()
+	}
+	if c.Search.DefaultOnlyFoundWords == nil {
+		onlyFoundWords := true
+		c.Search.DefaultOnlyFoundWords = &onlyFoundWords
+	}
+	if c.Search.DefaultResultLimit <= 0 {
+		c.Search.DefaultResultLimit = 10
+	}
+	if c.Search.MaxResultLimit <= 0 {
+		c.Search.MaxResultLimit = 100
+	}
+
+	if c.Geo.MaxRadiusMeters <= 0 {
+		c.Geo.MaxRadiusMeters = 5000
+	}
+	if c.Geo.ZipMarginMeters <= 0 {
+		c.Geo.ZipMarginMeters = 2000
+	}
+	if c.Geo.MaxZipCodes <= 0 {
+		c.Geo.MaxZipCodes = 20
+	}
+
+	if c.Screening.Concurrency <= 0 {
+		c.Screening.Concurrency = 4
+	}
+	if c.Screening.MaxNumbers <= 0 {
+		c.Screening.MaxNumbers = 10000
+	}
+	if c.Screening.RetentionMinutes <= 0 {
+		c.Screening.RetentionMinutes = 1440 // 1 day
+	}
+	if c.Screening.MaxRunning <= 0 {
+		c.Screening.MaxRunning = 2
+	}
+	if c.Screening.MaxStored <= 0 {
+		c.Screening.MaxStored = 100
+	}
+
+	if c.Batch.MaxItems <= 0 {
+		c.Batch.MaxItems = 500
+	}
+	if c.Batch.Concurrency <= 0 {
+		c.Batch.Concurrency = 8
+	}
+
+	if c.Jobs.Dir == "" {
+		c.Jobs.Dir = "data/jobs"
+	}
+	if c.Jobs.MaxItems <= 0 {
+		c.Jobs.MaxItems = 50000
+	}
+	if c.Jobs.Concurrency <= 0 {
+		c.Jobs.Concurrency = 4
+	}
+	if c.Jobs.MaxRunning <= 0 {
+		c.Jobs.MaxRunning = 2
+	}
+	if c.Jobs.RetentionHours <= 0 {
+		c.Jobs.RetentionHours = 168 // 1 week
+	}
+}
+
+// validate checks values that cannot be corrected with a default
+func (c *Config) validate() error {
+	switch c.Bisnode.Cassette.Mode {
+	case "", "record", "replay":
+	default:
+		return fmt.Errorf("bisnode.cassette.mode: must be \"record\", \"replay\" or empty, got %q", c.Bisnode.Cassette.Mode)
+	}
+	if c.Bisnode.Cassette.Mode != "" && c.Bisnode.Cassette.PseudonymKey == "" {
+		return fmt.Errorf("bisnode.cassette.pseudonym_key: is required to record or replay")
+	}
+	switch c.Contract.Mode {
+	case "", "log", "fail":
+	default:
+		return fmt.Errorf("contract.mode: must be \"log\", \"fail\" or empty, got %q", c.Contract.Mode)
+	}
+	if *c.Cache.StaleWhileRevalidateSeconds < 0 {
+		return fmt.Errorf("cache.stale_while_revalidate_seconds: must not be negative, got %d", *c.Cache.StaleWhileRevalidateSeconds)
+	}
+	if *c.Cache.StaleIfErrorSeconds < 0 {
+		return fmt.Errorf("cache.stale_if_error_seconds: must not be negative, got %d", *c.Cache.StaleIfErrorSeconds)
+	}
+	if _, err := models.ParseSearchMode(c.Search.DefaultSearchMode); err != nil {
+		return fmt.Errorf("search.default_search_mode: %w", err)
+	}
+	if c.Search.DefaultResultLimit > c.Search.MaxResultLimit {
+		return fmt.Errorf("search.default_result_limit %d exceeds search.max_result_limit %d",
+			c.Search.DefaultResultLimit, c.Search.MaxResultLimit)
+	}
+	return nil
+}
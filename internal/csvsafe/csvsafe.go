@@ -0,0 +1,15 @@
+// Package csvsafe prepares values for CSV files that are opened in
+// spreadsheets.
+package csvsafe
+
+import "strings"
+
+// EscapeFormula prefixes a value that spreadsheets would run as a formula with
+// a single quote, so looked up data or caller input cannot inject formulas
+// into a file
+func EscapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
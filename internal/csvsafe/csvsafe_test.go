@@ -0,0 +1,21 @@
+package csvsafe
+
+import "testing"
+
+func TestEscapeFormula(t *testing.T) {
+	tests := map[string]string{
+		"":            "",
+		"Eksempel AS": "Eksempel AS",
+		"=SUM(A1)":    "'=SUM(A1)",
+		"+4791234567": "'+4791234567",
+		"-1":          "'-1",
+		"@cmd":        "'@cmd",
+		"\t=1":        "'\t=1",
+		"a=b":         "a=b",
+	}
+	for input, want := range tests {
+		if got := EscapeFormula(input); got != want {
+			t.Errorf("EscapeFormula(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
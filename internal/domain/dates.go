@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// dateLayouts are the date formats seen in Bisnode responses, most common first
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"20060102",
+	"02.01.2006",
+}
+
+// ParseDate parses a date from a Bisnode response. It returns nil for empty
+// values, Bisnode's zero dates and formats it does not recognize, so a date
+// that cannot be trusted is left out rather than guessed.
+func ParseDate(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasPrefix(s, "0000") || s == "00000000" {
+		return nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return &t
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+// Package domain holds the directory and vehicle types returned by this API.
+// They are mapped from the Bisnode wire types in the models package, with
+// dates parsed, codes turned into enums and nested structures named.
+package domain
+
+import (
+	"bisnode/internal/geo"
+	"bisnode/internal/models"
+	"strings"
+	"time"
+)
+
+// Age is the age of a person in whole years, months and days
+type Age struct {
+	Years  int `json:"years"`
+	Months int `json:"months"`
+	Days   int `json:"days"`
+}
+
+// Reservation holds the marketing channels a person has reserved against
+type Reservation struct {
+	DirectMail    bool `json:"directMail"`
+	Telemarketing bool `json:"telemarketing"`
+	Humanitarian  bool `json:"humanitarian"`
+}
+
+// Address is a postal address with its provenance
+type Address struct {
+	Type       string     `json:"type,omitempty"`
+	StreetName string     `json:"streetName,omitempty"`
+	HouseNo    string     `json:"houseNo,omitempty"`
+	Entrance   string     `json:"entrance,omitempty"`
+	ZipCode    string     `json:"zipCode,omitempty"`
+	City       string     `json:"city,omitempty"`
+	Location   *geo.Point `json:"location,omitempty"`
+	Source     Source     `json:"source,omitempty"`
+	Quality    Quality    `json:"quality,omitempty"`
+	Dates      Dates      `json:"dates"`
+}
+
+// Line returns the street address on one line, such as "Storgata 5B"
+func (a Address) Line() string {
+	return strings.TrimSpace(a.StreetName + " " + a.HouseNo + a.Entrance)
+}
+
+// Phone is a phone number with its provenance
+type Phone struct {
+	Type    string  `json:"type,omitempty"`
+	Number  string  `json:"number"`
+	Source  Source  `json:"source,omitempty"`
+	Quality Quality `json:"quality,omitempty"`
+	Dates   Dates   `json:"dates"`
+}
+
+// Dates records when Bisnode first and last saw a piece of information
+type Dates struct {
+	FirstAcquired      *time.Time `json:"firstAcquired,omitempty"`
+	LastAcquired       *time.Time `json:"lastAcquired,omitempty"`
+	InformationChanged *time.Time `json:"informationChanged,omitempty"`
+}
+
+// Listing is a person or company in the directory
+type Listing struct {
+	Type               ListingType `json:"type"`
+	OrganizationNumber string      `json:"organizationNumber,omitempty"`
+	// Name is the full name of a person, or the name of a company
+	Name        string      `json:"name"`
+	FirstName   string      `json:"firstName,omitempty"`
+	MiddleName  string      `json:"middleName,omitempty"`
+	LastName    string      `json:"lastName,omitempty"`
+	Born        *time.Time  `json:"born,omitempty"`
+	Dead        *time.Time  `json:"dead,omitempty"`
+	Age         *Age        `json:"age,omitempty"`
+	Gender      Gender      `json:"gender,omitempty"`
+	Address     Address     `json:"address"`
+	Telephone   string      `json:"telephone,omitempty"`
+	Mobile      string      `json:"mobile,omitempty"`
+	Reservation Reservation `json:"reservation"`
+	// Contactable is set when the caller named an intended marketing channel,
+	// and is false when the reservation forbids it
+	Contactable *bool `json:"contactable,omitempty"`
+	// Addresses and Phones are the current and previous registrations
+	Addresses []Address `json:"addresses,omitempty"`
+	Phones    []Phone   `json:"phones,omitempty"`
+}
+
+// SearchResult is the API response for a directory search
+type SearchResult struct {
+	Result    []Listing  `json:"result"`
+	Freshness *Freshness `json:"freshness,omitempty"`
+	// Channel and Suppressed are set when results were checked against a marketing channel
+	Channel    Channel `json:"channel,omitempty"`
+	Suppressed int     `json:"suppressed,omitempty"`
+}
+
+// NewSearchResult maps a Bisnode directory search response
+func NewSearchResult(resp *models.DirectorySearchResponse) SearchResult {
+	return SearchResult{Result: NewListings(resp.Result)}
+}
+
+// Freshness describes how current a response served from cache is
+type Freshness struct {
+	// Status is one of "fresh", "stale" or "stale-if-error"
+	Status string `json:"status"`
+	// FetchedAt is when the data was retrieved from Bisnode
+	FetchedAt time.Time `json:"fetchedAt"`
+	// AgeSeconds is how old the data was when it was served
+	AgeSeconds int `json:"ageSeconds"`
+}
+
+// OrganizationSearchResult is the API response for an organization name search
+type OrganizationSearchResult struct {
+	Result    []models.OrganizationSummary `json:"result"`
+	Freshness *Freshness                   `json:"freshness,omitempty"`
+}
+
+// NewListings maps a list of Bisnode directory results
+func NewListings(results []models.DirectoryResult) []Listing {
+	listings := make([]Listing, len(results))
+	for i, r := range results {
+		listings[i] = NewListing(r)
+	}
+	return listings
+}
+
+// NewListing maps a single Bisnode directory result
+func NewListing(r models.DirectoryResult) Listing {
+	l := Listing{
+		Type:               ParseListingType(r.Type),
+		OrganizationNumber: strings.TrimSpace(r.OrganizationNumber),
+		Name:               strings.Join(strings.Fields(r.FirstName+" "+r.MiddleName+" "+r.LastName), " "),
+		FirstName:          strings.TrimSpace(r.FirstName),
+		MiddleName:         strings.TrimSpace(r.MiddleName),
+		LastName:           strings.TrimSpace(r.LastName),
+		Born:               ParseDate(r.Born),
+		Dead:               ParseDate(r.Dead),
+		Telephone:          strings.TrimSpace(r.Telephone),
+		Mobile:             strings.TrimSpace(r.Mobile),
+		Reservation: Reservation{
+			DirectMail:    r.Reservation.DirectMail,
+			Telemarketing: r.Reservation.Telemarketing,
+			Humanitarian:  r.Reservation.Humanitarian,
+		},
+		Address: Address{
+			StreetName: r.StreetName,
+			HouseNo:    r.HouseNo,
+			Entrance:   r.Entrance,
+			ZipCode:    r.ZipCode,
+			City:       r.City,
+			Location:   location(r.Latitude, r.Longitude),
+		},
+	}
+
+	// Companies have no gender, so only persons get an explicit unknown
+	if gender := ParseGender(r.Gender); gender != GenderUnknown || l.Type == ListingTypePerson {
+		l.Gender = gender
+	}
+
+	if r.Age != (models.DirectoryAge{}) {
+		l.Age = &Age{Years: r.Age.Year, Months: r.Age.Month, Days: r.Age.Day}
+	}
+
+	for _, a := range r.Addresses {
+		l.Addresses = append(l.Addresses, NewAddress(a))
+	}
+	for _, p := range r.Phones {
+		l.Phones = append(l.Phones, NewPhone(p))
+	}
+
+	return l
+}
+
+// NewAddress maps a Bisnode address registration
+func NewAddress(a models.DirectoryAddress) Address {
+	return Address{
+		Type:       a.Type,
+		StreetName: a.StreetName,
+		HouseNo:    a.HouseNo,
+		Entrance:   a.Entrance,
+		ZipCode:    a.ZipCode,
+		City:       a.City,
+		Location:   location(a.Latitude, a.Longitude),
+		Source:     ParseSource(a.Source),
+		Quality:    ParseQuality(a.Quality),
+		Dates:      NewDates(a.Date),
+	}
+}
+
+// NewPhone maps a Bisnode phone number registration
+func NewPhone(p models.DirectoryPhone) Phone {
+	return Phone{
+		Type:    p.Type,
+		Number:  strings.TrimSpace(p.Number),
+		Source:  ParseSource(p.Source),
+		Quality: ParseQuality(p.Quality),
+		Dates:   NewDates(p.Date),
+	}
+}
+
+// NewDates maps Bisnode's acquisition dates
+func NewDates(d models.DirectoryDate) Dates {
+	return Dates{
+		FirstAcquired:      ParseDate(d.FirstAcquired),
+		LastAcquired:       ParseDate(d.LastAcquired),
+		InformationChanged: ParseDate(d.InformationChanged),
+	}
+}
+
+// location returns the coordinate, or nil for Bisnode's 0,0 placeholder
+func location(lat, lon float64) *geo.Point {
+	p := geo.Point{Latitude: lat, Longitude: lon}
+	if p.IsZero() {
+		return nil
+	}
+	return &p
+}
+
+// NearbyListing is a listing with its distance from a geo search center
+type NearbyListing struct {
+	Listing
+	DistanceMeters float64 `json:"distanceMeters"`
+}
+
+// NearbyResult is the API response for a geo search
+type NearbyResult struct {
+	Center       geo.Point       `json:"center"`
+	RadiusMeters float64         `json:"radiusMeters"`
+	Result       []NearbyListing `json:"result"`
+	Channel      Channel         `json:"channel,omitempty"`
+	Suppressed   int             `json:"suppressed,omitempty"`
+}
+
+// NewNearbyResult maps a geo search response
+func NewNearbyResult(resp *models.GeoSearchResponse) NearbyResult {
+	result := NearbyResult{
+		Center:       resp.Center,
+		RadiusMeters: resp.RadiusMeters,
+		Result:       make([]NearbyListing, len(resp.Result)),
+	}
+	for i, r := range resp.Result {
+		result.Result[i] = NearbyListing{Listing: NewListing(r.DirectoryResult), DistanceMeters: r.DistanceMeters}
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"bisnode/internal/geo"
+	"bisnode/internal/models"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "iso date", input: "1985-03-14", want: "1985-03-14"},
+		{name: "iso datetime", input: "2019-11-02T10:15:00", want: "2019-11-02"},
+		{name: "rfc3339", input: "2019-11-02T10:15:00Z", want: "2019-11-02"},
+		{name: "compact", input: "19850314", want: "1985-03-14"},
+		{name: "norwegian", input: "14.03.1985", want: "1985-03-14"},
+		{name: "surrounding space", input: " 1985-03-14 ", want: "1985-03-14"},
+		{name: "empty", input: "", want: ""},
+		{name: "zero date", input: "0000-00-00", want: ""},
+		{name: "unrecognized", input: "March 1985", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseDate(tt.input)
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("ParseDate(%q) = %v, want nil", tt.input, got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("ParseDate(%q) = nil, want %s", tt.input, tt.want)
+			}
+			if s := got.Format("2006-01-02"); s != tt.want {
+				t.Errorf("ParseDate(%q) = %s, want %s", tt.input, s, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEnums(t *testing.T) {
+	listingTypes := map[string]ListingType{
+		"Person":  ListingTypePerson,
+		"company": ListingTypeCompany,
+		"":        ListingTypeUnknown,
+		"other":   ListingTypeUnknown,
+	}
+	for input, want := range listingTypes {
+		if got := ParseListingType(input); got != want {
+			t.Errorf("ParseListingType(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	genders := map[string]Gender{
+		"M":      GenderMale,
+		"kvinne": GenderFemale,
+		"K":      GenderFemale,
+		"F":      GenderFemale,
+		"":       GenderUnknown,
+	}
+	for input, want := range genders {
+		if got := ParseGender(input); got != want {
+			t.Errorf("ParseGender(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	qualities := map[string]Quality{
+		"A":      QualityHigh,
+		"1":      QualityHigh,
+		"medium": QualityMedium,
+		"C":      QualityLow,
+		"?":      QualityUnknown,
+	}
+	for input, want := range qualities {
+		if got := ParseQuality(input); got != want {
+			t.Errorf("ParseQuality(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	if got := ParseSource(" freg "); got != "FREG" {
+		t.Errorf("ParseSource() = %q, want FREG", got)
+	}
+	if !QualityHigh.Better(QualityLow) || QualityUnknown.Better(QualityLow) {
+		t.Error("Quality.Better does not order high > low > unknown")
+	}
+}
+
+func TestNewListingPerson(t *testing.T) {
+	r := models.DirectoryResult{
+		Type:       "Person",
+		Born:       "1985-03-14",
+		Age:        models.DirectoryAge{Year: 39, Month: 2, Day: 5},
+		Gender:     "M",
+		FirstName:  "Ola",
+		MiddleName: "Johan",
+		LastName:   "Nordmann",
+		StreetName: "Storgata",
+		HouseNo:    "5",
+		Entrance:   "B",
+		ZipCode:    "0155",
+		City:       "OSLO",
+		Latitude:   59.9139,
+		Longitude:  10.7522,
+		Mobile:     "91234567",
+		Reservation: models.DirectoryReservation{
+			Telemarketing: true,
+		},
+		Addresses: []models.DirectoryAddress{{
+			Source:     "freg",
+			Quality:    "A",
+			StreetName: "Storgata",
+			HouseNo:    "5",
+			ZipCode:    "0155",
+			City:       "OSLO",
+			Date: models.DirectoryDate{
+				FirstAcquired: "2015-06-01",
+				LastAcquired:  "2024-01-31",
+			},
+		}},
+		Phones: []models.DirectoryPhone{{
+			Source:  "tele",
+			Quality: "2",
+			Number:  " 91234567 ",
+			Date:    models.DirectoryDate{FirstAcquired: "20180101"},
+		}},
+	}
+
+	l := NewListing(r)
+
+	if l.Type != ListingTypePerson {
+		t.Errorf("Type = %q, want person", l.Type)
+	}
+	if l.Name != "Ola Johan Nordmann" {
+		t.Errorf("Name = %q, want %q", l.Name, "Ola Johan Nordmann")
+	}
+	if l.Born == nil || !l.Born.Equal(time.Date(1985, 3, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Born = %v, want 1985-03-14", l.Born)
+	}
+	if l.Dead != nil {
+		t.Errorf("Dead = %v, want nil", l.Dead)
+	}
+	if l.Age == nil || *l.Age != (Age{Years: 39, Months: 2, Days: 5}) {
+		t.Errorf("Age = %+v, want 39 years 2 months 5 days", l.Age)
+	}
+	if l.Gender != GenderMale {
+		t.Errorf("Gender = %q, want male", l.Gender)
+	}
+	if l.Address.Line() != "Storgata 5B" {
+		t.Errorf("Address.Line() = %q, want %q", l.Address.Line(), "Storgata 5B")
+	}
+	if l.Address.Location == nil || *l.Address.Location != (geo.Point{Latitude: 59.9139, Longitude: 10.7522}) {
+		t.Errorf("Address.Location = %v, want 59.9139,10.7522", l.Address.Location)
+	}
+	if !l.Reservation.Telemarketing || l.Reservation.DirectMail {
+		t.Errorf("Reservation = %+v, want only telemarketing", l.Reservation)
+	}
+
+	if len(l.Addresses) != 1 {
+		t.Fatalf("len(Addresses) = %d, want 1", len(l.Addresses))
+	}
+	a := l.Addresses[0]
+	if a.Source != "FREG" || a.Quality != QualityHigh {
+		t.Errorf("Address source/quality = %q/%q, want FREG/high", a.Source, a.Quality)
+	}
+	if a.Location != nil {
+		t.Errorf("Address.Location = %v, want nil for 0,0", a.Location)
+	}
+	if a.Dates.FirstAcquired == nil || a.Dates.LastAcquired == nil || a.Dates.InformationChanged != nil {
+		t.Errorf("Address.Dates = %+v, want first and last acquired only", a.Dates)
+	}
+
+	if len(l.Phones) != 1 {
+		t.Fatalf("len(Phones) = %d, want 1", len(l.Phones))
+	}
+	p := l.Phones[0]
+	if p.Number != "91234567" || p.Quality != QualityMedium || p.Dates.FirstAcquired == nil {
+		t.Errorf("Phone = %+v, want trimmed number, medium quality and first acquired", p)
+	}
+}
+
+func TestNewListingCompany(t *testing.T) {
+	l := NewListing(models.DirectoryResult{
+		Type:               "Company",
+		OrganizationNumber: "923609016",
+		LastName:           "Equinor ASA",
+	})
+
+	if l.Type != ListingTypeCompany {
+		t.Errorf("Type = %q, want company", l.Type)
+	}
+	if l.Name != "Equinor ASA" {
+		t.Errorf("Name = %q, want %q", l.Name, "Equinor ASA")
+	}
+	if l.Gender != "" {
+		t.Errorf("Gender = %q, want empty for companies", l.Gender)
+	}
+	if l.Age != nil {
+		t.Errorf("Age = %+v, want nil", l.Age)
+	}
+	if l.Address.Location != nil {
+		t.Errorf("Address.Location = %v, want nil", l.Address.Location)
+	}
+}
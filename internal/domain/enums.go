@@ -0,0 +1,95 @@
+package domain
+
+import "strings"
+
+// ListingType tells whether a listing is a person or a company
+type ListingType string
+
+const (
+	ListingTypePerson  ListingType = "person"
+	ListingTypeCompany ListingType = "company"
+	ListingTypeUnknown ListingType = "unknown"
+)
+
+// ParseListingType maps Bisnode's listing type to a ListingType
+func ParseListingType(s string) ListingType {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "person", "private", "privat", "p":
+		return ListingTypePerson
+	case "company", "business", "bedrift", "firma", "c", "b":
+		return ListingTypeCompany
+	}
+	return ListingTypeUnknown
+}
+
+// Gender is the registered gender of a person
+type Gender string
+
+const (
+	GenderMale    Gender = "male"
+	GenderFemale  Gender = "female"
+	GenderUnknown Gender = "unknown"
+)
+
+// ParseGender maps Bisnode's gender codes, in English or Norwegian, to a Gender
+func ParseGender(s string) Gender {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "m", "male", "mann", "1":
+		return GenderMale
+	case "f", "k", "female", "kvinne", "2":
+		return GenderFemale
+	}
+	return GenderUnknown
+}
+
+// Source is the register an address or phone number was obtained from.
+// Bisnode's source codes are passed through upper-cased, since the set of
+// sources grows over time.
+type Source string
+
+// ParseSource normalizes Bisnode's source code
+func ParseSource(s string) Source {
+	return Source(strings.ToUpper(strings.TrimSpace(s)))
+}
+
+// Quality is how reliable an address or phone number is considered to be
+type Quality string
+
+const (
+	QualityHigh    Quality = "high"
+	QualityMedium  Quality = "medium"
+	QualityLow     Quality = "low"
+	QualityUnknown Quality = "unknown"
+)
+
+// ParseQuality maps Bisnode's quality grading, either a word, a letter A-C or
+// a number 1-3 with 1 being best, to a Quality
+func ParseQuality(s string) Quality {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "high", "h", "a", "1", "good", "verified":
+		return QualityHigh
+	case "medium", "m", "b", "2":
+		return QualityMedium
+	case "low", "l", "c", "3", "poor":
+		return QualityLow
+	}
+	return QualityUnknown
+}
+
+// rank orders qualities from best to worst, for sorting
+func (q Quality) rank() int {
+	switch q {
+	case QualityHigh:
+		return 3
+	case QualityMedium:
+		return 2
+	case QualityLow:
+		return 1
+	}
+	return 0
+}
+
+// Better reports whether q is a better quality than other
+func (q Quality) Better(other Quality) bool {
+	return q.rank() > other.rank()
+}
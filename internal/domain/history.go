@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"bisnode/internal/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimelineKind tells whether a timeline entry is an address or a phone number
+type TimelineKind string
+
+const (
+	TimelineAddress TimelineKind = "address"
+	TimelinePhone   TimelineKind = "phone"
+)
+
+// TimelineEntry is an address or phone number merged from every registration
+// of it, with the period it was registered in
+type TimelineEntry struct {
+	Kind TimelineKind `json:"kind"`
+	// Value is the address on one line, or the phone number
+	Value   string   `json:"value"`
+	Address *Address `json:"address,omitempty"`
+	// From is the earliest date the entry was first acquired
+	From *time.Time `json:"from,omitempty"`
+	// To is the latest date the entry was last acquired
+	To *time.Time `json:"to,omitempty"`
+	// Current is true for the address or phone number the person has now
+	Current bool     `json:"current"`
+	Sources []Source `json:"sources,omitempty"`
+	// Quality is the best quality among the merged registrations
+	Quality Quality `json:"quality"`
+	// Confidence is a score between 0 and 1 based on quality and the number of sources
+	Confidence float64 `json:"confidence"`
+	// Registrations is the number of registrations merged into the entry
+	Registrations int `json:"registrations"`
+}
+
+// History is the address and phone history of a listing
+type History struct {
+	Name               string          `json:"name"`
+	OrganizationNumber string          `json:"organizationNumber,omitempty"`
+	Addresses          []TimelineEntry `json:"addresses"`
+	Phones             []TimelineEntry `json:"phones"`
+	// Timeline holds both addresses and phone numbers, oldest first
+	Timeline []TimelineEntry `json:"timeline"`
+}
+
+// HistoryResult is the history of every listing matching a lookup
+type HistoryResult struct {
+	Result    []History  `json:"result"`
+	Freshness *Freshness `json:"freshness,omitempty"`
+}
+
+// NewHistoryResult builds the history of each listing in a Bisnode directory search response
+func NewHistoryResult(resp *models.DirectorySearchResponse) HistoryResult {
+	histories := make([]History, len(resp.Result))
+	for i, r := range resp.Result {
+		histories[i] = NewHistory(NewListing(r))
+	}
+	return HistoryResult{Result: histories}
+}
+
+// NewHistory merges, deduplicates and orders the address and phone
+// registrations of a listing. Entries are ordered by the date they were first
+// acquired, oldest first, with undated entries last.
+func NewHistory(l Listing) History {
+	h := History{
+		Name:               l.Name,
+		OrganizationNumber: l.OrganizationNumber,
+		Addresses:          addressTimeline(l),
+		Phones:             phoneTimeline(l),
+	}
+
+	h.Timeline = append(append([]TimelineEntry{}, h.Addresses...), h.Phones...)
+	sortTimeline(h.Timeline)
+
+	return h
+}
+
+// addressTimeline merges the address registrations of a listing
+func addressTimeline(l Listing) []TimelineEntry {
+	entries := []TimelineEntry{}
+	index := make(map[string]int)
+
+	for _, a := range l.Addresses {
+		key := addressKey(a)
+		if i, ok := index[key]; ok {
+			mergeRegistration(&entries[i], a.Source, a.Quality, a.Dates)
+			continue
+		}
+
+		address := a
+		entry := TimelineEntry{Kind: TimelineAddress, Value: addressValue(a), Address: &address}
+		mergeRegistration(&entry, a.Source, a.Quality, a.Dates)
+		index[key] = len(entries)
+		entries = append(entries, entry)
+	}
+
+	// The listing's own address is the current one; without it, fall back to
+	// the address that was seen most recently
+	current := -1
+	if i, ok := index[addressKey(l.Address)]; ok && l.Address.StreetName != "" {
+		current = i
+	} else {
+		current = mostRecent(entries)
+	}
+	if current >= 0 {
+		entries[current].Current = true
+	}
+
+	finishTimeline(entries)
+	return entries
+}
+
+// phoneTimeline merges the phone registrations of a listing
+func phoneTimeline(l Listing) []TimelineEntry {
+	entries := []TimelineEntry{}
+	index := make(map[string]int)
+
+	for _, p := range l.Phones {
+		key := digits(p.Number)
+		if key == "" {
+			continue
+		}
+		if i, ok := index[key]; ok {
+			mergeRegistration(&entries[i], p.Source, p.Quality, p.Dates)
+			continue
+		}
+
+		entry := TimelineEntry{Kind: TimelinePhone, Value: p.Number}
+		mergeRegistration(&entry, p.Source, p.Quality, p.Dates)
+		index[key] = len(entries)
+		entries = append(entries, entry)
+	}
+
+	// A person can have both a current mobile and a current landline
+	found := false
+	for _, number := range []string{l.Mobile, l.Telephone} {
+		if i, ok := index[digits(number)]; ok && number != "" {
+			entries[i].Current = true
+			found = true
+		}
+	}
+	if !found {
+		if i := mostRecent(entries); i >= 0 {
+			entries[i].Current = true
+		}
+	}
+
+	finishTimeline(entries)
+	return entries
+}
+
+// mergeRegistration folds a single registration into an entry
+func mergeRegistration(e *TimelineEntry, source Source, quality Quality, dates Dates) {
+	e.Registrations++
+
+	if source != "" && !containsSource(e.Sources, source) {
+		e.Sources = append(e.Sources, source)
+	}
+	if e.Quality == "" || quality.Better(e.Quality) {
+		e.Quality = quality
+	}
+
+	if first := dates.FirstAcquired; first != nil && (e.From == nil || first.Before(*e.From)) {
+		e.From = first
+	}
+	last := dates.LastAcquired
+	if last == nil {
+		last = dates.InformationChanged
+	}
+	if last != nil && (e.To == nil || last.After(*e.To)) {
+		e.To = last
+	}
+}
+
+// finishTimeline scores and orders merged entries
+func finishTimeline(entries []TimelineEntry) {
+	for i := range entries {
+		entries[i].Confidence = confidence(entries[i])
+	}
+	sortTimeline(entries)
+}
+
+// confidence scores an entry from the quality of its best registration, with
+// a bonus for every additional source that confirms it
+func confidence(e TimelineEntry) float64 {
+	var score float64
+	switch e.Quality {
+	case QualityHigh:
+		score = 0.8
+	case QualityMedium:
+		score = 0.6
+	case QualityLow:
+		score = 0.4
+	default:
+		score = 0.3
+	}
+
+	if len(e.Sources) > 1 {
+		score += 0.1 * float64(len(e.Sources)-1)
+	}
+
+	return min(score, 1)
+}
+
+// sortTimeline orders entries oldest first, with undated entries last
+func sortTimeline(entries []TimelineEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].From, entries[j].From
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		}
+		return a.Before(*b)
+	})
+}
+
+// mostRecent returns the index of the entry that was acquired last, or -1
+func mostRecent(entries []TimelineEntry) int {
+	best := -1
+	for i, e := range entries {
+		if e.To == nil {
+			continue
+		}
+		if best < 0 || e.To.After(*entries[best].To) {
+			best = i
+		}
+	}
+	return best
+}
+
+// addressKey identifies an address regardless of case and spacing
+func addressKey(a Address) string {
+	return strings.ToLower(strings.Join(strings.Fields(a.StreetName+"|"+a.HouseNo+a.Entrance+"|"+a.ZipCode), " "))
+}
+
+// addressValue formats an address as "Storgata 5B, 0155 OSLO"
+func addressValue(a Address) string {
+	return strings.TrimSpace(strings.TrimSuffix(a.Line()+", "+strings.TrimSpace(a.ZipCode+" "+a.City), ", "))
+}
+
+// digits returns only the digits of a phone number, so that formatting
+// differences do not prevent merging
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// containsSource reports whether sources contains s
+func containsSource(sources []Source, s Source) bool {
+	for _, src := range sources {
+		if src == s {
+			return true
+		}
+	}
+	return false
+}
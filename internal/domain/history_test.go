@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewHistory(t *testing.T) {
+	date := func(s string) *time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &d
+	}
+
+	current := Address{StreetName: "Storgata", HouseNo: "5", Entrance: "B", ZipCode: "0155", City: "OSLO"}
+	listing := Listing{
+		Name:    "Ola Nordmann",
+		Mobile:  "91234567",
+		Address: current,
+		Addresses: []Address{
+			{StreetName: "Storgata", HouseNo: "5", Entrance: "B", ZipCode: "0155", City: "OSLO",
+				Source: "FREG", Quality: QualityMedium, Dates: Dates{FirstAcquired: date("2020-01-01"), LastAcquired: date("2023-06-01")}},
+			{StreetName: "Kongens gate", HouseNo: "1", ZipCode: "7011", City: "TRONDHEIM",
+				Source: "FREG", Quality: QualityHigh, Dates: Dates{FirstAcquired: date("2010-05-01"), LastAcquired: date("2019-12-31")}},
+			{StreetName: "STORGATA", HouseNo: "5", Entrance: "b", ZipCode: "0155", City: "OSLO",
+				Source: "TELENOR", Quality: QualityHigh, Dates: Dates{FirstAcquired: date("2019-11-15"), LastAcquired: date("2024-02-01")}},
+		},
+		Phones: []Phone{
+			{Number: "22 33 44 55", Source: "TELENOR", Quality: QualityLow},
+			{Number: "912 34 567", Source: "TELIA", Quality: QualityHigh, Dates: Dates{FirstAcquired: date("2015-03-01")}},
+			{Number: "91234567", Source: "TELIA", Quality: QualityMedium, Dates: Dates{LastAcquired: date("2024-01-01")}},
+		},
+	}
+
+	h := NewHistory(listing)
+
+	if len(h.Addresses) != 2 {
+		t.Fatalf("got %d addresses, want 2", len(h.Addresses))
+	}
+	old, now := h.Addresses[0], h.Addresses[1]
+	if old.Value != "Kongens gate 1, 7011 TRONDHEIM" || old.Current {
+		t.Errorf("first address = %q current=%v, want previous Kongens gate", old.Value, old.Current)
+	}
+	if !now.Current || now.Registrations != 2 {
+		t.Errorf("current address = %+v, want current with 2 registrations", now)
+	}
+	if got := now.From.Format("2006-01-02"); got != "2019-11-15" {
+		t.Errorf("current address from = %s, want 2019-11-15", got)
+	}
+	if got := now.To.Format("2006-01-02"); got != "2024-02-01" {
+		t.Errorf("current address to = %s, want 2024-02-01", got)
+	}
+	if now.Quality != QualityHigh || len(now.Sources) != 2 {
+		t.Errorf("current address quality=%q sources=%v, want high from 2 sources", now.Quality, now.Sources)
+	}
+	if now.Confidence <= old.Confidence {
+		t.Errorf("confidence %v should exceed single source confidence %v", now.Confidence, old.Confidence)
+	}
+
+	if len(h.Phones) != 2 {
+		t.Fatalf("got %d phones, want 2", len(h.Phones))
+	}
+	if h.Phones[0].Value != "912 34 567" || !h.Phones[0].Current || h.Phones[0].Registrations != 2 {
+		t.Errorf("first phone = %+v, want current mobile merged from 2 registrations", h.Phones[0])
+	}
+	if h.Phones[1].Current || h.Phones[1].From != nil {
+		t.Errorf("undated landline = %+v, want previous and ordered last", h.Phones[1])
+	}
+
+	if len(h.Timeline) != 4 {
+		t.Fatalf("got %d timeline entries, want 4", len(h.Timeline))
+	}
+	wantKinds := []TimelineKind{TimelineAddress, TimelinePhone, TimelineAddress, TimelinePhone}
+	for i, e := range h.Timeline {
+		if e.Kind != wantKinds[i] {
+			t.Errorf("timeline[%d].Kind = %q, want %q", i, e.Kind, wantKinds[i])
+		}
+	}
+}
+
+func TestNewHistoryWithoutCurrentAddress(t *testing.T) {
+	newer := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	older := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	h := NewHistory(Listing{Addresses: []Address{
+		{StreetName: "Storgata", HouseNo: "5", ZipCode: "0155", Dates: Dates{LastAcquired: &older}},
+		{StreetName: "Bygdøy allé", HouseNo: "2", ZipCode: "0257", Dates: Dates{LastAcquired: &newer}},
+	}})
+
+	if h.Addresses[0].Current || !h.Addresses[1].Current {
+		t.Errorf("expected the most recently acquired address to be current, got %+v", h.Addresses)
+	}
+}
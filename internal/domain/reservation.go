@@ -0,0 +1,145 @@
+package domain
+
+// Channel is a marketing channel a person can reserve against
+type Channel string
+
+const (
+	ChannelTelemarketing Channel = "telemarketing"
+	ChannelDirectMail    Channel = "directMail"
+	ChannelHumanitarian  Channel = "humanitarian"
+)
+
+// Allows reports whether the reservation permits contact through channel
+func (r Reservation) Allows(c Channel) bool {
+	switch c {
+	case ChannelTelemarketing:
+		return !r.Telemarketing
+	case ChannelDirectMail:
+		return !r.DirectMail
+	case ChannelHumanitarian:
+		return !r.Humanitarian
+	}
+	return true
+}
+
+// ReservationPolicy decides what happens to listings whose reservation
+// forbids the intended channel
+type ReservationPolicy string
+
+const (
+	// ReservationFlag keeps reserved listings, marked as not contactable
+	ReservationFlag ReservationPolicy = "flag"
+	// ReservationSuppress removes reserved listings from the result
+	ReservationSuppress ReservationPolicy = "suppress"
+)
+
+// ApplyChannel marks each listing as contactable or not through channel, and
+// drops the ones that are not when policy is ReservationSuppress. It returns
+// the remaining listings and the number suppressed.
+func ApplyChannel(listings []Listing, channel Channel, policy ReservationPolicy) ([]Listing, int) {
+	kept := make([]Listing, 0, len(listings))
+	for _, l := range listings {
+		contactable := l.Reservation.Allows(channel)
+		if !contactable && policy == ReservationSuppress {
+			continue
+		}
+		l.Contactable = &contactable
+		kept = append(kept, l)
+	}
+	return kept, len(listings) - len(kept)
+}
+
+// ApplyChannel applies the reservation policy for channel to the result
+func (r *SearchResult) ApplyChannel(channel Channel, policy ReservationPolicy) {
+	r.Result, r.Suppressed = ApplyChannel(r.Result, channel, policy)
+	r.Channel = channel
+}
+
+// ApplyChannel applies the reservation policy for channel to the result
+func (r *NearbyResult) ApplyChannel(channel Channel, policy ReservationPolicy) {
+	kept := make([]NearbyListing, 0, len(r.Result))
+	for _, l := range r.Result {
+		contactable := l.Reservation.Allows(channel)
+		if !contactable && policy == ReservationSuppress {
+			continue
+		}
+		l.Contactable = &contactable
+		kept = append(kept, l)
+	}
+	r.Suppressed = len(r.Result) - len(kept)
+	r.Result = kept
+	r.Channel = channel
+}
+
+// WashStatus is the outcome of washing a single phone number
+type WashStatus string
+
+const (
+	// WashContactable means a listing was found and none reserve against the channel
+	WashContactable WashStatus = "contactable"
+	// WashReserved means a listing for the number reserves against the channel
+	WashReserved WashStatus = "reserved"
+	// WashNotFound means no listing was found, so the reservation is unknown
+	WashNotFound WashStatus = "not_found"
+	// WashInvalid means the phone number could not be parsed as a Norwegian mobile number
+	WashInvalid WashStatus = "invalid"
+	// WashError means the lookup failed
+	WashError WashStatus = "error"
+)
+
+// WashEntry is the verdict for one phone number. Only numbers with status
+// contactable may be contacted; a number without a listing has no known
+// reservation and is not considered contactable.
+type WashEntry struct {
+	PhoneNumber string       `json:"phoneNumber"`
+	Contactable bool         `json:"contactable"`
+	Status      WashStatus   `json:"status"`
+	Name        string       `json:"name,omitempty"`
+	Reservation *Reservation `json:"reservation,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// WashResult is the API response for washing a list of phone numbers
+type WashResult struct {
+	Channel     Channel     `json:"channel"`
+	Contactable int         `json:"contactable"`
+	Result      []WashEntry `json:"result"`
+}
+
+// NewWashEntry decides whether number may be contacted through channel given
+// the listings found for it. The number is reserved if any listing reserves
+// against the channel.
+func NewWashEntry(number string, listings []Listing, channel Channel) WashEntry {
+	if len(listings) == 0 {
+		return WashEntry{PhoneNumber: number, Status: WashNotFound}
+	}
+
+	entry := WashEntry{PhoneNumber: number, Status: WashContactable, Contactable: true, Name: listings[0].Name}
+	reservation := listings[0].Reservation
+	for _, l := range listings {
+		if !l.Reservation.Allows(channel) {
+			entry.Status, entry.Contactable, entry.Name = WashReserved, false, l.Name
+			reservation = l.Reservation
+			break
+		}
+	}
+	entry.Reservation = &reservation
+
+	return entry
+}
+
+// NewWashError records a number whose lookup failed
+func NewWashError(number string, status WashStatus, err error) WashEntry {
+	return WashEntry{PhoneNumber: number, Status: status, Error: err.Error()}
+}
+
+// NewWashResult collects the entries and counts the contactable numbers
+func NewWashResult(channel Channel, entries []WashEntry) WashResult {
+	result := WashResult{Channel: channel, Result: entries}
+	for _, e := range entries {
+		if e.Contactable {
+			result.Contactable++
+		}
+	}
+	return result
+}
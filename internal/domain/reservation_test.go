@@ -0,0 +1,61 @@
+package domain
+
+import "testing"
+
+func TestReservationAllows(t *testing.T) {
+	r := Reservation{Telemarketing: true}
+
+	if r.Allows(ChannelTelemarketing) {
+		t.Error("telemarketing reservation should forbid telemarketing")
+	}
+	if !r.Allows(ChannelDirectMail) || !r.Allows(ChannelHumanitarian) {
+		t.Error("telemarketing reservation should allow other channels")
+	}
+}
+
+func TestApplyChannel(t *testing.T) {
+	listings := []Listing{
+		{Name: "Reserved", Reservation: Reservation{DirectMail: true}},
+		{Name: "Open"},
+	}
+
+	flagged, suppressed := ApplyChannel(listings, ChannelDirectMail, ReservationFlag)
+	if len(flagged) != 2 || suppressed != 0 {
+		t.Fatalf("flag kept %d and suppressed %d, want 2 and 0", len(flagged), suppressed)
+	}
+	if *flagged[0].Contactable || !*flagged[1].Contactable {
+		t.Errorf("contactable = %v, %v, want false, true", *flagged[0].Contactable, *flagged[1].Contactable)
+	}
+	if listings[0].Contactable != nil {
+		t.Error("ApplyChannel must not modify its input")
+	}
+
+	kept, suppressed := ApplyChannel(listings, ChannelDirectMail, ReservationSuppress)
+	if len(kept) != 1 || kept[0].Name != "Open" || suppressed != 1 {
+		t.Errorf("suppress kept %v and suppressed %d, want only Open and 1", kept, suppressed)
+	}
+}
+
+func TestNewWashEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		listings []Listing
+		want     WashStatus
+	}{
+		{name: "not found", listings: nil, want: WashNotFound},
+		{name: "contactable", listings: []Listing{{Name: "Ola"}}, want: WashContactable},
+		{name: "any listing reserved", listings: []Listing{{Name: "Ola"}, {Name: "Kari", Reservation: Reservation{Telemarketing: true}}}, want: WashReserved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewWashEntry("91234567", tt.listings, ChannelTelemarketing)
+			if got.Status != tt.want {
+				t.Errorf("status = %q, want %q", got.Status, tt.want)
+			}
+			if got.Contactable != (tt.want == WashContactable) {
+				t.Errorf("contactable = %v for status %q", got.Contactable, got.Status)
+			}
+		})
+	}
+}
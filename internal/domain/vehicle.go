@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"bisnode/internal/models"
+	"bisnode/internal/vehicleid"
+)
+
+// VehicleSearchResult is the API response for a motor vehicle search. The
+// vehicles keep Bisnode's field names.
+type VehicleSearchResult struct {
+	models.MotorVehicleSearchResponse
+	// Identifier is the classified search term, including decoded VIN information
+	Identifier *vehicleid.Identifier `json:"identifier,omitempty"`
+	Freshness  *Freshness            `json:"freshness,omitempty"`
+}
+
+// NewVehicleSearchResult maps a Bisnode motor vehicle search response for
+// the identifier that was searched for
+func NewVehicleSearchResult(resp *models.MotorVehicleSearchResponse, id vehicleid.Identifier) VehicleSearchResult {
+	return VehicleSearchResult{MotorVehicleSearchResponse: *resp, Identifier: &id}
+}
@@ -0,0 +1,241 @@
+// Package enrich appends directory and motor vehicle data to the rows of a
+// CSV file, looking up an organization number, mobile number, license plate
+// or VIN from one of its columns.
+package enrich
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/csvsafe"
+	"bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrorColumn is the header of the column appended with the error of each row
+const ErrorColumn = "error"
+
+// LookupFunc looks up a single item
+type LookupFunc func(ctx context.Context, item bisnode.BatchItem) bisnode.BatchOutcome
+
+// Options selects what to look up and which fields to append
+type Options struct {
+	// Column is the header of the column to look up. When empty, the first
+	// column whose header names a known lookup is used.
+	Column string
+	// Type is the lookup held by the column; when empty it is guessed from the header
+	Type bisnode.BatchItemType
+	// Fields are the columns to append; when empty a default set for the type is used
+	Fields []string
+}
+
+// Summary counts the rows of an enriched file
+type Summary struct {
+	Rows     int
+	Enriched int
+	NotFound int
+	Failed   int
+}
+
+// Enricher enriches CSV files
+type Enricher struct {
+	lookup      LookupFunc
+	concurrency int
+	maxRows     int
+}
+
+// New creates a new Enricher looking up concurrency rows at a time. maxRows
+// limits the rows of one file, 0 means unlimited.
+func New(lookup LookupFunc, concurrency, maxRows int) *Enricher {
+	return &Enricher{
+		lookup:      lookup,
+		concurrency: max(concurrency, 1),
+		maxRows:     maxRows,
+	}
+}
+
+// Enrich reads a CSV file with a header row from in, and writes it to out
+// with the selected fields and an error column appended to every row. The
+// input is read and checked before anything is written, so an error about
+// the file or the options leaves out untouched.
+func (e *Enricher) Enrich(ctx context.Context, in io.Reader, out io.Writer, opts Options) (Summary, error) {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return Summary{}, binding.ErrBodyTooLarge
+		}
+		return Summary{}, &binding.DecodeError{Message: "Malformed CSV", Err: err}
+	}
+	if len(records) == 0 {
+		return Summary{}, &binding.DecodeError{Message: "CSV file is empty"}
+	}
+
+	header, rows := records[0], records[1:]
+	if e.maxRows > 0 && len(rows) > e.maxRows {
+		return Summary{}, validation.Errorf("file", "must contain at most %d rows", e.maxRows)
+	}
+
+	column, itemType, err := resolveColumn(header, opts)
+	if err != nil {
+		return Summary{}, err
+	}
+	selected, err := resolveFields(opts.Fields, itemType)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	outcomes := e.lookupRows(ctx, rows, column, itemType)
+
+	writer := csv.NewWriter(out)
+	outHeader := append([]string{}, header...)
+	for _, f := range selected {
+		outHeader = append(outHeader, f.name)
+	}
+	writer.Write(append(outHeader, ErrorColumn))
+
+	summary := Summary{Rows: len(rows)}
+	for i, row := range rows {
+		o := outcomes[i]
+		record := append([]string{}, row...)
+		// Pad short rows so that the appended columns line up with the header
+		for len(record) < len(header) {
+			record = append(record, "")
+		}
+		for _, f := range selected {
+			record = append(record, csvsafe.EscapeFormula(f.value(o)))
+		}
+
+		message := rowError(o)
+		switch {
+		case o.Err != nil:
+			summary.Failed++
+		case message != "":
+			summary.NotFound++
+		default:
+			summary.Enriched++
+		}
+		writer.Write(append(record, csvsafe.EscapeFormula(message)))
+	}
+
+	writer.Flush()
+	return summary, writer.Error()
+}
+
+// lookupRows looks up the column of every row with bounded concurrency
+func (e *Enricher) lookupRows(ctx context.Context, rows [][]string, column int, itemType bisnode.BatchItemType) []bisnode.BatchOutcome {
+	outcomes := make([]bisnode.BatchOutcome, len(rows))
+	sem := make(chan struct{}, e.concurrency)
+	var wg sync.WaitGroup
+
+	for i, row := range rows {
+		value := ""
+		if column < len(row) {
+			value = strings.TrimSpace(row[column])
+		}
+		item := bisnode.BatchItem{Type: itemType, Value: value}
+		if value == "" {
+			outcomes[i] = bisnode.BatchOutcome{Item: item, Err: validation.Errorf("value", "is empty")}
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			outcomes[i] = bisnode.BatchOutcome{Item: item, Err: ctx.Err()}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, item bisnode.BatchItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			outcomes[i] = e.lookup(ctx, item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+// resolveColumn finds the column to look up and the type of lookup it holds
+func resolveColumn(header []string, opts Options) (int, bisnode.BatchItemType, error) {
+	if opts.Type != "" {
+		switch opts.Type {
+		case bisnode.BatchOrganizationNumber, bisnode.BatchMobileNumber, bisnode.BatchLicensePlate, bisnode.BatchVIN:
+		default:
+			return 0, "", validation.Errorf("type", "must be one of orgno, mobile, plate, vin")
+		}
+	}
+
+	if opts.Column != "" {
+		for i, h := range header {
+			if !strings.EqualFold(strings.TrimSpace(h), opts.Column) {
+				continue
+			}
+			if opts.Type != "" {
+				return i, opts.Type, nil
+			}
+			if t, ok := columnType(h); ok {
+				return i, t, nil
+			}
+			return 0, "", validation.Errorf("type", "is required, since it cannot be guessed from column %q", h)
+		}
+		return 0, "", validation.Errorf("column", "%q is not in the header", opts.Column)
+	}
+
+	for i, h := range header {
+		if t, ok := columnType(h); ok && (opts.Type == "" || t == opts.Type) {
+			return i, t, nil
+		}
+	}
+	if opts.Type != "" {
+		// Without a recognizable header the type applies to the first column
+		return 0, opts.Type, nil
+	}
+	return 0, "", validation.Errorf("column", "is required, since no column header names an organization number, mobile number, plate or VIN")
+}
+
+// resolveFields returns the selected fields, or the defaults for itemType
+func resolveFields(names []string, itemType bisnode.BatchItemType) ([]field, error) {
+	if len(names) == 0 {
+		names = defaultFields[isVehicle(itemType)]
+	}
+
+	selected := make([]field, 0, len(names))
+	for _, name := range names {
+		f, ok := lookupField(strings.ToLower(strings.TrimSpace(name)))
+		if !ok {
+			return nil, validation.Errorf("fields", "unknown field %q, must be one of %s", name, fieldNames())
+		}
+		if f.vehicle != isVehicle(itemType) {
+			return nil, validation.Errorf("fields", "field %q is not available for %s lookups", name, itemType)
+		}
+		selected = append(selected, f)
+	}
+	return selected, nil
+}
+
+// rowError describes why a row was not enriched, or returns "" if it was
+func rowError(o bisnode.BatchOutcome) string {
+	if o.Err != nil {
+		if ve, ok := validation.As(o.Err); ok && len(ve.Fields) > 0 {
+			return fmt.Sprintf("invalid %s: %s", o.Item.Type, ve.Fields[0].Message)
+		}
+		return o.Err.Error()
+	}
+	if !o.Found() {
+		return "not found"
+	}
+	return ""
+}
@@ -0,0 +1,237 @@
+package enrich
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/models"
+	"bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// stubLookup answers lookups from fixed directory and vehicle results by value
+func stubLookup(ctx context.Context, item bisnode.BatchItem) bisnode.BatchOutcome {
+	o := bisnode.BatchOutcome{Item: item}
+	switch item.Value {
+	case "923609016":
+		o.Directory = directoryResponse(
+			models.DirectoryResult{Type: "company", LastName: "Eksempel AS", StreetName: "Storgata", HouseNo: "1", ZipCode: "0155", City: "OSLO"},
+		)
+	case "91234567":
+		o.Directory = directoryResponse(
+			models.DirectoryResult{Type: "person", FirstName: "Ola", LastName: "Nordmann", City: "OSLO", Mobile: "91234567"},
+		)
+	case "AB12345":
+		var vehicle models.MotorVehicle
+		vehicle.BrandName = "VOLVO"
+		vehicle.Model = "V70"
+		o.Vehicle = &bisnode.VehicleResponse{}
+		o.Vehicle.Result = []models.MotorVehicle{vehicle}
+	case "=cmd":
+		o.Directory = directoryResponse(
+			models.DirectoryResult{Type: "company", LastName: "=HYPERLINK(\"http://example.com\")", City: "+OSLO"},
+		)
+	case "down":
+		o.Err = errors.New("bisnode unavailable")
+	case "invalid":
+		o.Err = validation.Errorf("organizationNumber", "must have 9 digits")
+	default:
+		o.Directory = &bisnode.DirectoryResponse{}
+	}
+	return o
+}
+
+// directoryResponse wraps directory results in a lookup response
+func directoryResponse(results ...models.DirectoryResult) *bisnode.DirectoryResponse {
+	resp := &bisnode.DirectoryResponse{}
+	resp.Result = results
+	return resp
+}
+
+// enrich runs an Enricher over input and returns the output
+func enrich(t *testing.T, input string, opts Options) (string, Summary, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	summary, err := New(stubLookup, 2, 0).Enrich(context.Background(), strings.NewReader(input), &out, opts)
+	return out.String(), summary, err
+}
+
+func TestEnrich(t *testing.T) {
+	input := "Customer,Org No\n" +
+		"1,923609016\n" +
+		"2,000000000\n" +
+		"3\n" +
+		"4,down\n" +
+		"5,invalid\n"
+
+	got, summary, err := enrich(t, input, Options{})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	want := "Customer,Org No,name,address,zip_code,city,error\n" +
+		"1,923609016,Eksempel AS,Storgata 1,0155,OSLO,\n" +
+		"2,000000000,,,,,not found\n" +
+		"3,,,,,,invalid orgno: is empty\n" +
+		"4,down,,,,,bisnode unavailable\n" +
+		"5,invalid,,,,,invalid orgno: must have 9 digits\n"
+	if got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+	if summary != (Summary{Rows: 5, Enriched: 1, NotFound: 1, Failed: 3}) {
+		t.Errorf("summary = %+v", summary)
+	}
+}
+
+func TestEnrichColumnSelection(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		opts   Options
+		want   string
+		field  string
+		errMsg string
+	}{
+		{
+			name:  "guessed from header",
+			input: "Name,Mobil\nOla,91234567\n",
+			want:  "Name,Mobil,name,address,zip_code,city,error\nOla,91234567,Ola Nordmann,,,OSLO,\n",
+		},
+		{
+			name:  "header with spaces and case",
+			input: "License Plate\nAB12345\n",
+			want:  "License Plate,brand,model,owner,next_inspection,error\nAB12345,VOLVO,V70,,,\n",
+		},
+		{
+			name:  "named column",
+			input: "Org No,Mobile\n923609016,91234567\n",
+			opts:  Options{Column: "mobile"},
+			want:  "Org No,Mobile,name,address,zip_code,city,error\n923609016,91234567,Ola Nordmann,,,OSLO,\n",
+		},
+		{
+			name:  "type for unrecognized header",
+			input: "Id,Number\n1,91234567\n",
+			opts:  Options{Column: "Number", Type: bisnode.BatchMobileNumber, Fields: []string{"name", "mobile"}},
+			want:  "Id,Number,name,mobile,error\n1,91234567,Ola Nordmann,91234567,\n",
+		},
+		{
+			name:  "type without header uses first column",
+			input: "a\n91234567\n",
+			opts:  Options{Type: bisnode.BatchMobileNumber, Fields: []string{"name"}},
+			want:  "a,name,error\n91234567,Ola Nordmann,\n",
+		},
+		{
+			name:  "unknown column",
+			input: "Org No\n923609016\n",
+			opts:  Options{Column: "vin"},
+			field: "column",
+		},
+		{
+			name:  "unrecognized header",
+			input: "Id\n923609016\n",
+			field: "column",
+		},
+		{
+			name:  "named column without type",
+			input: "Id\n923609016\n",
+			opts:  Options{Column: "Id"},
+			field: "type",
+		},
+		{
+			name:  "unknown type",
+			input: "Id\n923609016\n",
+			opts:  Options{Type: "email"},
+			field: "type",
+		},
+		{
+			name:  "unknown field",
+			input: "orgno\n923609016\n",
+			opts:  Options{Fields: []string{"name", "revenue"}},
+			field: "fields",
+		},
+		{
+			name:  "field of the other lookup",
+			input: "orgno\n923609016\n",
+			opts:  Options{Fields: []string{"brand"}},
+			field: "fields",
+		},
+		{
+			name:   "empty file",
+			input:  "",
+			errMsg: "CSV file is empty",
+		},
+		{
+			name:   "malformed file",
+			input:  "orgno\n\"923609016\n",
+			errMsg: "Malformed CSV",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := enrich(t, tt.input, tt.opts)
+			switch {
+			case tt.field != "":
+				ve, ok := validation.As(err)
+				if !ok || ve.Fields[0].Field != tt.field {
+					t.Errorf("Enrich() error = %v, want a validation error for %s", err, tt.field)
+				}
+			case tt.errMsg != "":
+				var de *binding.DecodeError
+				if !errors.As(err, &de) || de.Message != tt.errMsg {
+					t.Errorf("Enrich() error = %v, want %q", err, tt.errMsg)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Enrich() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+				}
+			}
+			if (tt.field != "" || tt.errMsg != "") && got != "" {
+				t.Errorf("output = %q, want nothing written for a rejected file", got)
+			}
+		})
+	}
+}
+
+func TestEnrichShortRows(t *testing.T) {
+	input := "orgno,Customer,Note\n923609016\n"
+
+	got, _, err := enrich(t, input, Options{Fields: []string{"name"}})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	want := "orgno,Customer,Note,name,error\n923609016,,,Eksempel AS,\n"
+	if got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEnrichMaxRows(t *testing.T) {
+	input := "orgno\n923609016\n923609016\n"
+
+	_, err := New(stubLookup, 1, 1).Enrich(context.Background(), strings.NewReader(input), &bytes.Buffer{}, Options{})
+	if ve, ok := validation.As(err); !ok || ve.Fields[0].Field != "file" {
+		t.Errorf("Enrich() error = %v, want a validation error for file", err)
+	}
+}
+
+func TestEnrichEscapesFormulas(t *testing.T) {
+	input := "orgno,Formula\n=cmd,=1+1\n"
+
+	got, _, err := enrich(t, input, Options{Fields: []string{"name", "city"}})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	want := "orgno,Formula,name,city,error\n" +
+		`=cmd,=1+1,"'=HYPERLINK(""http://example.com"")",'+OSLO,` + "\n"
+	if got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
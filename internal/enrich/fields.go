@@ -0,0 +1,118 @@
+package enrich
+
+import (
+	"bisnode/internal/domain"
+	"bisnode/internal/models"
+	"bisnode/internal/services/bisnode"
+	"strings"
+)
+
+// field is a column that can be appended to a row
+type field struct {
+	name string
+	// vehicle tells whether the field is taken from a motor vehicle rather
+	// than a directory listing
+	vehicle bool
+	listing func(l domain.Listing) string
+	motor   func(v models.MotorVehicle) string
+}
+
+// fields are the columns that can be selected, in the order they are listed in errors
+var fields = []field{
+	{name: "name", listing: func(l domain.Listing) string { return l.Name }},
+	{name: "organization_number", listing: func(l domain.Listing) string { return l.OrganizationNumber }},
+	{name: "address", listing: func(l domain.Listing) string { return l.Address.Line() }},
+	{name: "zip_code", listing: func(l domain.Listing) string { return l.Address.ZipCode }},
+	{name: "city", listing: func(l domain.Listing) string { return l.Address.City }},
+	{name: "phone", listing: func(l domain.Listing) string { return l.Telephone }},
+	{name: "mobile", listing: func(l domain.Listing) string { return l.Mobile }},
+	{name: "brand", vehicle: true, motor: func(v models.MotorVehicle) string { return v.BrandName }},
+	{name: "model", vehicle: true, motor: func(v models.MotorVehicle) string { return v.Model }},
+	{name: "model_year", vehicle: true, motor: func(v models.MotorVehicle) string { return v.ModelYear }},
+	{name: "color", vehicle: true, motor: func(v models.MotorVehicle) string { return v.ColorText }},
+	{name: "owner", vehicle: true, motor: func(v models.MotorVehicle) string { return v.Owner.Name }},
+	{name: "owner_organization_number", vehicle: true, motor: func(v models.MotorVehicle) string { return v.Owner.OrganizationNumber }},
+	{name: "last_inspection", vehicle: true, motor: func(v models.MotorVehicle) string { return v.LastInspectionDate }},
+	{name: "next_inspection", vehicle: true, motor: func(v models.MotorVehicle) string { return v.NextInspectionDate }},
+}
+
+// defaultFields are appended when no fields are selected
+var defaultFields = map[bool][]string{
+	false: {"name", "address", "zip_code", "city"},
+	true:  {"brand", "model", "owner", "next_inspection"},
+}
+
+// fieldNames lists every selectable field
+func fieldNames() string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// lookupField returns the field called name
+func lookupField(name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// value extracts the field from the first match of a lookup. Fields for the
+// other kind of lookup are left empty.
+func (f field) value(o bisnode.BatchOutcome) string {
+	switch {
+	case f.vehicle && o.Vehicle != nil && len(o.Vehicle.Result) > 0:
+		return strings.TrimSpace(f.motor(o.Vehicle.Result[0]))
+	case !f.vehicle && o.Directory != nil && len(o.Directory.Result) > 0:
+		return strings.TrimSpace(f.listing(domain.NewListing(o.Directory.Result[0])))
+	}
+	return ""
+}
+
+// isVehicle reports whether lookups of type t return motor vehicles
+func isVehicle(t bisnode.BatchItemType) bool {
+	return t == bisnode.BatchLicensePlate || t == bisnode.BatchVIN
+}
+
+// columnTypes maps common spreadsheet headers to the lookup they hold
+var columnTypes = map[string]bisnode.BatchItemType{
+	"orgno":               bisnode.BatchOrganizationNumber,
+	"orgnr":               bisnode.BatchOrganizationNumber,
+	"organizationnumber":  bisnode.BatchOrganizationNumber,
+	"organisationsnummer": bisnode.BatchOrganizationNumber,
+	"organisasjonsnummer": bisnode.BatchOrganizationNumber,
+	"mobile":              bisnode.BatchMobileNumber,
+	"mobilenumber":        bisnode.BatchMobileNumber,
+	"mobil":               bisnode.BatchMobileNumber,
+	"phone":               bisnode.BatchMobileNumber,
+	"phonenumber":         bisnode.BatchMobileNumber,
+	"telefon":             bisnode.BatchMobileNumber,
+	"plate":               bisnode.BatchLicensePlate,
+	"licenseplate":        bisnode.BatchLicensePlate,
+	"licensenumber":       bisnode.BatchLicensePlate,
+	"regno":               bisnode.BatchLicensePlate,
+	"regnr":               bisnode.BatchLicensePlate,
+	"registreringsnummer": bisnode.BatchLicensePlate,
+	"vin":                 bisnode.BatchVIN,
+	"chassisno":           bisnode.BatchVIN,
+	"chassisnummer":       bisnode.BatchVIN,
+}
+
+// columnType guesses the lookup held by a column from its header, ignoring
+// case, spaces, dashes and underscores
+func columnType(header string) (bisnode.BatchItemType, bool) {
+	key := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(header)))
+
+	t, ok := columnTypes[key]
+	return t, ok
+}
@@ -0,0 +1,76 @@
+// Package geo provides distance calculations and a zip code index for
+// searching the directory by location.
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// earthRadiusMeters is the mean radius of the Earth
+const earthRadiusMeters = 6371008.8
+
+// ErrInvalidCoordinate is returned for latitudes or longitudes outside their valid range
+var ErrInvalidCoordinate = errors.New("coordinate out of range")
+
+// Point is a WGS84 coordinate in decimal degrees
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Valid reports whether the point has a valid latitude and longitude
+func (p Point) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// IsZero reports whether the point is unset. Bisnode returns 0,0 for listings
+// without coordinates, which is far outside Norway.
+func (p Point) IsZero() bool {
+	return p.Latitude == 0 && p.Longitude == 0
+}
+
+// Distance returns the great-circle distance in meters between two points
+// using the haversine formula
+func Distance(a, b Point) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox is an area bounded by two latitudes and two longitudes
+type BoundingBox struct {
+	Min Point `json:"min"`
+	Max Point `json:"max"`
+}
+
+// Valid reports whether both corners are valid and Min is south-west of Max
+func (b BoundingBox) Valid() bool {
+	return b.Min.Valid() && b.Max.Valid() &&
+		b.Min.Latitude <= b.Max.Latitude && b.Min.Longitude <= b.Max.Longitude
+}
+
+// Contains reports whether p lies inside the box
+func (b BoundingBox) Contains(p Point) bool {
+	return p.Latitude >= b.Min.Latitude && p.Latitude <= b.Max.Latitude &&
+		p.Longitude >= b.Min.Longitude && p.Longitude <= b.Max.Longitude
+}
+
+// Center returns the midpoint of the box
+func (b BoundingBox) Center() Point {
+	return Point{
+		Latitude:  (b.Min.Latitude + b.Max.Latitude) / 2,
+		Longitude: (b.Min.Longitude + b.Max.Longitude) / 2,
+	}
+}
+
+// Radius returns the distance from the center of the box to its corners
+func (b BoundingBox) Radius() float64 {
+	return Distance(b.Center(), b.Max)
+}
@@ -0,0 +1,75 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+var (
+	oslo   = Point{Latitude: 59.9139, Longitude: 10.7522}
+	bergen = Point{Latitude: 60.3913, Longitude: 5.3221}
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64
+	}{
+		{name: "same point", a: oslo, b: oslo, want: 0},
+		{name: "Oslo to Bergen", a: oslo, b: bergen, want: 305_000},
+		{name: "one degree of latitude", a: Point{Latitude: 60}, b: Point{Latitude: 61}, want: 111_195},
+		{name: "antipodes", a: Point{Latitude: 0, Longitude: 0}, b: Point{Latitude: 0, Longitude: 180}, want: math.Pi * earthRadiusMeters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distance(tt.a, tt.b)
+			// Within 0.5 %, since the Earth is not a sphere anyway
+			if math.Abs(got-tt.want) > tt.want*0.005 {
+				t.Errorf("Distance() = %.0f, want about %.0f", got, tt.want)
+			}
+			if back := Distance(tt.b, tt.a); math.Abs(back-got) > 1e-6 {
+				t.Errorf("Distance() is %.3f one way and %.3f the other", got, back)
+			}
+		})
+	}
+}
+
+func TestPointValid(t *testing.T) {
+	tests := []struct {
+		p    Point
+		want bool
+	}{
+		{oslo, true},
+		{Point{Latitude: -90, Longitude: 180}, true},
+		{Point{Latitude: 90.1, Longitude: 10}, false},
+		{Point{Latitude: 59, Longitude: -180.5}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.p.Valid(); got != tt.want {
+			t.Errorf("%+v.Valid() = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	box := BoundingBox{Min: Point{Latitude: 59.90, Longitude: 10.70}, Max: Point{Latitude: 59.92, Longitude: 10.80}}
+
+	if !box.Valid() {
+		t.Error("Valid() = false, want true")
+	}
+	if swapped := (BoundingBox{Min: box.Max, Max: box.Min}); swapped.Valid() {
+		t.Error("Valid() = true with min north-east of max, want false")
+	}
+	if !box.Contains(oslo) || box.Contains(bergen) {
+		t.Errorf("Contains() = %v for Oslo and %v for Bergen, want only Oslo", box.Contains(oslo), box.Contains(bergen))
+	}
+	if c := box.Center(); math.Abs(c.Latitude-59.91) > 1e-9 || math.Abs(c.Longitude-10.75) > 1e-9 {
+		t.Errorf("Center() = %+v, want 59.91, 10.75", c)
+	}
+	if r := box.Radius(); math.Abs(r-Distance(box.Center(), box.Min)) > 1 {
+		t.Errorf("Radius() = %.0f, want the distance to either corner", r)
+	}
+}
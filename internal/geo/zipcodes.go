@@ -0,0 +1,110 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ZipCode is a zip code area represented by its center point
+type ZipCode struct {
+	Code   string
+	City   string
+	Center Point
+}
+
+// ZipIndex finds zip code areas near a point
+type ZipIndex struct {
+	zipCodes []ZipCode
+}
+
+// NewZipIndex creates a ZipIndex from a list of zip codes
+func NewZipIndex(zipCodes []ZipCode) *ZipIndex {
+	return &ZipIndex{zipCodes: zipCodes}
+}
+
+// LoadZipIndex reads a zip code index from a CSV file with the columns
+// zipcode, city, latitude and longitude. A header row is skipped if present.
+func LoadZipIndex(path string) (*ZipIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadZipIndex(f)
+}
+
+// ReadZipIndex reads a zip code index in the format described by LoadZipIndex
+func ReadZipIndex(r io.Reader) (*ZipIndex, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var zipCodes []ZipCode
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zip codes: %w", err)
+		}
+
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		lon, lonErr := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+		if latErr != nil || lonErr != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("invalid coordinate on line %d", line)
+		}
+
+		zip := ZipCode{
+			Code:   strings.TrimSpace(record[0]),
+			City:   strings.TrimSpace(record[1]),
+			Center: Point{Latitude: lat, Longitude: lon},
+		}
+		if !zip.Center.Valid() {
+			return nil, fmt.Errorf("line %d: %w", line, ErrInvalidCoordinate)
+		}
+		zipCodes = append(zipCodes, zip)
+	}
+
+	return NewZipIndex(zipCodes), nil
+}
+
+// Len returns the number of zip codes in the index
+func (idx *ZipIndex) Len() int {
+	return len(idx.zipCodes)
+}
+
+// Nearby returns the zip codes whose center lies within radius meters of p,
+// nearest first
+func (idx *ZipIndex) Nearby(p Point, radius float64) []ZipCode {
+	type candidate struct {
+		zip      ZipCode
+		distance float64
+	}
+
+	var candidates []candidate
+	for _, zip := range idx.zipCodes {
+		if d := Distance(p, zip.Center); d <= radius {
+			candidates = append(candidates, candidate{zip: zip, distance: d})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	zipCodes := make([]ZipCode, len(candidates))
+	for i, c := range candidates {
+		zipCodes[i] = c.zip
+	}
+	return zipCodes
+}
@@ -0,0 +1,102 @@
+package geo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadZipIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []ZipCode
+		wantErr string
+	}{
+		{
+			name:  "with header",
+			input: "zipcode,city,latitude,longitude\n0150,OSLO,59.9111,10.7503\n",
+			want:  []ZipCode{{Code: "0150", City: "OSLO", Center: Point{Latitude: 59.9111, Longitude: 10.7503}}},
+		},
+		{
+			name:  "without header and with spaces",
+			input: "0150, OSLO, 59.9111, 10.7503\n5003,BERGEN,60.3940,5.3250\n",
+			want: []ZipCode{
+				{Code: "0150", City: "OSLO", Center: Point{Latitude: 59.9111, Longitude: 10.7503}},
+				{Code: "5003", City: "BERGEN", Center: Point{Latitude: 60.3940, Longitude: 5.3250}},
+			},
+		},
+		{
+			name:    "invalid coordinate after the first line",
+			input:   "0150,OSLO,59.9111,10.7503\n0151,OSLO,north,10.7442\n",
+			wantErr: "invalid coordinate on line 2",
+		},
+		{
+			name:    "coordinate out of range",
+			input:   "0150,OSLO,95.0,10.7503\n",
+			wantErr: "line 1: coordinate out of range",
+		},
+		{
+			name:    "missing column",
+			input:   "0150,OSLO,59.9111\n",
+			wantErr: "failed to read zip codes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, err := ReadZipIndex(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReadZipIndex() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadZipIndex() error = %v", err)
+			}
+			if !reflect.DeepEqual(idx.zipCodes, tt.want) {
+				t.Errorf("ReadZipIndex() = %+v, want %+v", idx.zipCodes, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadZipIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zipcodes.csv")
+	if err := os.WriteFile(path, []byte("0150,OSLO,59.9111,10.7503\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := LoadZipIndex(path)
+	if err != nil || idx.Len() != 1 {
+		t.Fatalf("LoadZipIndex() = %v, %v, want 1 zip code", idx, err)
+	}
+
+	if _, err := LoadZipIndex(filepath.Join(t.TempDir(), "missing.csv")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadZipIndex() of a missing file error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestZipIndexNearby(t *testing.T) {
+	idx := NewZipIndex([]ZipCode{
+		{Code: "0151", Center: Point{Latitude: 59.9102, Longitude: 10.7442}},
+		{Code: "5003", Center: bergen},
+		{Code: "0150", Center: Point{Latitude: 59.9111, Longitude: 10.7503}},
+	})
+
+	var got []string
+	for _, zip := range idx.Nearby(oslo, 2000) {
+		got = append(got, zip.Code)
+	}
+	if want := []string{"0150", "0151"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Nearby() = %v, want %v, nearest first", got, want)
+	}
+
+	if zips := idx.Nearby(oslo, 10); len(zips) != 0 {
+		t.Errorf("Nearby() within 10 meters = %+v, want none", zips)
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"bisnode/internal/services/bisnode"
+	"net"
+	"net/http"
+)
+
+// AuditCaller attributes the lookups made while serving a request to its
+// caller in the audit log: the remote address, and the basic auth user as an
+// unverified claim, since the API does not check credentials
+func AuditCaller(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller := bisnode.Caller{Address: r.RemoteAddr}
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			caller.Address = host
+		}
+		if user, _, ok := r.BasicAuth(); ok {
+			caller.ClaimedUser = user
+		}
+		next.ServeHTTP(w, r.WithContext(bisnode.WithCaller(r.Context(), caller)))
+	})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/domain"
+	"bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
+	"net/http"
+	"strings"
+)
+
+// BatchItemStatus is the outcome of a single batch item
+type BatchItemStatus string
+
+const (
+	BatchItemOK       BatchItemStatus = "ok"
+	BatchItemNotFound BatchItemStatus = "not_found"
+	BatchItemInvalid  BatchItemStatus = "invalid"
+	BatchItemError    BatchItemStatus = "error"
+)
+
+// BatchRequest represents the request body for a batch lookup
+type BatchRequest struct {
+	Items []BatchItemRequest `json:"items" validate:"required"`
+}
+
+// BatchItemRequest is a single lookup in a batch. Items are validated one by
+// one, so that an invalid item fails on its own without rejecting the batch.
+type BatchItemRequest struct {
+	// Type is orgno, mobile, plate or vin
+	Type  string `json:"type" example:"orgno"`
+	Value string `json:"value" example:"923609016"`
+}
+
+// item converts the request into a batch item
+func (r BatchItemRequest) item() bisnode.BatchItem {
+	return bisnode.BatchItem{
+		Type:  bisnode.BatchItemType(strings.ToLower(strings.TrimSpace(r.Type))),
+		Value: r.Value,
+	}
+}
+
+// BatchItemResult is the result of a single batch item. Result is a
+// domain.SearchResult for orgno and mobile items, and a motor vehicle search
+// response for plate and vin items.
+type BatchItemResult struct {
+	Index   int                     `json:"index"`
+	Type    string                  `json:"type" example:"orgno"`
+	Value   string                  `json:"value" example:"923609016"`
+	Status  BatchItemStatus         `json:"status"`
+	Result  interface{}             `json:"result,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+	Details []validation.FieldError `json:"details,omitempty"`
+}
+
+// BatchResponse is the response of a batch lookup, with the items in request order
+type BatchResponse struct {
+	Succeeded int               `json:"succeeded"`
+	NotFound  int               `json:"notFound"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
+
+// BatchHandler handles HTTP requests for batch lookups
+type BatchHandler struct {
+	service *bisnode.BatchService
+}
+
+// NewBatchHandler creates a new BatchHandler
+func NewBatchHandler(service *bisnode.BatchService) *BatchHandler {
+	return &BatchHandler{
+		service: service,
+	}
+}
+
+// Lookup handles the batch lookup request
+// @Summary Look up organizations, persons and vehicles in one request
+// @Description Look up a mixed list of organization numbers, mobile numbers, license plates and VINs. Items are looked up in parallel and returned in request order, each with its own status; one failing item does not fail the batch.
+// @Tags Batch
+// @Accept json
+// @Produce json
+// @Param request body BatchRequest true "Items to look up, at most the configured batch size (default 500)"
+// @Success 200 {object} BatchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Router /api/v1/batch [post]
+// @Security BasicAuth
+func (h *BatchHandler) Lookup(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := binding.Bind(r, &req); err != nil {
+		respondWithBindingError(w, err)
+		return
+	}
+
+	items := make([]bisnode.BatchItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = item.item()
+	}
+
+	outcomes, err := h.service.Lookup(r.Context(), items)
+	if err != nil {
+		respondWithBindingError(w, err)
+		return
+	}
+
+	response := BatchResponse{Items: make([]BatchItemResult, len(outcomes))}
+	for i, o := range outcomes {
+		result := newBatchItemResult(i, req.Items[i], o)
+		switch result.Status {
+		case BatchItemOK:
+			response.Succeeded++
+		case BatchItemNotFound:
+			response.NotFound++
+		default:
+			response.Failed++
+		}
+		response.Items[i] = result
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// newBatchItemResult maps the outcome of a batch item to its response
+func newBatchItemResult(index int, item BatchItemRequest, o bisnode.BatchOutcome) BatchItemResult {
+	result := BatchItemResult{Index: index, Type: item.Type, Value: item.Value}
+
+	if o.Err != nil {
+		if ve, ok := validation.As(o.Err); ok {
+			result.Status = BatchItemInvalid
+			result.Error = "Invalid item"
+			result.Details = ve.Fields
+			return result
+		}
+		result.Status = BatchItemError
+		result.Error = o.Err.Error()
+		return result
+	}
+
+	result.Status = BatchItemOK
+	if !o.Found() {
+		result.Status = BatchItemNotFound
+	}
+	if o.Directory != nil {
+		response := domain.NewSearchResult(&o.Directory.DirectorySearchResponse)
+		response.Freshness = o.Directory.Freshness
+		result.Result = response
+	} else if o.Vehicle != nil {
+		result.Result = newVehicleSearchResult(o.Vehicle)
+	}
+	return result
+}
@@ -1,58 +1,134 @@
 package handlers
 
 import (
+	"bisnode/internal/binding"
+	"bisnode/internal/domain"
 	"bisnode/internal/models"
 	"bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
-// SearchPersonRequest represents the request body for searching a person
+// SearchPersonRequest represents the request body for searching a person.
+// Either MobileNumber or one or more of the name and address fields is set.
 type SearchPersonRequest struct {
-	MobileNumber string `json:"mobileNumber"`
+	MobileNumber string `json:"mobileNumber,omitempty" query:"mobileNumber" validate:"max=20" example:"91234567"`
+	FirstName    string `json:"firstName,omitempty" query:"firstName" validate:"max=100" example:"Ola"`
+	LastName     string `json:"lastName,omitempty" query:"lastName" validate:"max=100" example:"Nordmann"`
+	Street       string `json:"street,omitempty" query:"street" validate:"max=100" example:"Storgata 1"`
+	ZipCode      string `json:"zipCode,omitempty" query:"zipCode" validate:"max=10" example:"0155"`
+	City         string `json:"city,omitempty" query:"city" validate:"max=100" example:"Oslo"`
+	SearchOptionsRequest
+	ContactChannelRequest
+}
+
+// hasNameOrAddress reports whether any of the name and address fields are set
+func (r *SearchPersonRequest) hasNameOrAddress() bool {
+	return strings.TrimSpace(r.FirstName+r.LastName+r.Street+r.ZipCode+r.City) != ""
+}
+
+// Validate checks that the request is either a mobile number or a name and address search
+func (r *SearchPersonRequest) Validate() error {
+	hasMobile := strings.TrimSpace(r.MobileNumber) != ""
+	switch {
+	case hasMobile && r.hasNameOrAddress():
+		return validation.Errorf("mobileNumber", "cannot be combined with name and address fields")
+	case !hasMobile && !r.hasNameOrAddress():
+		return validation.Errorf("mobileNumber", "is required when no name or address is given")
+	}
+	return nil
+}
+
+// SearchOptionsRequest holds the Bisnode search options a caller may set.
+// Options left out use the server's configured defaults.
+type SearchOptionsRequest struct {
+	SearchMode     string `json:"searchMode,omitempty" query:"searchMode" validate:"oneof=exact phonetic smart"`
+	ListingType    string `json:"listingType,omitempty" query:"listingType" validate:"oneof=all company person"`
+	OnlyFoundWords *bool  `json:"onlyFoundWords,omitempty" query:"onlyFoundWords"`
+	Limit          int    `json:"limit,omitempty" query:"limit" validate:"min=1" example:"10"`
+}
+
+// options converts the validated request into search options
+func (r SearchOptionsRequest) options() models.SearchOptions {
+	opts := models.SearchOptions{
+		ResultLimit:    r.Limit,
+		OnlyFoundWords: r.OnlyFoundWords,
+	}
+	if mode, err := models.ParseSearchMode(r.SearchMode); err == nil {
+		opts.SearchMode = mode
+	}
+	if listing, err := models.ParseListingType(r.ListingType); err == nil {
+		opts.ListingType = &listing
+	}
+	return opts
 }
 
 // SearchOrganizationRequest represents the request body for searching an organization
 type SearchOrganizationRequest struct {
-	OrganizationNumber string `json:"organizationNumber"`
+	// orgNo is accepted in the query string for backward compatibility
+	OrganizationNumber string `json:"organizationNumber" query:"organizationNumber,orgNo" validate:"required,max=20" example:"923609016"`
+}
+
+// SearchOrganizationsByNameRequest represents the request body for searching organizations by name
+type SearchOrganizationsByNameRequest struct {
+	Name    string `json:"name" query:"name" validate:"required,min=2,max=100" example:"Eksempel"`
+	City    string `json:"city,omitempty" query:"city" validate:"max=100" example:"Oslo"`
+	ZipCode string `json:"zipCode,omitempty" query:"zipCode" validate:"max=10" example:"0155"`
+	SearchOptionsRequest
 }
 
 // DirectoryHandler handles HTTP requests for directory search
 type DirectoryHandler struct {
-	service *bisnode.DirectoryService
+	persons       bisnode.PersonSearcher
+	organizations bisnode.OrganizationLookup
 }
 
 // NewDirectoryHandler creates a new DirectoryHandler
-func NewDirectoryHandler(service *bisnode.DirectoryService) *DirectoryHandler {
+func NewDirectoryHandler(persons bisnode.PersonSearcher, organizations bisnode.OrganizationLookup) *DirectoryHandler {
 	return &DirectoryHandler{
-		service: service,
+		persons:       persons,
+		organizations: organizations,
 	}
 }
 
-// SearchPerson handles the search request for a person by mobile number
-// @Summary Search for a person by mobile number
-// @Description Search for a person using their mobile number
+// SearchPerson handles the search request for a person by mobile number, or by name and address
+// @Summary Search for a person by mobile number or by name and address
+// @Description Search for a person using their mobile number, or using name and address fields. Name and address results are ranked by how well they match.
 // @Tags Directory
 // @Accept json
 // @Produce json
 // @Param mobileNumber query string false "Mobile number of the person"
-// @Success 200 {object} map[string]interface{}
+// @Param firstName query string false "First name of the person"
+// @Param lastName query string false "Last name of the person"
+// @Param street query string false "Street name, optionally followed by house number"
+// @Param zipCode query string false "Four digit zip code"
+// @Param city query string false "City"
+// @Param searchMode query string false "Search mode: exact, phonetic or smart (server default smart)"
+// @Param listingType query string false "Listing type: all, company or person (default person)"
+// @Param onlyFoundWords query bool false "Only return results containing every search word"
+// @Param limit query int false "Maximum number of results (server default 10, maximum 100)"
+// @Param channel query string false "Intended marketing channel: telemarketing, directMail or humanitarian"
+// @Param reservationPolicy query string false "What to do with listings reserved against the channel: flag (default) or suppress"
+// @Success 200 {object} domain.SearchResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/directory/persons/search [get]
 // @Security BasicAuth
 
-// SearchPerson handles the search request for a person by mobile number (POST)
-// @Summary Search for a person by mobile number (POST)
-// @Description Search for a person using their mobile number with JSON body
+// SearchPerson handles the search request for a person by mobile number, or by name and address (POST)
+// @Summary Search for a person by mobile number or by name and address (POST)
+// @Description Search for a person using their mobile number, or using name and address fields, with JSON body
 // @Tags Directory
 // @Accept json
 // @Produce json
 // @Param request body SearchPersonRequest true "Search parameters"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} domain.SearchResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -66,28 +142,36 @@ func (h *DirectoryHandler) SearchPerson(w http.ResponseWriter, r *http.Request)
 	log.Printf("Incoming request: %s %s", r.Method, r.URL.Path)
 	log.Printf("Headers: %v", r.Header)
 
-	if r.Method != http.MethodPost {
-		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
 	var req SearchPersonRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	if err := binding.Bind(r, &req); err != nil {
+		respondWithBindingError(w, err)
 		return
 	}
 
-	if req.MobileNumber == "" {
-		respondWithError(w, http.StatusBadRequest, "Mobile number is required")
-		return
+	var result *bisnode.DirectoryResponse
+	var err error
+	if req.hasNameOrAddress() {
+		result, err = h.persons.SearchPersons(r.Context(), models.PersonSearchQuery{
+			FirstName: req.FirstName,
+			LastName:  req.LastName,
+			Street:    req.Street,
+			ZipCode:   req.ZipCode,
+			City:      req.City,
+			Options:   req.options(),
+		})
+	} else {
+		result, err = h.persons.SearchByMobileNumber(r.Context(), req.MobileNumber, req.options())
 	}
-
-	result, err := h.service.SearchByMobileNumber(r.Context(), req.MobileNumber)
 	if err != nil {
+		// Invalid input is rejected by the service before calling Bisnode
+		if _, ok := validation.As(err); ok {
+			respondWithBindingError(w, err)
+			return
+		}
 		// Check if the error is due to no results found
 		if strings.Contains(err.Error(), "no results") {
-			respondWithJSON(w, http.StatusOK, &models.DirectorySearchResponse{
-				Result: []models.DirectoryResult{},
+			respondWithJSON(w, http.StatusOK, domain.SearchResult{
+				Result: []domain.Listing{},
 			})
 			return
 		}
@@ -95,7 +179,14 @@ func (h *DirectoryHandler) SearchPerson(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, result)
+	response := domain.NewSearchResult(&result.DirectorySearchResponse)
+	response.Freshness = result.Freshness
+	if channel, policy, ok := req.channel(); ok {
+		response.ApplyChannel(channel, policy)
+	}
+
+	setFreshnessHeaders(w, result.Freshness)
+	respondWithJSON(w, http.StatusOK, response)
 }
 
 // SearchOrganization handles the search request for an organization by organization number
@@ -105,7 +196,8 @@ func (h *DirectoryHandler) SearchPerson(w http.ResponseWriter, r *http.Request)
 // @Accept json
 // @Produce json
 // @Param organizationNumber query string false "Organization number"
-// @Success 200 {object} map[string]interface{}
+// @Param orgNo query string false "Organization number (deprecated alias of organizationNumber)"
+// @Success 200 {object} domain.SearchResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -114,12 +206,14 @@ func (h *DirectoryHandler) SearchPerson(w http.ResponseWriter, r *http.Request)
 
 // SearchOrganization handles the search request for an organization by organization number (POST)
 // @Summary Search for an organization by organization number (POST)
-// @Description Search for an organization using its organization number with JSON body
+// @Description Search for an organization using its organization number with JSON body. The organization number may also be given in the query string, which fills it when the body leaves it empty.
 // @Tags Directory
 // @Accept json
 // @Produce json
-// @Param request body SearchOrganizationRequest true "Search parameters"
-// @Success 200 {object} map[string]interface{}
+// @Param request body SearchOrganizationRequest false "Search parameters; may be omitted when the query string is given"
+// @Param organizationNumber query string false "Organization number"
+// @Param orgNo query string false "Organization number (deprecated alias of organizationNumber)"
+// @Success 200 {object} domain.SearchResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -134,41 +228,175 @@ func (h *DirectoryHandler) SearchOrganization(w http.ResponseWriter, r *http.Req
 	log.Printf("Headers: %v", r.Header)
 
 	var req SearchOrganizationRequest
-	// Try to get the organization number from query parameters first (for backward compatibility)
-	orgNo := r.URL.Query().Get("orgNo")
-	if orgNo == "" {
-		// If not in query params, try to parse from JSON body
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	if err := binding.Bind(r, &req); err != nil {
+		respondWithBindingError(w, err)
+		return
+	}
+
+	result, err := h.organizations.SearchByOrganizationNumber(r.Context(), req.OrganizationNumber)
+	if err != nil {
+		// Invalid input is rejected by the service before calling Bisnode
+		if _, ok := validation.As(err); ok {
+			respondWithBindingError(w, err)
 			return
 		}
-		orgNo = req.OrganizationNumber
+		// Check if the error is due to no results found
+		if strings.Contains(err.Error(), "no results") {
+			respondWithJSON(w, http.StatusOK, domain.SearchResult{
+				Result: []domain.Listing{},
+			})
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := domain.NewSearchResult(&result.DirectorySearchResponse)
+	response.Freshness = result.Freshness
+
+	setFreshnessHeaders(w, result.Freshness)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// SearchOrganizationsByName handles the search request for organizations by name
+// @Summary Search for organizations by name
+// @Description Search for companies by name, optionally filtered by city and zip code. Returns a summary of each match whose organization number can be used with the organization search.
+// @Tags Directory
+// @Accept json
+// @Produce json
+// @Param name query string true "Name of the organization"
+// @Param city query string false "City"
+// @Param zipCode query string false "Four digit zip code"
+// @Param searchMode query string false "Search mode: exact, phonetic or smart (server default smart)"
+// @Param onlyFoundWords query bool false "Only return results containing every search word"
+// @Param limit query int false "Maximum number of results (server default 10, maximum 100)"
+// @Success 200 {object} domain.OrganizationSearchResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/directory/organizations/search-by-name [get]
+// @Security BasicAuth
+
+// SearchOrganizationsByName handles the search request for organizations by name (POST)
+// @Summary Search for organizations by name (POST)
+// @Description Search for companies by name, optionally filtered by city and zip code, with JSON body
+// @Tags Directory
+// @Accept json
+// @Produce json
+// @Param request body SearchOrganizationsByNameRequest true "Search parameters"
+// @Success 200 {object} domain.OrganizationSearchResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/directory/organizations/search-by-name [post]
+// @Security BasicAuth
+func (h *DirectoryHandler) SearchOrganizationsByName(w http.ResponseWriter, r *http.Request) {
+	var req SearchOrganizationsByNameRequest
+	if err := binding.Bind(r, &req); err != nil {
+		respondWithBindingError(w, err)
+		return
 	}
 
-	if orgNo == "" {
-		respondWithError(w, http.StatusBadRequest, "Organization number is required")
+	result, err := h.organizations.SearchOrganizationsByName(r.Context(), models.OrganizationSearchQuery{
+		Name:    req.Name,
+		City:    req.City,
+		ZipCode: req.ZipCode,
+		Options: req.options(),
+	})
+	if err != nil {
+		if _, ok := validation.As(err); ok {
+			respondWithBindingError(w, err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	result, err := h.service.SearchByOrganizationNumber(r.Context(), orgNo)
+	setFreshnessHeaders(w, result.Freshness)
+	respondWithJSON(w, http.StatusOK, domain.OrganizationSearchResult{
+		Result:    result.Result,
+		Freshness: result.Freshness,
+	})
+}
+
+// PersonHistory handles the address and phone history request for a person identified by mobile number
+// @Summary Get the address and phone history of a person
+// @Description Look up a person by mobile number and return their addresses and phone numbers merged, deduplicated and ordered into a timeline, with the current entries flagged and a confidence score based on source quality
+// @Tags Directory
+// @Produce json
+// @Param mobileNumber path string true "Mobile number of the person"
+// @Success 200 {object} domain.HistoryResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/directory/persons/{mobileNumber}/history [get]
+// @Security BasicAuth
+func (h *DirectoryHandler) PersonHistory(w http.ResponseWriter, r *http.Request) {
+	result, err := h.persons.SearchByMobileNumber(r.Context(), r.PathValue("mobileNumber"), models.SearchOptions{})
 	if err != nil {
-		// Check if the error is due to no results found
+		if _, ok := validation.As(err); ok {
+			respondWithBindingError(w, err)
+			return
+		}
 		if strings.Contains(err.Error(), "no results") {
-			respondWithJSON(w, http.StatusOK, &models.DirectorySearchResponse{
-				Result: []models.DirectoryResult{},
-			})
+			respondWithError(w, http.StatusNotFound, "No person found for mobile number")
 			return
 		}
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if len(result.Result) == 0 {
+		respondWithError(w, http.StatusNotFound, "No person found for mobile number")
+		return
+	}
+
+	response := domain.NewHistoryResult(&result.DirectorySearchResponse)
+	response.Freshness = result.Freshness
+
+	setFreshnessHeaders(w, result.Freshness)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// setFreshnessHeaders marks responses served from cache with their age, and
+// stale responses with a Warning header
+func setFreshnessHeaders(w http.ResponseWriter, f *domain.Freshness) {
+	if f == nil {
+		return
+	}
+
+	w.Header().Set("X-Data-Age", strconv.Itoa(f.AgeSeconds))
 
-	respondWithJSON(w, http.StatusOK, result)
+	switch f.Status {
+	case "stale":
+		w.Header().Set("Warning", `110 - "Response is Stale"`)
+	case "stale-if-error":
+		w.Header().Set("Warning", `111 - "Revalidation Failed"`)
+	}
 }
 
 // respondWithError sends an error response
 func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
+	respondWithJSON(w, code, ErrorResponse{Error: message})
+}
+
+// respondWithBindingError sends the error response for a request that could
+// not be bound, including field-level details for validation errors
+func respondWithBindingError(w http.ResponseWriter, err error) {
+	if ve, ok := validation.As(err); ok {
+		respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request parameters",
+			Details: ve.Fields,
+		})
+		return
+	}
+
+	if errors.Is(err, binding.ErrBodyTooLarge) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+		return
+	}
+
+	respondWithError(w, http.StatusBadRequest, err.Error())
 }
 
 // respondWithJSON sends a JSON response
@@ -0,0 +1,350 @@
+package handlers_test
+
+import (
+	"bisnode/docs"
+	"bisnode/internal/bisnodefake"
+	"bisnode/internal/config"
+	"bisnode/internal/domain"
+	"bisnode/internal/handlers"
+	"bisnode/internal/openapi"
+	"bisnode/internal/routes"
+	bisnodeservice "bisnode/internal/services/bisnode"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestAPI serves the directory and motor vehicle routes backed by a fake
+// Bisnode API. Requests and responses that do not match the OpenAPI document
+// fail the test.
+func newTestAPI(t *testing.T) (*bisnodefake.Server, http.Handler) {
+	t.Helper()
+
+	fake := bisnodefake.New(bisnodefake.Options{})
+	upstream := httptest.NewServer(fake)
+	t.Cleanup(upstream.Close)
+
+	cfg := &config.BisnodeConfig{BaseURL: upstream.URL}
+	directoryService := bisnodeservice.NewDirectoryService(bisnodeservice.NewDirectoryClient(cfg), nil)
+	motorVehicleService := bisnodeservice.NewMotorVehicleService(bisnodeservice.NewMotorVehicleClient(cfg))
+
+	mux := http.NewServeMux()
+	routes.RegisterDirectoryRoutes(mux, handlers.NewDirectoryHandler(directoryService, directoryService))
+	routes.RegisterMotorVehicleRoutes(mux, handlers.NewMotorVehicleHandler(motorVehicleService))
+
+	doc, err := openapi.Parse(docs.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := openapi.ValidateContract(mux, doc, openapi.ContractOptions{
+		Report: func(m openapi.Mismatch) {
+			t.Errorf("contract mismatch: %s", m)
+		},
+	})
+
+	return fake, api
+}
+
+// serve sends a request to the API; a non-empty body is sent as JSON
+func serve(api http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode decodes a JSON response body into v
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type = %q, want application/json; body: %s", ct, rec.Body)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("invalid JSON response: %v; body: %s", err, rec.Body)
+	}
+}
+
+// wantValidationError checks for a 400 response with details for field
+func wantValidationError(t *testing.T, rec *httptest.ResponseRecorder, field string) {
+	t.Helper()
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body: %s", rec.Code, rec.Body)
+	}
+	var resp handlers.ErrorResponse
+	decode(t, rec, &resp)
+	for _, d := range resp.Details {
+		if d.Field == field {
+			return
+		}
+	}
+	t.Errorf("details = %+v, want an error for %s", resp.Details, field)
+}
+
+func TestSearchPerson(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{name: "get by mobile", method: http.MethodGet, target: "/api/v1/directory/persons/search?mobileNumber=91234567"},
+		{name: "get by formatted mobile", method: http.MethodGet, target: "/api/v1/directory/persons/search?mobileNumber=%2B47+912+34+567"},
+		{name: "post by mobile", method: http.MethodPost, target: "/api/v1/directory/persons/search", body: `{"mobileNumber": "91234567"}`},
+		{name: "get by name", method: http.MethodGet, target: "/api/v1/directory/persons/search?firstName=Ola&lastName=Nordmann"},
+		{name: "post by name and city", method: http.MethodPost, target: "/api/v1/directory/persons/search", body: `{"firstName": "Ola", "city": "Oslo", "onlyFoundWords": true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, api := newTestAPI(t)
+
+			rec := serve(api, tt.method, tt.target, tt.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body)
+			}
+			var resp domain.SearchResult
+			decode(t, rec, &resp)
+			if len(resp.Result) == 0 || resp.Result[0].Name != "Ola Nordmann" {
+				t.Errorf("result = %+v, want Ola Nordmann first", resp.Result)
+			}
+		})
+	}
+}
+
+func TestSearchPersonValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		field  string
+	}{
+		{name: "nothing to search for", method: http.MethodGet, target: "/api/v1/directory/persons/search", field: "mobileNumber"},
+		{name: "mobile and name", method: http.MethodGet, target: "/api/v1/directory/persons/search?mobileNumber=91234567&firstName=Ola", field: "mobileNumber"},
+		{name: "landline", method: http.MethodGet, target: "/api/v1/directory/persons/search?mobileNumber=22123456", field: "mobileNumber"},
+		{name: "unknown search mode", method: http.MethodGet, target: "/api/v1/directory/persons/search?mobileNumber=91234567&searchMode=fuzzy", field: "searchMode"},
+		{name: "search mode in capitals", method: http.MethodGet, target: "/api/v1/directory/persons/search?mobileNumber=91234567&searchMode=SMART", field: "searchMode"},
+		{name: "numeric listing type", method: http.MethodPost, target: "/api/v1/directory/persons/search", body: `{"mobileNumber": "91234567", "listingType": "2"}`, field: "listingType"},
+		{name: "limit too large", method: http.MethodPost, target: "/api/v1/directory/persons/search", body: `{"mobileNumber": "91234567", "limit": 1000}`, field: "limit"},
+		{name: "unknown channel", method: http.MethodGet, target: "/api/v1/directory/persons/search?mobileNumber=91234567&channel=fax", field: "channel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, api := newTestAPI(t)
+
+			wantValidationError(t, serve(api, tt.method, tt.target, tt.body), tt.field)
+			if n := len(fake.Requests()); n != 0 {
+				t.Errorf("Bisnode received %d requests for an invalid request, want 0", n)
+			}
+		})
+	}
+}
+
+func TestSearchPersonMalformedBody(t *testing.T) {
+	_, api := newTestAPI(t)
+
+	rec := serve(api, http.MethodPost, "/api/v1/directory/persons/search", `{"mobileNumber": `)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body: %s", rec.Code, rec.Body)
+	}
+	var resp handlers.ErrorResponse
+	decode(t, rec, &resp)
+	if resp.Error == "" {
+		t.Error("error message is empty")
+	}
+}
+
+func TestSearchPersonChannel(t *testing.T) {
+	_, api := newTestAPI(t)
+
+	// The fixture person is reserved against telemarketing
+	rec := serve(api, http.MethodGet, "/api/v1/directory/persons/search?mobileNumber=91234567&channel=telemarketing&reservationPolicy=suppress", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body)
+	}
+	var resp domain.SearchResult
+	decode(t, rec, &resp)
+	if len(resp.Result) != 0 || resp.Suppressed != 1 || resp.Channel != domain.ChannelTelemarketing {
+		t.Errorf("response = %+v, want the reserved listing suppressed", resp)
+	}
+
+	rec = serve(api, http.MethodGet, "/api/v1/directory/persons/search?mobileNumber=91234567&channel=directMail", "")
+	decode(t, rec, &resp)
+	if len(resp.Result) != 1 || resp.Result[0].Contactable == nil || !*resp.Result[0].Contactable {
+		t.Errorf("result = %+v, want the listing flagged contactable for direct mail", resp.Result)
+	}
+}
+
+func TestSearchPersonUpstreamError(t *testing.T) {
+	fake, api := newTestAPI(t)
+	fake.Fail(bisnodefake.Fault{Status: http.StatusServiceUnavailable})
+
+	rec := serve(api, http.MethodGet, "/api/v1/directory/persons/search?mobileNumber=91234567", "")
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500; body: %s", rec.Code, rec.Body)
+	}
+	var resp handlers.ErrorResponse
+	decode(t, rec, &resp)
+	if !strings.Contains(resp.Error, "503") {
+		t.Errorf("error = %q, want it to mention the upstream status", resp.Error)
+	}
+}
+
+func TestSearchOrganization(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{name: "get", method: http.MethodGet, target: "/api/v1/directory/organizations/search?organizationNumber=923609016"},
+		{name: "get with deprecated alias", method: http.MethodGet, target: "/api/v1/directory/organizations/search?orgNo=923+609+016"},
+		{name: "post", method: http.MethodPost, target: "/api/v1/directory/organizations/search", body: `{"organizationNumber": "NO 923 609 016 MVA"}`},
+		{name: "post with deprecated alias", method: http.MethodPost, target: "/api/v1/directory/organizations/search?orgNo=923609016"},
+		{name: "body wins over query", method: http.MethodPost, target: "/api/v1/directory/organizations/search?orgNo=1", body: `{"organizationNumber": "923609016"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, api := newTestAPI(t)
+
+			rec := serve(api, tt.method, tt.target, tt.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body)
+			}
+			var resp domain.SearchResult
+			decode(t, rec, &resp)
+			if len(resp.Result) != 1 || resp.Result[0].OrganizationNumber != "923609016" || resp.Result[0].Name != "Eksempel AS" {
+				t.Errorf("result = %+v, want Eksempel AS", resp.Result)
+			}
+		})
+	}
+}
+
+func TestSearchOrganizationValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{name: "missing", target: "/api/v1/directory/organizations/search"},
+		{name: "wrong check digit", target: "/api/v1/directory/organizations/search?organizationNumber=923609017"},
+		{name: "letters", target: "/api/v1/directory/organizations/search?organizationNumber=ABC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, api := newTestAPI(t)
+			wantValidationError(t, serve(api, http.MethodGet, tt.target, ""), "organizationNumber")
+		})
+	}
+}
+
+func TestSearchOrganizationsByName(t *testing.T) {
+	_, api := newTestAPI(t)
+
+	rec := serve(api, http.MethodGet, "/api/v1/directory/organizations/search-by-name?name=eksempel", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Result []struct {
+			OrganizationNumber string `json:"organizationNumber"`
+			Name               string `json:"name"`
+		} `json:"result"`
+	}
+	decode(t, rec, &resp)
+	if len(resp.Result) != 1 || resp.Result[0].OrganizationNumber != "923609016" {
+		t.Errorf("result = %+v, want Eksempel AS", resp.Result)
+	}
+
+	wantValidationError(t, serve(api, http.MethodPost, "/api/v1/directory/organizations/search-by-name", `{"name": "e"}`), "name")
+}
+
+func TestPersonHistory(t *testing.T) {
+	_, api := newTestAPI(t)
+
+	rec := serve(api, http.MethodGet, "/api/v1/directory/persons/91234567/history", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body)
+	}
+	var resp domain.HistoryResult
+	decode(t, rec, &resp)
+	if len(resp.Result) != 1 || len(resp.Result[0].Addresses) != 2 {
+		t.Fatalf("result = %+v, want one person with two addresses", resp.Result)
+	}
+
+	rec = serve(api, http.MethodGet, "/api/v1/directory/persons/91111111/history", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status for unknown number = %d, want 404; body: %s", rec.Code, rec.Body)
+	}
+
+	wantValidationError(t, serve(api, http.MethodGet, "/api/v1/directory/persons/22123456/history", ""), "mobileNumber")
+}
+
+func TestHealth(t *testing.T) {
+	_, api := newTestAPI(t)
+
+	rec := serve(api, http.MethodGet, "/health", "")
+	if rec.Code != http.StatusOK || rec.Body.String() != "OK" {
+		t.Errorf("health = %d %q, want 200 OK", rec.Code, rec.Body)
+	}
+}
+
+func TestCachedResponsesIncludeFreshness(t *testing.T) {
+	upstream := httptest.NewServer(bisnodefake.New(bisnodefake.Options{}))
+	t.Cleanup(upstream.Close)
+
+	cfg := &config.BisnodeConfig{BaseURL: upstream.URL}
+	cacheCfg := &config.CacheConfig{Enabled: true, TTLSeconds: 60, MaxEntries: 10}
+	directoryService := bisnodeservice.NewDirectoryService(bisnodeservice.NewDirectoryClient(cfg), nil)
+	persons := bisnodeservice.NewCachedPersonSearcher(directoryService, cacheCfg)
+	organizations := bisnodeservice.NewCachedOrganizationLookup(directoryService, cacheCfg)
+	vehicles := bisnodeservice.NewCachedVehicleLookup(bisnodeservice.NewMotorVehicleService(bisnodeservice.NewMotorVehicleClient(cfg)), cacheCfg)
+
+	api := http.NewServeMux()
+	routes.RegisterDirectoryRoutes(api, handlers.NewDirectoryHandler(persons, organizations))
+	routes.RegisterMotorVehicleRoutes(api, handlers.NewMotorVehicleHandler(vehicles))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{"person search", http.MethodPost, "/api/v1/directory/persons/search", `{"mobileNumber": "91234567"}`},
+		{"organization search", http.MethodGet, "/api/v1/directory/organizations/search?organizationNumber=923609016", ""},
+		{"organization name search", http.MethodGet, "/api/v1/directory/organizations/search-by-name?name=Eksempel", ""},
+		{"person history", http.MethodGet, "/api/v1/directory/persons/91234567/history", ""},
+		{"vehicle search", http.MethodGet, "/api/v1/motor-vehicles/search?licenseNumber=AB12345", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(api, tt.method, tt.target, tt.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d %s, want 200", rec.Code, rec.Body)
+			}
+			var resp struct {
+				Freshness *domain.Freshness `json:"freshness"`
+			}
+			decode(t, rec, &resp)
+			if resp.Freshness == nil || resp.Freshness.Status != "fresh" {
+				t.Errorf("freshness = %+v, want fresh", resp.Freshness)
+			}
+			if rec.Header().Get("X-Data-Age") == "" {
+				t.Error("X-Data-Age header is missing")
+			}
+		})
+	}
+}
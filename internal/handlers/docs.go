@@ -0,0 +1,40 @@
+package handlers
+
+import "net/http"
+
+// DocsHandler serves the API documentation
+type DocsHandler struct {
+	openAPI []byte
+}
+
+// NewDocsHandler creates a new DocsHandler serving the OpenAPI document openAPI
+func NewDocsHandler(openAPI []byte) *DocsHandler {
+	return &DocsHandler{
+		openAPI: openAPI,
+	}
+}
+
+// OpenAPI handles the request for the OpenAPI document
+// @Summary Get the OpenAPI document
+// @Description The OpenAPI 3.1 document of the API, generated from the handlers and the Go types of their requests and responses
+// @Tags Documentation
+// @Produce json
+// @Success 200 {object} object "OpenAPI 3.1 document"
+// @Router /openapi.json [get]
+func (h *DocsHandler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(h.openAPI)
+}
+
+// Health handles the health check
+// @Summary Check that the service is up
+// @Tags Health
+// @Produce plain
+// @Success 200 {string} string "OK"
+// @Router /health [get]
+func Health(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/enrich"
+	"bisnode/internal/services/bisnode"
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// EnrichHandler handles HTTP requests for enriching CSV files
+type EnrichHandler struct {
+	enricher *enrich.Enricher
+}
+
+// NewEnrichHandler creates a new EnrichHandler
+func NewEnrichHandler(enricher *enrich.Enricher) *EnrichHandler {
+	return &EnrichHandler{
+		enricher: enricher,
+	}
+}
+
+// Enrich handles the request for enriching a CSV file
+// @Summary Enrich a CSV file
+// @Description Look up the organization number, mobile number, license plate or VIN in one column of a CSV file with a header row, and return the file with the selected fields and an error column appended to every row. The column and its type are guessed from the header when not given.
+// @Tags Batch
+// @Accept text/csv
+// @Produce text/csv
+// @Param column query string false "Header of the column to look up"
+// @Param type query string false "Lookup held by the column: orgno, mobile, plate or vin"
+// @Param fields query string false "Comma separated fields to append, such as name,address,zip_code,city or brand,model,owner,next_inspection"
+// @Param file body string true "CSV file"
+// @Success 200 {string} string "Enriched CSV file"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Router /api/v1/enrich [post]
+// @Security BasicAuth
+func (h *EnrichHandler) Enrich(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := enrich.Options{
+		Column: strings.TrimSpace(query.Get("column")),
+		Type:   bisnode.BatchItemType(strings.ToLower(strings.TrimSpace(query.Get("type")))),
+	}
+	if fields := query.Get("fields"); fields != "" {
+		opts.Fields = strings.Split(fields, ",")
+	}
+
+	// The file is enriched into a buffer, so that errors found while reading
+	// it can still be reported with a JSON error response
+	var out bytes.Buffer
+	summary, err := h.enricher.Enrich(r.Context(), http.MaxBytesReader(w, r.Body, binding.DefaultMaxBodyBytes), &out, opts)
+	if err != nil {
+		respondWithBindingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="enriched.csv"`)
+	w.Header().Set("X-Enrich-Rows", strconv.Itoa(summary.Rows))
+	w.Header().Set("X-Enrich-Enriched", strconv.Itoa(summary.Enriched))
+	w.Header().Set("X-Enrich-Not-Found", strconv.Itoa(summary.NotFound))
+	w.Header().Set("X-Enrich-Failed", strconv.Itoa(summary.Failed))
+	w.WriteHeader(http.StatusOK)
+	w.Write(out.Bytes())
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/domain"
+	"bisnode/internal/geo"
+	"bisnode/internal/models"
+	"bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
+	"errors"
+	"net/http"
+)
+
+// SearchNearbyRequest represents the request for searching the directory by
+// location, either a point with a radius or a bounding box
+type SearchNearbyRequest struct {
+	Latitude     *float64 `json:"latitude,omitempty" query:"latitude" example:"59.9139"`
+	Longitude    *float64 `json:"longitude,omitempty" query:"longitude" example:"10.7522"`
+	Radius       float64  `json:"radius,omitempty" query:"radius" validate:"min=1" example:"500"`
+	MinLatitude  *float64 `json:"minLatitude,omitempty" query:"minLatitude"`
+	MinLongitude *float64 `json:"minLongitude,omitempty" query:"minLongitude"`
+	MaxLatitude  *float64 `json:"maxLatitude,omitempty" query:"maxLatitude"`
+	MaxLongitude *float64 `json:"maxLongitude,omitempty" query:"maxLongitude"`
+	SearchOptionsRequest
+	ContactChannelRequest
+}
+
+// hasPoint reports whether any of the center point fields are set
+func (r *SearchNearbyRequest) hasPoint() bool {
+	return r.Latitude != nil || r.Longitude != nil
+}
+
+// hasBoundingBox reports whether any of the bounding box fields are set
+func (r *SearchNearbyRequest) hasBoundingBox() bool {
+	return r.MinLatitude != nil || r.MinLongitude != nil || r.MaxLatitude != nil || r.MaxLongitude != nil
+}
+
+// Validate checks that the request describes exactly one complete area
+func (r *SearchNearbyRequest) Validate() error {
+	switch {
+	case r.hasPoint() && r.hasBoundingBox():
+		return validation.Errorf("latitude", "cannot be combined with a bounding box")
+	case r.hasPoint():
+		if r.Latitude == nil || r.Longitude == nil {
+			return validation.Errorf("latitude", "latitude and longitude must both be given")
+		}
+		if r.Radius == 0 {
+			return validation.Errorf("radius", "is required with latitude and longitude")
+		}
+	case r.hasBoundingBox():
+		if r.MinLatitude == nil || r.MinLongitude == nil || r.MaxLatitude == nil || r.MaxLongitude == nil {
+			return validation.Errorf("minLatitude", "minLatitude, minLongitude, maxLatitude and maxLongitude must all be given")
+		}
+	default:
+		return validation.Errorf("latitude", "is required when no bounding box is given")
+	}
+	return nil
+}
+
+// query converts the validated request into a geo search query
+func (r *SearchNearbyRequest) query() models.GeoSearchQuery {
+	q := models.GeoSearchQuery{Options: r.options()}
+	if r.hasPoint() {
+		q.Center = &geo.Point{Latitude: *r.Latitude, Longitude: *r.Longitude}
+		q.RadiusMeters = r.Radius
+	} else {
+		q.BoundingBox = &geo.BoundingBox{
+			Min: geo.Point{Latitude: *r.MinLatitude, Longitude: *r.MinLongitude},
+			Max: geo.Point{Latitude: *r.MaxLatitude, Longitude: *r.MaxLongitude},
+		}
+	}
+	return q
+}
+
+// GeoHandler handles HTTP requests for searching the directory by location
+type GeoHandler struct {
+	service *bisnode.GeoSearchService
+}
+
+// NewGeoHandler creates a new GeoHandler
+func NewGeoHandler(service *bisnode.GeoSearchService) *GeoHandler {
+	return &GeoHandler{
+		service: service,
+	}
+}
+
+// SearchNearby handles the search request for listings near a location
+// @Summary Search for persons and companies near a location
+// @Description Search for listings within a radius of a point, or inside a bounding box. Results are sorted by distance from the center and include the distance in meters.
+// @Tags Directory
+// @Accept json
+// @Produce json
+// @Param latitude query number false "Latitude of the center point"
+// @Param longitude query number false "Longitude of the center point"
+// @Param radius query number false "Radius in meters around the center point"
+// @Param minLatitude query number false "Southern edge of the bounding box"
+// @Param minLongitude query number false "Western edge of the bounding box"
+// @Param maxLatitude query number false "Northern edge of the bounding box"
+// @Param maxLongitude query number false "Eastern edge of the bounding box"
+// @Param listingType query string false "Listing type: all, company or person (default all)"
+// @Param limit query int false "Maximum number of results (server default 10, maximum 100)"
+// @Param channel query string false "Intended marketing channel: telemarketing, directMail or humanitarian"
+// @Param reservationPolicy query string false "What to do with listings reserved against the channel: flag (default) or suppress"
+// @Success 200 {object} domain.NearbyResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/directory/nearby [get]
+// @Security BasicAuth
+
+// SearchNearby handles the search request for listings near a location (POST)
+// @Summary Search for persons and companies near a location (POST)
+// @Description Search for listings within a radius of a point, or inside a bounding box, with JSON body
+// @Tags Directory
+// @Accept json
+// @Produce json
+// @Param request body SearchNearbyRequest true "Search parameters"
+// @Success 200 {object} domain.NearbyResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/directory/nearby [post]
+// @Security BasicAuth
+func (h *GeoHandler) SearchNearby(w http.ResponseWriter, r *http.Request) {
+	var req SearchNearbyRequest
+	if err := binding.Bind(r, &req); err != nil {
+		respondWithBindingError(w, err)
+		return
+	}
+
+	result, err := h.service.SearchNearby(r.Context(), req.query())
+	if err != nil {
+		if _, ok := validation.As(err); ok {
+			respondWithBindingError(w, err)
+			return
+		}
+		if errors.Is(err, bisnode.ErrGeoSearchUnavailable) {
+			respondWithError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := domain.NewNearbyResult(result)
+	if channel, policy, ok := req.channel(); ok {
+		response.ApplyChannel(channel, policy)
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
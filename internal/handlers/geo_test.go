@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"bisnode/docs"
+	"bisnode/internal/bisnodefake"
+	"bisnode/internal/config"
+	"bisnode/internal/geo"
+	"bisnode/internal/handlers"
+	"bisnode/internal/openapi"
+	"bisnode/internal/routes"
+	bisnodeservice "bisnode/internal/services/bisnode"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newGeoAPI serves the location search routes backed by a fake Bisnode API
+// and a single zip code in Oslo
+func newGeoAPI(t *testing.T) http.Handler {
+	t.Helper()
+
+	upstream := httptest.NewServer(bisnodefake.New(bisnodefake.Options{}))
+	t.Cleanup(upstream.Close)
+
+	cfg := &config.BisnodeConfig{BaseURL: upstream.URL}
+	directoryService := bisnodeservice.NewDirectoryService(bisnodeservice.NewDirectoryClient(cfg), nil)
+	zipIndex := geo.NewZipIndex([]geo.ZipCode{{Code: "0155", City: "OSLO", Center: geo.Point{Latitude: 59.9111, Longitude: 10.7503}}})
+	geoService := bisnodeservice.NewGeoSearchService(directoryService, zipIndex, &config.GeoConfig{MaxRadiusMeters: 5000, ZipMarginMeters: 2000, MaxZipCodes: 20}, nil)
+
+	mux := http.NewServeMux()
+	routes.RegisterGeoRoutes(mux, handlers.NewGeoHandler(geoService))
+
+	doc, err := openapi.Parse(docs.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return openapi.ValidateContract(mux, doc, openapi.ContractOptions{
+		Report: func(m openapi.Mismatch) {
+			t.Errorf("contract mismatch: %s", m)
+		},
+	})
+}
+
+func TestSearchNearby(t *testing.T) {
+	api := newGeoAPI(t)
+
+	rec := serve(api, http.MethodGet, "/api/v1/directory/nearby?latitude=59.9111&longitude=10.7503&radius=1000", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		field  string
+	}{
+		{"NaN radius", "/api/v1/directory/nearby?latitude=59.9111&longitude=10.7503&radius=NaN", "radius"},
+		{"infinite radius", "/api/v1/directory/nearby?latitude=59.9111&longitude=10.7503&radius=Inf", "radius"},
+		{"NaN latitude", "/api/v1/directory/nearby?latitude=NaN&longitude=10.7503&radius=1000", "latitude"},
+		{"radius too large", "/api/v1/directory/nearby?latitude=59.9111&longitude=10.7503&radius=50000", "radius"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantValidationError(t, serve(api, http.MethodGet, tt.target, ""), tt.field)
+		})
+	}
+}
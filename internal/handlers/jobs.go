@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/jobs"
+	"bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// jobBinder accepts the larger bodies needed to submit tens of thousands of items
+var jobBinder = binding.Binder{MaxBodyBytes: 16 << 20} // 16 MiB
+
+// JobResults is the response with the results of a finished job. Each item
+// has the format of a batch lookup item.
+type JobResults struct {
+	Job   jobs.Job          `json:"job"`
+	Items []json.RawMessage `json:"items"`
+}
+
+// NewBatchJobProcessor returns a jobs.Processor that looks up batch items
+func NewBatchJobProcessor(service *bisnode.BatchService) jobs.Processor {
+	return func(ctx context.Context, index int, raw json.RawMessage) jobs.Outcome {
+		var item BatchItemRequest
+		var result BatchItemResult
+		if err := json.Unmarshal(raw, &item); err != nil {
+			result = BatchItemResult{Index: index, Status: BatchItemInvalid, Error: "Invalid item"}
+		} else {
+			result = newBatchItemResult(index, item, service.LookupItem(ctx, item.item()))
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return jobs.Outcome{Failed: true}
+		}
+		return jobs.Outcome{
+			Result: data,
+			Failed: result.Status == BatchItemInvalid || result.Status == BatchItemError,
+		}
+	}
+}
+
+// JobHandler handles HTTP requests for background jobs
+type JobHandler struct {
+	manager *jobs.Manager
+}
+
+// NewJobHandler creates a new JobHandler
+func NewJobHandler(manager *jobs.Manager) *JobHandler {
+	return &JobHandler{
+		manager: manager,
+	}
+}
+
+// Submit handles the request for starting a background batch lookup
+// @Summary Submit a batch lookup job
+// @Description Start looking up a large mixed list of organization numbers, mobile numbers, license plates and VINs in the background. Poll the job for progress and fetch the results when it has finished. Jobs survive a restart of the service.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param request body BatchRequest true "Items to look up, at most the configured job size (default 50000)"
+// @Success 202 {object} jobs.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/jobs [post]
+// @Security BasicAuth
+func (h *JobHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := jobBinder.Bind(r, &req); err != nil {
+		respondWithBindingError(w, err)
+		return
+	}
+
+	items := make([]json.RawMessage, len(req.Items))
+	for i, item := range req.Items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		items[i] = data
+	}
+
+	job, err := h.manager.Submit(items)
+	if err != nil {
+		if _, ok := validation.As(err); ok {
+			respondWithBindingError(w, err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	respondWithJSON(w, http.StatusAccepted, job)
+}
+
+// List handles the request for all jobs
+// @Summary List jobs
+// @Tags Jobs
+// @Produce json
+// @Success 200 {array} jobs.Job
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/jobs [get]
+// @Security BasicAuth
+func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.manager.List())
+}
+
+// Get handles the request for the status and progress of a job
+// @Summary Get the status and progress of a job
+// @ID getJob
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} jobs.Job
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/jobs/{id} [get]
+// @Security BasicAuth
+func (h *JobHandler) Get(w http.ResponseWriter, r *http.Request) {
+	job, err := h.manager.Get(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, job)
+}
+
+// Results handles the request for the results of a finished job
+// @Summary Get the results of a job
+// @Description Returns the result of every processed item in item order. Cancelled and failed jobs return the items processed before they stopped.
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} JobResults
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/jobs/{id}/results [get]
+// @Security BasicAuth
+func (h *JobHandler) Results(w http.ResponseWriter, r *http.Request) {
+	job, results, err := h.manager.Results(r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrNotFound):
+			respondWithError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, jobs.ErrNotFinished):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	items := make([]json.RawMessage, len(results))
+	for i, res := range results {
+		items[i] = res.Result
+	}
+	respondWithJSON(w, http.StatusOK, JobResults{Job: job, Items: items})
+}
+
+// Cancel handles the request for cancelling a job
+// @Summary Cancel a job
+// @Description Stop a queued or running job. Results of items already processed are kept.
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} jobs.Job
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/jobs/{id}/cancel [post]
+// @Security BasicAuth
+func (h *JobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	job, err := h.manager.Cancel(r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrFinished):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			respondWithError(w, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, job)
+}
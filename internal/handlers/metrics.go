@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"bisnode/internal/services/bisnode"
+	"net/http"
+	"time"
+)
+
+// MetricsResponse holds the lookup counters by operation
+type MetricsResponse struct {
+	// Since is when counting started
+	Since      time.Time                         `json:"since"`
+	Operations map[string]bisnode.OperationStats `json:"operations"`
+}
+
+// MetricsHandler handles HTTP requests for lookup metrics
+type MetricsHandler struct {
+	metrics *bisnode.Metrics
+}
+
+// NewMetricsHandler creates a new MetricsHandler
+func NewMetricsHandler(metrics *bisnode.Metrics) *MetricsHandler {
+	return &MetricsHandler{
+		metrics: metrics,
+	}
+}
+
+// Get handles the request for the lookup metrics
+// @Summary Get lookup metrics
+// @ID getMetrics
+// @Description Number of calls, invalid and failed calls and durations of each directory and vehicle lookup operation since the service started
+// @Tags Metrics
+// @Produce json
+// @Success 200 {object} MetricsResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /metrics [get]
+// @Security BasicAuth
+func (h *MetricsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, MetricsResponse{
+		Since:      h.metrics.Since(),
+		Operations: h.metrics.Snapshot(),
+	})
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"bisnode/internal/binding"
+	"bisnode/internal/domain"
 	"bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
 	"encoding/json"
 	"log"
 	"net/http"
@@ -11,22 +14,24 @@ import (
 // swagger:response errorResponse
 type ErrorResponse struct {
 	// The error message
-	Error string `json:"error"`
+	Error string `json:"error" example:"Invalid request parameters"`
+	// Field-level validation errors, if any
+	Details []validation.FieldError `json:"details,omitempty"`
 }
 
 // SearchRequest represents the request body for searching a motor vehicle
 type SearchRequest struct {
-	LicenseNumber string `json:"licenseNumber,omitempty"`
-	VIN           string `json:"vin,omitempty"`
+	LicenseNumber string `json:"licenseNumber,omitempty" query:"licenseNumber" validate:"required_without=VIN,max=20" example:"AB12345"`
+	VIN           string `json:"vin,omitempty" query:"vin" validate:"max=17" example:"WBAKG7C5XBE123456"`
 }
 
 // MotorVehicleHandler handles HTTP requests for motor vehicle information
 type MotorVehicleHandler struct {
-	service *bisnode.MotorVehicleClient
+	service bisnode.VehicleLookup
 }
 
 // NewMotorVehicleHandler creates a new MotorVehicleHandler
-func NewMotorVehicleHandler(service *bisnode.MotorVehicleClient) *MotorVehicleHandler {
+func NewMotorVehicleHandler(service bisnode.VehicleLookup) *MotorVehicleHandler {
 	return &MotorVehicleHandler{
 		service: service,
 	}
@@ -40,7 +45,7 @@ func NewMotorVehicleHandler(service *bisnode.MotorVehicleClient) *MotorVehicleHa
 // @Produce json
 // @Param licenseNumber query string false "License number of the vehicle"
 // @Param vin query string false "Vehicle Identification Number"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} domain.VehicleSearchResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -54,7 +59,7 @@ func NewMotorVehicleHandler(service *bisnode.MotorVehicleClient) *MotorVehicleHa
 // @Accept json
 // @Produce json
 // @Param request body SearchRequest true "Search parameters"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} domain.VehicleSearchResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -70,53 +75,14 @@ func (h *MotorVehicleHandler) Search(w http.ResponseWriter, r *http.Request) {
 
 	// Get context from request
 	ctx := r.Context()
-	var request SearchRequest
-
-	if r.Method == http.MethodGet {
-		query := r.URL.Query()
-		licenseNumber := query.Get("licenseNumber")
-		vin := query.Get("vin")
-
-		// Check if either license number or VIN is provided
-		if licenseNumber == "" && vin == "" {
-			http.Error(w, "Either licenseNumber or VIN must be provided", http.StatusBadRequest)
-			return
-		}
-
-		var result interface{}
-		var err error
-
-		if licenseNumber != "" {
-			result, err = h.service.SearchByLicenseNumber(ctx, licenseNumber)
-		} else {
-			result, err = h.service.SearchByVIN(ctx, vin)
-		}
-
-		if err != nil {
-			log.Printf("Error searching motor vehicle: %v", err)
-			http.Error(w, "Failed to search motor vehicle", http.StatusInternalServerError)
-			return
-		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(result)
-		return
-	}
-
-	// Handle POST request
-	// Parse request body
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	// Check if either license number or VIN is provided
-	if request.LicenseNumber == "" && request.VIN == "" {
-		http.Error(w, "Either licenseNumber or VIN must be provided in the request body", http.StatusBadRequest)
+	var request SearchRequest
+	if err := binding.Bind(r, &request); err != nil {
+		respondWithBindingError(w, err)
 		return
 	}
 
-	var result interface{}
+	var result *bisnode.VehicleResponse
 	var err error
 
 	if request.LicenseNumber != "" {
@@ -125,11 +91,23 @@ func (h *MotorVehicleHandler) Search(w http.ResponseWriter, r *http.Request) {
 		result, err = h.service.SearchByVIN(ctx, request.VIN)
 	}
 	if err != nil {
+		if _, ok := validation.As(err); ok {
+			respondWithBindingError(w, err)
+			return
+		}
 		log.Printf("Error searching motor vehicle: %v", err)
-		http.Error(w, "Failed to search motor vehicle", http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, "Failed to search motor vehicle")
 		return
 	}
 
+	setFreshnessHeaders(w, result.Freshness)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(newVehicleSearchResult(result))
+}
+
+// newVehicleSearchResult maps a vehicle lookup to its response
+func newVehicleSearchResult(result *bisnode.VehicleResponse) domain.VehicleSearchResult {
+	response := domain.NewVehicleSearchResult(&result.MotorVehicleSearchResponse, result.Identifier)
+	response.Freshness = result.Freshness
+	return response
 }
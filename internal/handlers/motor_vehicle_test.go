@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"bisnode/internal/bisnodefake"
+	"bisnode/internal/domain"
+	"bisnode/internal/handlers"
+	"net/http"
+	"testing"
+)
+
+func TestMotorVehicleSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{name: "get by license number", method: http.MethodGet, target: "/api/v1/motor-vehicles/search?licenseNumber=AB12345"},
+		{name: "get by formatted license number", method: http.MethodGet, target: "/api/v1/motor-vehicles/search?licenseNumber=ab+12345"},
+		{name: "get by vin", method: http.MethodGet, target: "/api/v1/motor-vehicles/search?vin=WBAKG7C5XBE123456"},
+		{name: "get by vin as license number", method: http.MethodGet, target: "/api/v1/motor-vehicles/search?licenseNumber=WBAKG7C5XBE123456"},
+		{name: "post by license number", method: http.MethodPost, target: "/api/v1/motor-vehicles/search", body: `{"licenseNumber": "AB12345"}`},
+		{name: "post by vin", method: http.MethodPost, target: "/api/v1/motor-vehicles/search", body: `{"vin": "wbakg7c5xbe123456"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, api := newTestAPI(t)
+
+			rec := serve(api, tt.method, tt.target, tt.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body)
+			}
+			var resp domain.VehicleSearchResult
+			decode(t, rec, &resp)
+			if len(resp.Result) != 1 || resp.Result[0].RegNo != "AB12345" {
+				t.Errorf("result = %+v, want AB12345", resp.Result)
+			}
+			if resp.Identifier == nil {
+				t.Error("identifier is missing")
+			}
+		})
+	}
+}
+
+func TestMotorVehicleSearchValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		field  string
+	}{
+		{name: "nothing to search for", method: http.MethodGet, target: "/api/v1/motor-vehicles/search", field: "licenseNumber"},
+		{name: "invalid license number", method: http.MethodGet, target: "/api/v1/motor-vehicles/search?licenseNumber=AB!2345", field: "licenseNumber"},
+		{name: "plate as vin", method: http.MethodPost, target: "/api/v1/motor-vehicles/search", body: `{"vin": "AB12345"}`, field: "vin"},
+		{name: "vin too long", method: http.MethodGet, target: "/api/v1/motor-vehicles/search?vin=WBAKG7C5XBE1234567", field: "vin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, api := newTestAPI(t)
+
+			wantValidationError(t, serve(api, tt.method, tt.target, tt.body), tt.field)
+			if n := len(fake.Requests()); n != 0 {
+				t.Errorf("Bisnode received %d requests for an invalid request, want 0", n)
+			}
+		})
+	}
+}
+
+func TestMotorVehicleSearchUpstreamError(t *testing.T) {
+	fake, api := newTestAPI(t)
+	fake.Fail(bisnodefake.Fault{Status: http.StatusUnauthorized})
+
+	rec := serve(api, http.MethodGet, "/api/v1/motor-vehicles/search?licenseNumber=AB12345", "")
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500; body: %s", rec.Code, rec.Body)
+	}
+	var resp handlers.ErrorResponse
+	decode(t, rec, &resp)
+	if resp.Error == "" {
+		t.Error("error message is missing")
+	}
+}
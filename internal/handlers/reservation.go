@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/domain"
+	"bisnode/internal/models"
+	"bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
+	"net/http"
+)
+
+// maxWashNumbers is the largest number of phone numbers accepted by a single wash request
+const maxWashNumbers = 100
+
+// ContactChannelRequest names the marketing channel the results will be used
+// for, so that listings reserved against it can be flagged or suppressed
+type ContactChannelRequest struct {
+	Channel string `json:"channel,omitempty" query:"channel" validate:"oneof=telemarketing directMail humanitarian"`
+	// ReservationPolicy is flag (default) or suppress, and only applies with a channel
+	ReservationPolicy string `json:"reservationPolicy,omitempty" query:"reservationPolicy" validate:"oneof=flag suppress"`
+}
+
+// channel returns the requested channel and policy, and false when no channel was given
+func (r ContactChannelRequest) channel() (domain.Channel, domain.ReservationPolicy, bool) {
+	if r.Channel == "" {
+		return "", "", false
+	}
+	policy := domain.ReservationFlag
+	if r.ReservationPolicy != "" {
+		policy = domain.ReservationPolicy(r.ReservationPolicy)
+	}
+	return domain.Channel(r.Channel), policy, true
+}
+
+// WashRequest represents the request body for washing phone numbers against
+// the marketing reservations
+type WashRequest struct {
+	Channel      string   `json:"channel" validate:"required,oneof=telemarketing directMail humanitarian"`
+	PhoneNumbers []string `json:"phoneNumbers" validate:"required"`
+}
+
+// Validate checks the size of the phone number list
+func (r *WashRequest) Validate() error {
+	if len(r.PhoneNumbers) > maxWashNumbers {
+		return validation.Errorf("phoneNumbers", "must contain at most %d numbers", maxWashNumbers)
+	}
+	return nil
+}
+
+// Wash handles the request for checking which phone numbers may be contacted through a marketing channel
+// @Summary Wash phone numbers against marketing reservations
+// @Description Look up each mobile number and report whether the person may be contacted through the channel. Only numbers with status contactable may be contacted; numbers without a listing have no known reservation and are reported as not_found.
+// @Tags Directory
+// @Accept json
+// @Produce json
+// @Param request body WashRequest true "Channel and phone numbers, at most 100"
+// @Success 200 {object} domain.WashResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Router /api/v1/directory/wash [post]
+// @Security BasicAuth
+func (h *DirectoryHandler) Wash(w http.ResponseWriter, r *http.Request) {
+	var req WashRequest
+	if err := binding.Bind(r, &req); err != nil {
+		respondWithBindingError(w, err)
+		return
+	}
+
+	channel := domain.Channel(req.Channel)
+	lookups := bisnode.LookupMobileNumbers(r.Context(), h.persons, req.PhoneNumbers, models.SearchOptions{})
+
+	entries := make([]domain.WashEntry, len(lookups))
+	for i, l := range lookups {
+		entries[i] = l.WashEntry(channel)
+	}
+
+	respondWithJSON(w, http.StatusOK, domain.NewWashResult(channel, entries))
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/csvsafe"
+	"bisnode/internal/domain"
+	"bisnode/internal/services/bisnode"
+	"bisnode/internal/validation"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ScreeningRequest represents the request for screening a phone list. CSV
+// uploads give the channel in the query string and the numbers in the first
+// column of the body.
+type ScreeningRequest struct {
+	Channel      string   `json:"channel" validate:"required,oneof=telemarketing directMail humanitarian"`
+	PhoneNumbers []string `json:"phoneNumbers" validate:"required"`
+}
+
+// ScreeningReport is the JSON report of a completed screening
+type ScreeningReport struct {
+	bisnode.Screening
+	Result []domain.WashEntry `json:"result"`
+}
+
+// ScreeningHandler handles HTTP requests for screening phone lists
+type ScreeningHandler struct {
+	service *bisnode.ScreeningService
+}
+
+// NewScreeningHandler creates a new ScreeningHandler
+func NewScreeningHandler(service *bisnode.ScreeningService) *ScreeningHandler {
+	return &ScreeningHandler{
+		service: service,
+	}
+}
+
+// StartScreening handles the request for screening a phone list against marketing reservations
+// @Summary Screen a phone list against marketing reservations
+// @Description Start screening a list of mobile numbers in the background. Send JSON, or CSV with Content-Type text/csv, the numbers in the first column and the channel in the query string. Poll the returned screening for progress and download the report when it has completed.
+// @Tags Directory
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param channel query string false "Marketing channel for CSV uploads: telemarketing, directMail or humanitarian"
+// @Param request body ScreeningRequest true "Channel and phone numbers"
+// @Success 202 {object} bisnode.Screening
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse "Too many screenings are running or kept; retry later"
+// @Router /api/v1/directory/screenings [post]
+// @Security BasicAuth
+func (h *ScreeningHandler) StartScreening(w http.ResponseWriter, r *http.Request) {
+	var req ScreeningRequest
+	var err error
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		req.Channel = r.URL.Query().Get("channel")
+		req.PhoneNumbers, err = readPhoneList(http.MaxBytesReader(w, r.Body, binding.DefaultMaxBodyBytes))
+		if err == nil {
+			err = binding.Validate(&req)
+		}
+	} else {
+		err = binding.Bind(r, &req)
+	}
+	if err != nil {
+		respondWithBindingError(w, err)
+		return
+	}
+
+	screening, err := h.service.Start(r.Context(), req.PhoneNumbers, domain.Channel(req.Channel))
+	if err != nil {
+		if _, ok := validation.As(err); ok {
+			respondWithBindingError(w, err)
+			return
+		}
+		if errors.Is(err, bisnode.ErrScreeningsFull) {
+			w.Header().Set("Retry-After", "60")
+			respondWithError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/directory/screenings/"+screening.ID)
+	respondWithJSON(w, http.StatusAccepted, screening)
+}
+
+// GetScreening handles the request for the progress of a screening
+// @Summary Get the progress of a phone list screening
+// @ID getScreening
+// @Tags Directory
+// @Produce json
+// @Param id path string true "Screening ID"
+// @Success 200 {object} bisnode.Screening
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/directory/screenings/{id} [get]
+// @Security BasicAuth
+func (h *ScreeningHandler) GetScreening(w http.ResponseWriter, r *http.Request) {
+	screening, err := h.service.Get(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, screening)
+}
+
+// GetScreeningReport handles the request for the report of a completed screening
+// @Summary Download the report of a phone list screening
+// @ID getScreeningReport
+// @Description Returns the matched name, reservation flags and contactable verdict of every number, in the order they were submitted. The report is CSV unless format=json is given.
+// @Tags Directory
+// @Produce text/csv
+// @Produce json
+// @Param id path string true "Screening ID"
+// @Param format query string false "Report format: csv (default) or json"
+// @Success 200 {object} ScreeningReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/directory/screenings/{id}/report [get]
+// @Security BasicAuth
+func (h *ScreeningHandler) GetScreeningReport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "csv" && format != "json" {
+		respondWithBindingError(w, validation.Errorf("format", "must be one of csv, json"))
+		return
+	}
+
+	screening, entries, err := h.service.Report(r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, bisnode.ErrScreeningNotFinished):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			respondWithError(w, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+
+	if format == "json" {
+		respondWithJSON(w, http.StatusOK, ScreeningReport{Screening: screening, Result: entries})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="screening-%s.csv"`, screening.ID))
+	w.WriteHeader(http.StatusOK)
+	writeWashCSV(w, entries)
+}
+
+// readPhoneList reads phone numbers from the first column of a CSV file.
+// A header row is skipped when its first cell contains no digits.
+func readPhoneList(body io.Reader) ([]string, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var numbers []string
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				return nil, binding.ErrBodyTooLarge
+			}
+			return nil, &binding.DecodeError{Message: "Malformed CSV", Err: err}
+		}
+
+		cell := strings.TrimSpace(record[0])
+		if cell == "" || (row == 0 && !strings.ContainsAny(cell, "0123456789")) {
+			continue
+		}
+		numbers = append(numbers, cell)
+	}
+
+	return numbers, nil
+}
+
+// writeWashCSV writes the verdict of each number as CSV, escaping cells that
+// spreadsheets would run as formulas
+func writeWashCSV(w io.Writer, entries []domain.WashEntry) {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"phone_number", "status", "contactable", "name", "direct_mail", "telemarketing", "humanitarian", "error"})
+
+	for _, e := range entries {
+		var directMail, telemarketing, humanitarian string
+		if e.Reservation != nil {
+			directMail = strconv.FormatBool(e.Reservation.DirectMail)
+			telemarketing = strconv.FormatBool(e.Reservation.Telemarketing)
+			humanitarian = strconv.FormatBool(e.Reservation.Humanitarian)
+		}
+		record := []string{
+			e.PhoneNumber, string(e.Status), strconv.FormatBool(e.Contactable), e.Name,
+			directMail, telemarketing, humanitarian, e.Error,
+		}
+		for i, cell := range record {
+			record[i] = csvsafe.EscapeFormula(cell)
+		}
+		writer.Write(record)
+	}
+
+	writer.Flush()
+}
@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"bisnode/docs"
+	"bisnode/internal/bisnodefake"
+	"bisnode/internal/config"
+	"bisnode/internal/handlers"
+	"bisnode/internal/models"
+	"bisnode/internal/openapi"
+	"bisnode/internal/routes"
+	bisnodeservice "bisnode/internal/services/bisnode"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newScreeningAPI serves the screening routes backed by a fake Bisnode API
+// with a single person named like a spreadsheet formula
+func newScreeningAPI(t *testing.T) http.Handler {
+	t.Helper()
+
+	fake := bisnodefake.New(bisnodefake.Options{Fixtures: &bisnodefake.Fixtures{
+		Directory: []models.DirectoryResult{
+			{Type: "person", FirstName: `=HYPERLINK("http://example.com")`, LastName: "Nordmann", Mobile: "91234567"},
+		},
+	}})
+	upstream := httptest.NewServer(fake)
+	t.Cleanup(upstream.Close)
+
+	cfg := &config.BisnodeConfig{BaseURL: upstream.URL}
+	directoryService := bisnodeservice.NewDirectoryService(bisnodeservice.NewDirectoryClient(cfg), nil)
+	screeningService := bisnodeservice.NewScreeningService(directoryService, &config.ScreeningConfig{
+		Concurrency:      2,
+		MaxNumbers:       10,
+		RetentionMinutes: 60,
+		MaxRunning:       1,
+		MaxStored:        10,
+	})
+
+	mux := http.NewServeMux()
+	routes.RegisterScreeningRoutes(mux, handlers.NewScreeningHandler(screeningService))
+
+	doc, err := openapi.Parse(docs.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return openapi.ValidateContract(mux, doc, openapi.ContractOptions{
+		Report: func(m openapi.Mismatch) {
+			t.Errorf("contract mismatch: %s", m)
+		},
+	})
+}
+
+// screen starts a screening and waits until it has completed
+func screen(t *testing.T, api http.Handler, body string) string {
+	t.Helper()
+
+	rec := serve(api, http.MethodPost, "/api/v1/directory/screenings", body)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d %s, want 202", rec.Code, rec.Body)
+	}
+	var screening struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &screening)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for screening.Status != "completed" {
+		if time.Now().After(deadline) {
+			t.Fatalf("screening is still %s", screening.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+		json.Unmarshal(serve(api, http.MethodGet, "/api/v1/directory/screenings/"+screening.ID, "").Body.Bytes(), &screening)
+	}
+	return screening.ID
+}
+
+func TestScreeningReportEscapesFormulas(t *testing.T) {
+	api := newScreeningAPI(t)
+	id := screen(t, api, `{"channel": "telemarketing", "phoneNumbers": ["91234567", "=1+1"]}`)
+
+	rec := serve(api, http.MethodGet, "/api/v1/directory/screenings/"+id+"/report", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("report =\n%s\nwant a header and two rows", rec.Body)
+	}
+	if want := `91234567,contactable,true,"'=HYPERLINK(""http://example.com"") Nordmann",`; !strings.HasPrefix(lines[1], want) {
+		t.Errorf("row = %s, want the name escaped: %s", lines[1], want)
+	}
+	if want := `'=1+1,invalid,false,`; !strings.HasPrefix(lines[2], want) {
+		t.Errorf("row = %s, want the phone number escaped: %s", lines[2], want)
+	}
+}
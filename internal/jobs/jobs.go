@@ -0,0 +1,468 @@
+// Package jobs runs long lists of lookups in the background. Jobs are
+// persisted to a local directory and resume where they left off after a
+// restart.
+package jobs
+
+import (
+	"bisnode/internal/config"
+	"bisnode/internal/validation"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for unknown or expired jobs
+	ErrNotFound = errors.New("job not found")
+	// ErrNotFinished is returned when the results of a queued or running job are requested
+	ErrNotFinished = errors.New("job has not finished")
+	// ErrFinished is returned when cancelling a job that has already finished
+	ErrFinished = errors.New("job has already finished")
+)
+
+// Status is the state of a job
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusCancelled Status = "cancelled"
+	StatusFailed    Status = "failed"
+)
+
+// finished reports whether a job in status s will not run again
+func (s Status) finished() bool {
+	return s == StatusCompleted || s == StatusCancelled || s == StatusFailed
+}
+
+// Job describes a job and its progress
+type Job struct {
+	ID          string     `json:"id"`
+	Status      Status     `json:"status"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Succeeded   int        `json:"succeeded"`
+	Failed      int        `json:"failed"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Result is the outcome of one item of a job
+type Result struct {
+	Index  int             `json:"index"`
+	Failed bool            `json:"failed"`
+	Result json.RawMessage `json:"result"`
+}
+
+// Outcome is returned by a Processor for a single item
+type Outcome struct {
+	Result json.RawMessage
+	// Failed counts the item as failed in the job's progress
+	Failed bool
+}
+
+// Processor handles a single item of a job. It is called concurrently, and
+// outcomes produced after ctx is done are discarded and redone on resume.
+type Processor func(ctx context.Context, index int, item json.RawMessage) Outcome
+
+// run is a job that is known to the manager
+type run struct {
+	job       Job
+	cancel    context.CancelFunc
+	cancelled bool
+	// saving makes saves of the job take turns
+	saving sync.Mutex
+}
+
+// Manager submits, runs and tracks jobs
+type Manager struct {
+	store       store
+	process     Processor
+	concurrency int
+	maxItems    int
+	retention   time.Duration
+	slots       chan struct{}
+
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+
+	mu   sync.Mutex
+	runs map[string]*run
+	now  func() time.Time
+}
+
+// NewManager creates a Manager storing jobs in cfg.Dir, and resumes the
+// jobs that were queued or running when the service last stopped
+func NewManager(cfg *config.JobsConfig, process Processor) (*Manager, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating jobs directory: %w", err)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	m := &Manager{
+		store:       store{dir: cfg.Dir},
+		process:     process,
+		concurrency: max(cfg.Concurrency, 1),
+		maxItems:    cfg.MaxItems,
+		retention:   time.Duration(cfg.RetentionHours) * time.Hour,
+		slots:       make(chan struct{}, max(cfg.MaxRunning, 1)),
+		ctx:         ctx,
+		stop:        stop,
+		runs:        make(map[string]*run),
+		now:         time.Now,
+	}
+
+	jobs, err := m.store.load()
+	if err != nil {
+		stop()
+		return nil, err
+	}
+
+	// Resume oldest first, so that jobs keep their place in the queue
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	for _, job := range jobs {
+		r := &run{job: job}
+		m.runs[job.ID] = r
+		if !job.Status.finished() {
+			// Progress is only saved with the status, so count it from the results
+			if results, err := m.store.results(job.ID); err == nil {
+				r.job.Processed, r.job.Succeeded, r.job.Failed = 0, 0, 0
+				for _, res := range results {
+					r.job.count(res.Failed)
+				}
+			}
+			log.Printf("Resuming job %s at %d of %d items", job.ID, r.job.Processed, job.Total)
+			m.start(r)
+		}
+	}
+
+	m.mu.Lock()
+	m.purge()
+	m.mu.Unlock()
+
+	return m, nil
+}
+
+// Submit stores a new job for items and queues it
+func (m *Manager) Submit(items []json.RawMessage) (Job, error) {
+	if len(items) == 0 {
+		return Job{}, validation.Errorf("items", "is required")
+	}
+	if len(items) > m.maxItems {
+		return Job{}, validation.Errorf("items", "must contain at most %d items", m.maxItems)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	job := Job{ID: id, Status: StatusQueued, Total: len(items), CreatedAt: m.now()}
+	if err := m.store.create(job, items); err != nil {
+		return Job{}, err
+	}
+
+	r := &run{job: job}
+	m.mu.Lock()
+	m.purge()
+	m.runs[id] = r
+	m.mu.Unlock()
+
+	m.start(r)
+	return job, nil
+}
+
+// Get returns a job and its progress
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.runs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return r.job, nil
+}
+
+// List returns every job, newest first
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purge()
+	jobs := make([]Job, 0, len(m.runs))
+	for _, r := range m.runs {
+		jobs = append(jobs, r.job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs
+}
+
+// Results returns the results of a finished job in item order. Cancelled and
+// failed jobs return the items processed before they stopped.
+func (m *Manager) Results(id string) (Job, []Result, error) {
+	job, err := m.Get(id)
+	if err != nil {
+		return Job{}, nil, err
+	}
+	if !job.Status.finished() {
+		return job, nil, ErrNotFinished
+	}
+
+	results, err := m.store.results(id)
+	if err != nil {
+		return job, nil, err
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+	return job, results, nil
+}
+
+// Cancel stops a queued or running job. Items already processed keep their results.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	r, ok := m.runs[id]
+	if !ok {
+		m.mu.Unlock()
+		return Job{}, ErrNotFound
+	}
+	if r.job.Status.finished() {
+		job := r.job
+		m.mu.Unlock()
+		return job, ErrFinished
+	}
+
+	r.cancelled = true
+	if r.cancel != nil {
+		r.cancel()
+	}
+	job := m.finish(r, StatusCancelled, "")
+	m.mu.Unlock()
+
+	m.save(r)
+	return job, nil
+}
+
+// Close stops all jobs and waits for them to return. Unfinished jobs keep
+// their status and resume the next time a Manager is created.
+func (m *Manager) Close() {
+	m.stop()
+	m.wg.Wait()
+}
+
+// start runs r in the background once a slot is free
+func (m *Manager) start(r *run) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.mu.Lock()
+	r.cancel = cancel
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+
+		select {
+		case m.slots <- struct{}{}:
+			defer func() { <-m.slots }()
+		case <-ctx.Done():
+			return
+		}
+
+		if err := m.execute(ctx, r); err != nil {
+			log.Printf("Job %s failed: %v", r.job.ID, err)
+			m.mu.Lock()
+			if r.job.Status.finished() {
+				m.mu.Unlock()
+				return
+			}
+			m.finish(r, StatusFailed, err.Error())
+			m.mu.Unlock()
+			m.save(r)
+		}
+	}()
+}
+
+// execute processes the items of r that have no result yet
+func (m *Manager) execute(ctx context.Context, r *run) error {
+	id := r.job.ID
+
+	items, err := m.store.items(id)
+	if err != nil {
+		return fmt.Errorf("error reading job items: %w", err)
+	}
+	results, err := m.store.results(id)
+	if err != nil {
+		return err
+	}
+	out, err := m.store.openResults(id)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	done := make(map[int]bool, len(results))
+	m.mu.Lock()
+	if r.cancelled {
+		m.mu.Unlock()
+		return nil
+	}
+	r.job.Processed, r.job.Succeeded, r.job.Failed = 0, 0, 0
+	for _, res := range results {
+		done[res.Index] = true
+		r.job.count(res.Failed)
+	}
+	startedAt := m.now()
+	r.job.Status = StatusRunning
+	r.job.StartedAt = &startedAt
+	m.mu.Unlock()
+	if err := m.write(r); err != nil {
+		return err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		writeErr error
+	)
+	sem := make(chan struct{}, m.concurrency)
+
+	for i, item := range items {
+		if done[i] {
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, item json.RawMessage) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			outcome := m.process(ctx, i, item)
+			if ctx.Err() != nil {
+				return
+			}
+
+			line, err := json.Marshal(Result{Index: i, Failed: outcome.Failed, Result: outcome.Result})
+			if err == nil {
+				line = append(line, '\n')
+			}
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if err == nil {
+				_, err = out.Write(line)
+			}
+			if err != nil {
+				if writeErr == nil {
+					writeErr = fmt.Errorf("error writing job result: %w", err)
+				}
+				return
+			}
+			r.job.count(outcome.Failed)
+		}(i, item)
+	}
+	wg.Wait()
+
+	if writeErr != nil {
+		return writeErr
+	}
+	if ctx.Err() != nil {
+		// Cancelled by the caller, which recorded the status, or stopped by Close
+		return nil
+	}
+
+	m.mu.Lock()
+	if r.cancelled {
+		m.mu.Unlock()
+		return nil
+	}
+	job := m.finish(r, StatusCompleted, "")
+	m.mu.Unlock()
+
+	m.save(r)
+	log.Printf("Job %s completed: %d succeeded, %d failed", id, job.Succeeded, job.Failed)
+	return nil
+}
+
+// finish records the final status of r and returns the job. The caller must
+// hold m.mu, and saves r once it has released it, so other requests do not
+// wait for the disk.
+func (m *Manager) finish(r *run, status Status, message string) Job {
+	completedAt := m.now()
+	r.job.Status = status
+	r.job.Error = message
+	r.job.CompletedAt = &completedAt
+	return r.job
+}
+
+// save stores the current state of r, logging a failure
+func (m *Manager) save(r *run) {
+	if err := m.write(r); err != nil {
+		log.Printf("Error saving job %s: %v", r.job.ID, err)
+	}
+}
+
+// write stores the current state of r. The caller must not hold m.mu, so
+// other requests do not wait for the disk. Writes of the same job take turns,
+// and each stores the job as it is when its turn comes, so a slow write of
+// an earlier status cannot overwrite a later one.
+func (m *Manager) write(r *run) error {
+	r.saving.Lock()
+	defer r.saving.Unlock()
+
+	m.mu.Lock()
+	job := r.job
+	m.mu.Unlock()
+	return m.store.save(job)
+}
+
+// purge removes finished jobs past their retention. The caller must hold m.mu.
+func (m *Manager) purge() {
+	now := m.now()
+	for id, r := range m.runs {
+		if r.job.CompletedAt != nil && now.Sub(*r.job.CompletedAt) > m.retention {
+			if err := m.store.remove(id); err != nil {
+				log.Printf("Error removing job %s: %v", id, err)
+				continue
+			}
+			delete(m.runs, id)
+		}
+	}
+}
+
+// count records one processed item
+func (j *Job) count(failed bool) {
+	j.Processed++
+	if failed {
+		j.Failed++
+	} else {
+		j.Succeeded++
+	}
+}
+
+// newID returns a random identifier for a job
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
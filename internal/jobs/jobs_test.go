@@ -0,0 +1,298 @@
+package jobs
+
+import (
+	"bisnode/internal/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testConfig returns a configuration storing jobs in a temporary directory
+func testConfig(t *testing.T) *config.JobsConfig {
+	return &config.JobsConfig{
+		Dir:            t.TempDir(),
+		MaxItems:       100,
+		Concurrency:    1,
+		MaxRunning:     1,
+		RetentionHours: 1,
+	}
+}
+
+// numbers returns the items 0 to n-1
+func numbers(n int) []json.RawMessage {
+	items := make([]json.RawMessage, n)
+	for i := range items {
+		items[i] = json.RawMessage(strconv.Itoa(i))
+	}
+	return items
+}
+
+// recorder is a Processor that echoes items and remembers which it processed.
+// Items from block on wait until their context is done.
+type recorder struct {
+	mu    sync.Mutex
+	seen  []int
+	block int
+}
+
+func newRecorder(block int) *recorder {
+	return &recorder{block: block}
+}
+
+func (p *recorder) process(ctx context.Context, index int, item json.RawMessage) Outcome {
+	if index >= p.block {
+		<-ctx.Done()
+		return Outcome{}
+	}
+	p.mu.Lock()
+	p.seen = append(p.seen, index)
+	p.mu.Unlock()
+	return Outcome{Result: item, Failed: index%2 == 1}
+}
+
+func (p *recorder) processed() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seen := append([]int(nil), p.seen...)
+	sort.Ints(seen)
+	return seen
+}
+
+// waitFor polls a job until done reports true for it
+func waitFor(t *testing.T, m *Manager, id string, done func(Job) bool) Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if done(job) {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job = %+v, gave up waiting", job)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// hasStatus returns a condition for waitFor
+func hasStatus(status Status) func(Job) bool {
+	return func(j Job) bool { return j.Status == status }
+}
+
+// indexes returns the indexes of results
+func indexes(results []Result) []int {
+	out := make([]int, len(results))
+	for i, r := range results {
+		out[i] = r.Index
+	}
+	return out
+}
+
+func TestManagerResumesAfterRestart(t *testing.T) {
+	cfg := testConfig(t)
+
+	first := newRecorder(3)
+	m, err := NewManager(cfg, first.process)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := m.Submit(numbers(5))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitFor(t, m, job.ID, func(j Job) bool { return j.Processed == 3 })
+	m.Close()
+
+	second := newRecorder(5)
+	m, err = NewManager(cfg, second.process)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	done := waitFor(t, m, job.ID, hasStatus(StatusCompleted))
+	if done.Processed != 5 || done.Succeeded != 3 || done.Failed != 2 {
+		t.Errorf("job = %+v, want 5 processed, 3 succeeded and 2 failed", done)
+	}
+	if got := second.processed(); !reflect.DeepEqual(got, []int{3, 4}) {
+		t.Errorf("resumed job processed items %v, want only [3 4]", got)
+	}
+
+	_, results, err := m.Results(job.ID)
+	if err != nil {
+		t.Fatalf("Results() error = %v", err)
+	}
+	if got := indexes(results); !reflect.DeepEqual(got, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("results = %v, want every item once in order", got)
+	}
+}
+
+func TestManagerIgnoresTruncatedResult(t *testing.T) {
+	cfg := testConfig(t)
+
+	// A job that crashed while writing the result of item 1
+	s := store{dir: cfg.Dir}
+	job := Job{ID: "crashed", Status: StatusRunning, Total: 3, CreatedAt: time.Now()}
+	if err := s.create(job, numbers(3)); err != nil {
+		t.Fatal(err)
+	}
+	partial := `{"index":0,"failed":false,"result":0}` + "\n" + `{"index":1,"fai`
+	if err := os.WriteFile(filepath.Join(s.path(job.ID), resultsFile), []byte(partial), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := newRecorder(3)
+	m, err := NewManager(cfg, p.process)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	waitFor(t, m, job.ID, hasStatus(StatusCompleted))
+	if got := p.processed(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("processed items %v, want [1 2]", got)
+	}
+	_, results, err := m.Results(job.ID)
+	if err != nil {
+		t.Fatalf("Results() error = %v", err)
+	}
+	if got := indexes(results); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Errorf("results = %v, want [0 1 2]", got)
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	cfg := testConfig(t)
+
+	p := newRecorder(2)
+	m, err := NewManager(cfg, p.process)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	running, err := m.Submit(numbers(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, m, running.ID, func(j Job) bool { return j.Status == StatusRunning && j.Processed == 2 })
+
+	// Only one job runs at a time, so this one waits in the queue
+	queued, err := m.Submit(numbers(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := m.Cancel(queued.ID)
+	if err != nil || job.Status != StatusCancelled || job.StartedAt != nil {
+		t.Errorf("Cancel(queued) = %+v, %v, want cancelled before starting", job, err)
+	}
+
+	job, err = m.Cancel(running.ID)
+	if err != nil || job.Status != StatusCancelled || job.CompletedAt == nil {
+		t.Errorf("Cancel(running) = %+v, %v, want cancelled", job, err)
+	}
+	if _, err := m.Cancel(running.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("Cancel() twice error = %v, want ErrFinished", err)
+	}
+	if _, err := m.Cancel("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(unknown) error = %v, want ErrNotFound", err)
+	}
+	m.Close()
+
+	if got := p.processed(); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("processed items %v, want only the first two of the running job", got)
+	}
+
+	// The cancellations are stored and the jobs do not resume
+	p = newRecorder(0)
+	m, err = NewManager(cfg, p.process)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	for _, id := range []string{running.ID, queued.ID} {
+		if job, _ := m.Get(id); job.Status != StatusCancelled {
+			t.Errorf("job %s after restart = %+v, want cancelled", id, job)
+		}
+	}
+	_, results, err := m.Results(running.ID)
+	if err != nil || !reflect.DeepEqual(indexes(results), []int{0, 1}) {
+		t.Errorf("Results() = %v, %v, want the items processed before cancelling", indexes(results), err)
+	}
+}
+
+func TestManagerPurgesOldJobs(t *testing.T) {
+	cfg := testConfig(t)
+
+	m, err := NewManager(cfg, newRecorder(10).process)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	now := time.Now()
+	m.mu.Lock()
+	m.now = func() time.Time { return now }
+	m.mu.Unlock()
+
+	old, err := m.Submit(numbers(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, m, old.ID, hasStatus(StatusCompleted))
+
+	// An hour later the job is still kept; after that it is removed
+	m.mu.Lock()
+	m.now = func() time.Time { return now.Add(time.Hour) }
+	m.mu.Unlock()
+	if jobs := m.List(); len(jobs) != 1 {
+		t.Fatalf("List() = %+v, want the job within its retention", jobs)
+	}
+
+	m.mu.Lock()
+	m.now = func() time.Time { return now.Add(time.Hour + time.Second) }
+	m.mu.Unlock()
+	recent, err := m.Submit(numbers(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, m, recent.ID, hasStatus(StatusCompleted))
+
+	if jobs := m.List(); len(jobs) != 1 || jobs[0].ID != recent.ID {
+		t.Errorf("List() = %+v, want only the recent job", jobs)
+	}
+	if _, err := m.Get(old.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(old) error = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Dir, old.ID)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("directory of the old job: %v, want it removed", err)
+	}
+}
+
+func TestManagerZeroLimits(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Concurrency, cfg.MaxRunning = 0, 0
+
+	m, err := NewManager(cfg, newRecorder(100).process)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	job, err := m.Submit(numbers(3))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitFor(t, m, job.ID, hasStatus(StatusCompleted))
+}
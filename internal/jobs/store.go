@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	jobFile     = "job.json"
+	itemsFile   = "items.json"
+	resultsFile = "results.jsonl"
+)
+
+// store keeps each job in its own directory: the job status in job.json,
+// the submitted items in items.json and one line per processed item in
+// results.jsonl. Results are appended as items finish, so a job can resume
+// after a restart without repeating the work already done.
+type store struct {
+	dir string
+}
+
+// create writes a new job and its items
+func (s store) create(job Job, items []json.RawMessage) error {
+	if err := os.MkdirAll(s.path(job.ID), 0o755); err != nil {
+		return fmt.Errorf("error creating job directory: %w", err)
+	}
+	if err := writeJSON(filepath.Join(s.path(job.ID), itemsFile), items); err != nil {
+		return err
+	}
+	return s.save(job)
+}
+
+// save replaces the stored status of job
+func (s store) save(job Job) error {
+	return writeJSON(filepath.Join(s.path(job.ID), jobFile), job)
+}
+
+// load reads every stored job, skipping directories without a readable job.json
+func (s store) load() ([]Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading jobs directory: %w", err)
+	}
+
+	var jobs []Job
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		var job Job
+		if err := readJSON(filepath.Join(s.path(e.Name()), jobFile), &job); err != nil {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// items reads the items submitted with a job
+func (s store) items(id string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	if err := readJSON(filepath.Join(s.path(id), itemsFile), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// results reads the processed items of a job in the order they finished.
+// A truncated last line, left by a crash while writing, is ignored.
+func (s store) results(id string) ([]Result, error) {
+	f, err := os.Open(filepath.Join(s.path(id), resultsFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error opening job results: %w", err)
+	}
+	defer f.Close()
+
+	var results []Result
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r Result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		results = append(results, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading job results: %w", err)
+	}
+	return results, nil
+}
+
+// openResults opens the results of a job for appending. A truncated last
+// line is cut off first, so the next result starts on a line of its own.
+func (s store) openResults(id string) (*os.File, error) {
+	path := filepath.Join(s.path(id), resultsFile)
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error opening job results: %w", err)
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if err := os.Truncate(path, int64(bytes.LastIndexByte(data, '\n')+1)); err != nil {
+			return nil, fmt.Errorf("error repairing job results: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening job results: %w", err)
+	}
+	return f, nil
+}
+
+// remove deletes a job and its results
+func (s store) remove(id string) error {
+	return os.RemoveAll(s.path(id))
+}
+
+// path returns the directory of a job
+func (s store) path(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+// writeJSON replaces path atomically, so that a crash never leaves a
+// half-written file behind
+func writeJSON(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("error writing %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error writing %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// readJSON decodes the file at path into v
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
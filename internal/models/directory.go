@@ -1,5 +1,7 @@
 package models
 
+import "bisnode/internal/geo"
+
 // DirectorySearchRequest represents the request body for directory search
 type DirectorySearchRequest struct {
 	Form struct {
@@ -7,10 +9,10 @@ type DirectorySearchRequest struct {
 		SearchString string `json:"Searchstring"`
 	} `json:"Form"`
 	Options struct {
-		SearchMode       int  `json:"SearchMode"`
-		OnlyFoundWords   bool `json:"OnlyFoundWords"`
-		ListingType      int  `json:"ListingType"`
-		ResultLimit      int  `json:"ResultLimitSearch"`
+		SearchMode     SearchMode  `json:"SearchMode"`
+		OnlyFoundWords bool        `json:"OnlyFoundWords"`
+		ListingType    ListingType `json:"ListingType"`
+		ResultLimit    int         `json:"ResultLimitSearch"`
 	} `json:"Options"`
 }
 
@@ -20,69 +22,139 @@ type DirectorySearchResponse struct {
 	Service struct {
 		Dataset       string `json:"dataset"`
 		Documentation string `json:"documentation"`
-		Version      string `json:"version"`
-		Timestamp    string `json:"timestamp"`
-		Message      string `json:"message"`
+		Version       string `json:"version"`
+		Timestamp     string `json:"timestamp"`
+		Message       string `json:"message"`
 	} `json:"Service"`
 }
 
 // DirectoryResult represents a single result in the directory search
 type DirectoryResult struct {
-	Type              string `json:"type"`
-	OrganizationNumber string `json:"organizationnumber,omitempty"`
-	Born              string `json:"born,omitempty"`
-	Dead              string `json:"dead,omitempty"`
-	Age               struct {
-		Year  int `json:"year"`
-		Month int `json:"month"`
-		Day   int `json:"day"`
-	} `json:"Age,omitempty"`
-	Gender     string `json:"gender,omitempty"`
-	FirstName  string `json:"firstname,omitempty"`
-	MiddleName string `json:"middlename,omitempty"`
-	LastName   string `json:"lastname,omitempty"`
-	StreetName string `json:"streetname,omitempty"`
-	HouseNo    string `json:"houseno,omitempty"`
-	Entrance   string `json:"entrance,omitempty"`
-	ZipCode    string `json:"zipcode,omitempty"`
-	City       string `json:"city,omitempty"`
-	Longitude  float64 `json:"longitude"`
-	Latitude   float64 `json:"latitude"`
-	Telephone  string `json:"telephone,omitempty"`
-	Mobile     string `json:"mobile,omitempty"`
-	Reservation struct {
-		DirectMail    bool `json:"directmail"`
-		Telemarketing bool `json:"telemarketing"`
-		Humanitarian  bool `json:"humanitarian"`
-	} `json:"Reservation,omitempty"`
-	Addresses []struct {
-		Source      string `json:"source"`
-		Type        string `json:"type"`
-		Quality     string `json:"quality"`
-		StreetName  string `json:"streetname"`
-		HouseNo     string `json:"houseno"`
-		Entrance    string `json:"entrance"`
-		ZipCode     string `json:"zipcode"`
-		City        string `json:"city"`
-		Longitude   float64 `json:"longitude"`
-		Latitude    float64 `json:"latitude"`
-		Date        struct {
-			FirstAcquired     string `json:"firstaquired"`
-			LastAcquired      string `json:"lastaquired"`
-			InformationChanged string `json:"informationchanged"`
-		} `json:"Date"`
-	} `json:"Address,omitempty"`
-	Phones []struct {
-		Source      string `json:"source"`
-		Type        string `json:"type"`
-		Quality     string `json:"quality"`
-		Number      string `json:"number"`
-		Date        struct {
-			FirstAcquired     string `json:"firstaquired"`
-			LastAcquired      string `json:"lastaquired"`
-			InformationChanged string `json:"informationchanged"`
-		} `json:"Date"`
-	} `json:"Phone,omitempty"`
+	Type               string               `json:"type"`
+	OrganizationNumber string               `json:"organizationnumber,omitempty"`
+	Born               string               `json:"born,omitempty"`
+	Dead               string               `json:"dead,omitempty"`
+	Age                DirectoryAge         `json:"Age,omitempty"`
+	Gender             string               `json:"gender,omitempty"`
+	FirstName          string               `json:"firstname,omitempty"`
+	MiddleName         string               `json:"middlename,omitempty"`
+	LastName           string               `json:"lastname,omitempty"`
+	StreetName         string               `json:"streetname,omitempty"`
+	HouseNo            string               `json:"houseno,omitempty"`
+	Entrance           string               `json:"entrance,omitempty"`
+	ZipCode            string               `json:"zipcode,omitempty"`
+	City               string               `json:"city,omitempty"`
+	Longitude          float64              `json:"longitude"`
+	Latitude           float64              `json:"latitude"`
+	Telephone          string               `json:"telephone,omitempty"`
+	Mobile             string               `json:"mobile,omitempty"`
+	Reservation        DirectoryReservation `json:"Reservation,omitempty"`
+	Addresses          []DirectoryAddress   `json:"Address,omitempty"`
+	Phones             []DirectoryPhone     `json:"Phone,omitempty"`
+}
+
+// DirectoryAge is the age of a person in years, months and days
+type DirectoryAge struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+// DirectoryReservation holds the marketing reservations registered for a person
+type DirectoryReservation struct {
+	DirectMail    bool `json:"directmail"`
+	Telemarketing bool `json:"telemarketing"`
+	Humanitarian  bool `json:"humanitarian"`
+}
+
+// DirectoryAddress is a current or previous address of a listing
+type DirectoryAddress struct {
+	Source     string        `json:"source"`
+	Type       string        `json:"type"`
+	Quality    string        `json:"quality"`
+	StreetName string        `json:"streetname"`
+	HouseNo    string        `json:"houseno"`
+	Entrance   string        `json:"entrance"`
+	ZipCode    string        `json:"zipcode"`
+	City       string        `json:"city"`
+	Longitude  float64       `json:"longitude"`
+	Latitude   float64       `json:"latitude"`
+	Date       DirectoryDate `json:"Date"`
+}
+
+// DirectoryPhone is a current or previous phone number of a listing
+type DirectoryPhone struct {
+	Source  string        `json:"source"`
+	Type    string        `json:"type"`
+	Quality string        `json:"quality"`
+	Number  string        `json:"number"`
+	Date    DirectoryDate `json:"Date"`
+}
+
+// DirectoryDate holds when an address or phone number was registered and changed
+type DirectoryDate struct {
+	FirstAcquired      string `json:"firstaquired"`
+	LastAcquired       string `json:"lastaquired"`
+	InformationChanged string `json:"informationchanged"`
+}
+
+// PersonSearchQuery holds structured name and address fields for a person search
+type PersonSearchQuery struct {
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Street    string `json:"street,omitempty"`
+	ZipCode   string `json:"zipCode,omitempty"`
+	City      string `json:"city,omitempty"`
+	// Options are the Bisnode search options; the listing type defaults to person
+	Options SearchOptions `json:"options,omitempty"`
+}
+
+// OrganizationSearchQuery holds the name and filters for an organization name search
+type OrganizationSearchQuery struct {
+	Name    string `json:"name"`
+	City    string `json:"city,omitempty"`
+	ZipCode string `json:"zipCode,omitempty"`
+	// Options are the Bisnode search options; the listing type is always company
+	Options SearchOptions `json:"options,omitempty"`
+}
+
+// OrganizationSummary is a compact search result for an organization. The
+// organization number can be used to look up the full record.
+type OrganizationSummary struct {
+	OrganizationNumber string `json:"organizationNumber"`
+	Name               string `json:"name"`
+	StreetName         string `json:"streetName,omitempty"`
+	HouseNo            string `json:"houseNo,omitempty"`
+	ZipCode            string `json:"zipCode,omitempty"`
+	City               string `json:"city,omitempty"`
+}
+
+// OrganizationSearchResponse represents the response from an organization name search
+type OrganizationSearchResponse struct {
+	Result []OrganizationSummary `json:"result"`
+}
+
+// GeoSearchQuery describes an area to search for listings in, either a point
+// with a radius or a bounding box
+type GeoSearchQuery struct {
+	Center       *geo.Point       `json:"center,omitempty"`
+	RadiusMeters float64          `json:"radiusMeters,omitempty"`
+	BoundingBox  *geo.BoundingBox `json:"boundingBox,omitempty"`
+	// Options are the Bisnode search options; the listing type defaults to all
+	Options SearchOptions `json:"options,omitempty"`
+}
+
+// GeoSearchResult is a directory listing with its distance from the search center
+type GeoSearchResult struct {
+	DirectoryResult
+	DistanceMeters float64 `json:"distanceMeters"`
+}
+
+// GeoSearchResponse represents the response from a geo search, nearest listing first
+type GeoSearchResponse struct {
+	Center       geo.Point         `json:"center"`
+	RadiusMeters float64           `json:"radiusMeters"`
+	Result       []GeoSearchResult `json:"result"`
 }
 
 // SearchRequest represents a search request for directory search
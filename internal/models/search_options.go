@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SearchMode controls how Bisnode matches the words of a directory search
+type SearchMode int
+
+const (
+	// SearchModeExact only matches words exactly as written
+	SearchModeExact SearchMode = 1
+	// SearchModePhonetic matches words that sound alike, such as "Hansen" and "Hanssen"
+	SearchModePhonetic SearchMode = 2
+	// SearchModeSmart matches exactly where possible and falls back to phonetic matching
+	SearchModeSmart SearchMode = 3
+)
+
+var searchModeNames = map[SearchMode]string{
+	SearchModeExact:    "exact",
+	SearchModePhonetic: "phonetic",
+	SearchModeSmart:    "smart",
+}
+
+// String returns the name used for the search mode in the API
+func (m SearchMode) String() string {
+	if name, ok := searchModeNames[m]; ok {
+		return name
+	}
+	return strconv.Itoa(int(m))
+}
+
+// ParseSearchMode parses a search mode name such as "exact". Like the API, it
+// only accepts the lowercase names.
+func ParseSearchMode(s string) (SearchMode, error) {
+	for mode, name := range searchModeNames {
+		if s == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown search mode %q", s)
+}
+
+// ListingType restricts a directory search to persons, companies or both
+type ListingType int
+
+const (
+	// ListingTypeAll returns both persons and companies
+	ListingTypeAll ListingType = 0
+	// ListingTypeCompany only returns companies
+	ListingTypeCompany ListingType = 1
+	// ListingTypePerson only returns persons
+	ListingTypePerson ListingType = 2
+)
+
+var listingTypeNames = map[ListingType]string{
+	ListingTypeAll:     "all",
+	ListingTypeCompany: "company",
+	ListingTypePerson:  "person",
+}
+
+// String returns the name used for the listing type in the API
+func (t ListingType) String() string {
+	if name, ok := listingTypeNames[t]; ok {
+		return name
+	}
+	return strconv.Itoa(int(t))
+}
+
+// ParseListingType parses a listing type name such as "person". Like the API,
+// it only accepts the lowercase names.
+func ParseListingType(s string) (ListingType, error) {
+	for t, name := range listingTypeNames {
+		if s == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown listing type %q", s)
+}
+
+// SearchOptions are the Bisnode directory search options a caller may choose.
+// Unset fields are filled in from the server configuration.
+type SearchOptions struct {
+	// SearchMode is 0 when not set
+	SearchMode SearchMode `json:"searchMode,omitempty"`
+	// ListingType is nil when not set, since ListingTypeAll is 0
+	ListingType *ListingType `json:"listingType,omitempty"`
+	// ResultLimit is the maximum number of results, 0 when not set
+	ResultLimit int `json:"resultLimit,omitempty"`
+	// OnlyFoundWords only returns results that contain every search word
+	OnlyFoundWords *bool `json:"onlyFoundWords,omitempty"`
+}
+
+// CacheKey returns a string that identifies the options, for use in cache keys
+func (o SearchOptions) CacheKey() string {
+	listing, onlyFound := "-", "-"
+	if o.ListingType != nil {
+		listing = o.ListingType.String()
+	}
+	if o.OnlyFoundWords != nil {
+		onlyFound = strconv.FormatBool(*o.OnlyFoundWords)
+	}
+	return fmt.Sprintf("%s:%s:%d:%s", o.SearchMode, listing, o.ResultLimit, onlyFound)
+}
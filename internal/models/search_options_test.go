@@ -0,0 +1,61 @@
+package models
+
+import "testing"
+
+func TestParseSearchMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    SearchMode
+		wantErr bool
+	}{
+		{input: "exact", want: SearchModeExact},
+		{input: "phonetic", want: SearchModePhonetic},
+		{input: "smart", want: SearchModeSmart},
+		{input: "SMART", wantErr: true},
+		{input: " smart", wantErr: true},
+		{input: "3", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSearchMode(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSearchMode(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseListingType(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ListingType
+		wantErr bool
+	}{
+		{input: "all", want: ListingTypeAll},
+		{input: "company", want: ListingTypeCompany},
+		{input: "person", want: ListingTypePerson},
+		{input: "Person", wantErr: true},
+		{input: "2", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseListingType(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseListingType(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestSearchModeNamesRoundTrip(t *testing.T) {
+	for mode := range searchModeNames {
+		if got, err := ParseSearchMode(mode.String()); err != nil || got != mode {
+			t.Errorf("ParseSearchMode(%q) = %v, %v, want %v", mode.String(), got, err, mode)
+		}
+	}
+	for listing := range listingTypeNames {
+		if got, err := ParseListingType(listing.String()); err != nil || got != listing {
+			t.Errorf("ParseListingType(%q) = %v, %v, want %v", listing.String(), got, err, listing)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package openapi
+
+import (
+	"bisnode/internal/binding"
+	"bisnode/internal/validation"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mismatch is a difference between a request or response and the document
+type Mismatch struct {
+	// Route is the documented route, such as "GET /api/v1/jobs/{id}"
+	Route string
+	// Status is the status of the response
+	Status int
+	// In is where the mismatch is, such as "response body" or "query parameter limit"
+	In string
+	// Path locates the mismatch in a JSON body, such as "$.result[0].name"
+	Path    string
+	Message string
+}
+
+// String describes the mismatch
+func (m Mismatch) String() string {
+	where := m.In
+	if m.Path != "" {
+		where += " " + m.Path
+	}
+	return fmt.Sprintf("%s (status %d): %s: %s", m.Route, m.Status, where, m.Message)
+}
+
+// ContractOptions configures ValidateContract
+type ContractOptions struct {
+	// Fail replaces the response to a request with mismatches by a 500 error
+	// listing them
+	Fail bool
+	// Report is called with every mismatch; mismatches are logged when nil
+	Report func(Mismatch)
+}
+
+// contract holds the documented operations by route
+type contract struct {
+	doc        *Document
+	routes     *http.ServeMux
+	operations map[string]*Operation
+}
+
+// ValidateContract checks every request and response of next against the
+// document. Responses must have a documented status and content type, and
+// JSON bodies must match their schema. Requests are only reported when next
+// accepted them although they do not match the document; rejecting an
+// invalid request is what handlers are supposed to do. Routes missing from
+// the document are not checked. JSON request bodies larger than the handlers
+// accept are answered with 413 Request Entity Too Large without calling next.
+//
+// Responses are buffered until they have been checked, so the middleware is
+// meant for tests and staging rather than production.
+func ValidateContract(next http.Handler, doc *Document, opts ContractOptions) http.Handler {
+	c := &contract{
+		doc:        doc,
+		routes:     http.NewServeMux(),
+		operations: make(map[string]*Operation),
+	}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			route := strings.ToUpper(method) + " " + path
+			c.routes.Handle(route, http.NotFoundHandler())
+			c.operations[route] = op
+		}
+	}
+
+	report := opts.Report
+	if report == nil {
+		report = func(m Mismatch) {
+			log.Printf("Contract mismatch: %s", m)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := c.routes.Handler(r)
+		op, ok := c.operations[route]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Keep a copy of JSON request bodies, which the handler consumes. The
+		// copy is limited like the handlers limit bodies, so a large body is
+		// rejected before it is read into memory.
+		var body []byte
+		if isJSONMediaType(r.Header.Get("Content-Type")) && r.Body != nil {
+			var err error
+			if body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, binding.DefaultMaxBodyBytes)); err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					respondWithError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+					return
+				}
+				http.Error(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		mismatches := c.checkResponse(route, op, rec)
+		if rec.status < 400 {
+			mismatches = append(mismatches, c.checkRequest(route, op, r, body, rec.status)...)
+		}
+		for _, m := range mismatches {
+			report(m)
+		}
+
+		if opts.Fail && len(mismatches) > 0 {
+			respondWithMismatches(w, mismatches)
+			return
+		}
+		rec.writeTo(w)
+	})
+}
+
+// checkResponse checks the status, content type and body of a response
+func (c *contract) checkResponse(route string, op *Operation, rec *bufferedResponse) []Mismatch {
+	mismatch := func(in, path, message string) Mismatch {
+		return Mismatch{Route: route, Status: rec.status, In: in, Path: path, Message: message}
+	}
+
+	response, ok := op.Responses[strconv.Itoa(rec.status)]
+	if !ok {
+		return []Mismatch{mismatch("response", "", fmt.Sprintf("status %d is not documented", rec.status))}
+	}
+	if len(response.Content) == 0 || rec.body.Len() == 0 {
+		return nil
+	}
+
+	contentType := rec.header.Get("Content-Type")
+	if contentType == "" {
+		// The server sniffs the content type of responses without one
+		contentType = http.DetectContentType(rec.body.Bytes())
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	content, ok := response.Content[mediaType]
+	if !ok {
+		return []Mismatch{mismatch("response content type", "", fmt.Sprintf("%q is not one of %s", contentType, mediaTypesOf(response.Content)))}
+	}
+	if !isJSONMediaType(mediaType) {
+		return nil
+	}
+	return c.checkBody(content.Schema, rec.body.Bytes(), func(path, message string) Mismatch {
+		return mismatch("response body", path, message)
+	})
+}
+
+// checkRequest checks the query parameters and JSON body of a request
+func (c *contract) checkRequest(route string, op *Operation, r *http.Request, body []byte, status int) []Mismatch {
+	mismatch := func(in, path, message string) Mismatch {
+		return Mismatch{Route: route, Status: status, In: in, Path: path, Message: message}
+	}
+
+	var mismatches []Mismatch
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		if p.In != "query" {
+			continue
+		}
+		in := "query parameter " + p.Name
+		if !query.Has(p.Name) {
+			if p.Required {
+				mismatches = append(mismatches, mismatch(in, "", "is required"))
+			}
+			continue
+		}
+		value, ok := queryValue(c.doc.Resolve(p.Schema), query.Get(p.Name))
+		if !ok {
+			mismatches = append(mismatches, mismatch(in, "", fmt.Sprintf("%q is not %s", query.Get(p.Name), strings.Join(p.Schema.Type, " or "))))
+			continue
+		}
+		for _, err := range c.doc.ValidateValue(p.Schema, value) {
+			mismatches = append(mismatches, mismatch(in, "", err.Message))
+		}
+	}
+
+	if op.RequestBody == nil {
+		return mismatches
+	}
+	if len(body) == 0 && r.ContentLength == 0 {
+		if op.RequestBody.Required {
+			mismatches = append(mismatches, mismatch("request body", "", "is required"))
+		}
+		return mismatches
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	content, ok := op.RequestBody.Content[mediaType]
+	if !ok {
+		return append(mismatches, mismatch("request content type", "", fmt.Sprintf("%q is not one of %s", contentType, mediaTypesOf(op.RequestBody.Content))))
+	}
+	if !isJSONMediaType(mediaType) {
+		return mismatches
+	}
+	return append(mismatches, c.checkBody(content.Schema, body, func(path, message string) Mismatch {
+		return mismatch("request body", path, message)
+	})...)
+}
+
+// checkBody checks a JSON body against a schema
+func (c *contract) checkBody(s *Schema, body []byte, mismatch func(path, message string) Mismatch) []Mismatch {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []Mismatch{mismatch("", fmt.Sprintf("invalid JSON: %v", err))}
+	}
+
+	var mismatches []Mismatch
+	for _, err := range c.doc.ValidateValue(s, value) {
+		mismatches = append(mismatches, mismatch(err.Path, err.Message))
+	}
+	return mismatches
+}
+
+// queryValue converts a query parameter to the JSON value its schema describes
+func queryValue(s *Schema, raw string) (interface{}, bool) {
+	switch {
+	case s == nil:
+		return raw, true
+	case s.Type.Has("integer"):
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, false
+		}
+		return json.Number(raw), true
+	case s.Type.Has("number"):
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, false
+		}
+		return json.Number(raw), true
+	case s.Type.Has("boolean"):
+		b, err := strconv.ParseBool(raw)
+		return b, err == nil
+	}
+	return raw, true
+}
+
+// respondWithMismatches replaces a response with a 500 error listing the mismatches
+func respondWithMismatches(w http.ResponseWriter, mismatches []Mismatch) {
+	details := make([]validation.FieldError, len(mismatches))
+	for i, m := range mismatches {
+		field := m.In
+		if m.Path != "" {
+			field += " " + m.Path
+		}
+		details[i] = validation.FieldError{Field: field, Message: m.Message}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(struct {
+		Error   string                  `json:"error"`
+		Details []validation.FieldError `json:"details"`
+	}{"Request or response does not match the API contract", details})
+}
+
+// respondWithError sends an error response in the format of the API
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{message})
+}
+
+// mediaTypesOf lists the media types of a content map
+func mediaTypesOf(content map[string]*MediaType) string {
+	types := make([]string, 0, len(content))
+	for mt := range content {
+		types = append(types, mt)
+	}
+	sort.Strings(types)
+	return strings.Join(types, ", ")
+}
+
+// isJSONMediaType reports whether a Content-Type header is JSON
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// bufferedResponse holds a response until it has been checked
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// Header returns the response headers
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+// WriteHeader records the status of the response
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.status, b.wroteHeader = status, true
+}
+
+// Write buffers the response body
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+// writeTo sends the buffered response
+func (b *bufferedResponse) writeTo(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
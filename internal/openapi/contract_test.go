@@ -0,0 +1,278 @@
+package openapi
+
+import (
+	"bisnode/docs"
+	"bisnode/internal/binding"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testDocument documents GET and POST /items
+func testDocument() *Document {
+	ints := func(n int) *int { return &n }
+	errorResponse := &Response{
+		Description: "Bad Request",
+		Content:     map[string]*MediaType{"application/json": {Schema: &Schema{Ref: RefPrefix + "Error"}}},
+	}
+	return &Document{
+		OpenAPI: "3.1.0",
+		Paths: map[string]PathItem{
+			"/items/{id}": {
+				"get": {
+					OperationID: "getItem",
+					Parameters: []*Parameter{
+						{Name: "id", In: "path", Required: true, Schema: &Schema{Type: Types{"string"}}},
+						{Name: "limit", In: "query", Schema: &Schema{Type: Types{"integer"}}},
+					},
+					Responses: map[string]*Response{
+						"200": {Description: "OK", Content: map[string]*MediaType{"application/json": {Schema: &Schema{Ref: RefPrefix + "Item"}}}},
+						"400": errorResponse,
+					},
+				},
+			},
+			"/items": {
+				"post": {
+					OperationID: "postItem",
+					RequestBody: &RequestBody{
+						Required: true,
+						Content: map[string]*MediaType{"application/json": {Schema: &Schema{
+							Type:       Types{"object"},
+							Properties: map[string]*Schema{"name": {Type: Types{"string"}, MaxLength: ints(5)}},
+							Required:   []string{"name"},
+						}}},
+					},
+					Responses: map[string]*Response{
+						"201": {Description: "Created", Content: map[string]*MediaType{"application/json": {Schema: &Schema{Ref: RefPrefix + "Item"}}}},
+						"400": errorResponse,
+					},
+				},
+			},
+		},
+		Components: Components{Schemas: map[string]*Schema{
+			"Item": {
+				Type: Types{"object"},
+				Properties: map[string]*Schema{
+					"id":     {Type: Types{"integer"}},
+					"status": {Type: Types{"string"}, Enum: []string{"new", "done"}},
+					"owner":  {AnyOf: []*Schema{{Ref: RefPrefix + "Owner"}, {Type: Types{"null"}}}},
+					"tags":   {Type: Types{"array"}, Items: &Schema{Type: Types{"string"}}},
+				},
+				Required: []string{"id", "status", "owner"},
+			},
+			"Owner": {
+				Type:       Types{"object"},
+				Properties: map[string]*Schema{"name": {Type: Types{"string"}}},
+				Required:   []string{"name"},
+			},
+			"Error": {
+				Type:       Types{"object"},
+				Properties: map[string]*Schema{"error": {Type: Types{"string"}}},
+				Required:   []string{"error"},
+			},
+		}},
+	}
+}
+
+// respond returns a handler that responds with status, content type and body
+func respond(status int, contentType, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	})
+}
+
+func TestValidateContract(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		handler http.Handler
+		want    []string
+	}{
+		{
+			name:    "matching response",
+			method:  http.MethodGet,
+			target:  "/items/1?limit=2",
+			handler: respond(200, "application/json", `{"id": 1, "status": "new", "owner": {"name": "Ola"}, "tags": ["a"]}`),
+		},
+		{
+			name:    "null owner",
+			method:  http.MethodGet,
+			target:  "/items/1",
+			handler: respond(200, "application/json; charset=utf-8", `{"id": 1, "status": "done", "owner": null}`),
+		},
+		{
+			name:    "invalid response body",
+			method:  http.MethodGet,
+			target:  "/items/1",
+			handler: respond(200, "application/json", `{"id": 1.5, "status": "lost", "owner": {}, "tags": [1]}`),
+			want: []string{
+				"response body $.id: is number, want integer",
+				"response body $.owner: does not match any of the allowed schemas",
+				`response body $.status: "lost" is not one of new, done`,
+				"response body $.tags[0]: is integer, want string",
+			},
+		},
+		{
+			name:    "missing field",
+			method:  http.MethodGet,
+			target:  "/items/1",
+			handler: respond(200, "application/json", `{"id": 1, "owner": null}`),
+			want:    []string{"response body $.status: is required"},
+		},
+		{
+			name:    "plain text error",
+			method:  http.MethodGet,
+			target:  "/items/1",
+			handler: respond(400, "text/plain; charset=utf-8", "bad request\n"),
+			want:    []string{`response content type: "text/plain; charset=utf-8" is not one of application/json`},
+		},
+		{
+			name:    "undocumented status",
+			method:  http.MethodGet,
+			target:  "/items/1",
+			handler: respond(500, "application/json", `{"error": "failed"}`),
+			want:    []string{"response: status 500 is not documented"},
+		},
+		{
+			name:    "invalid request accepted",
+			method:  http.MethodPost,
+			target:  "/items",
+			body:    `{"name": "too long"}`,
+			handler: respond(201, "application/json", `{"id": 1, "status": "new", "owner": null}`),
+			want:    []string{"request body $.name: must be at most 5 characters"},
+		},
+		{
+			name:    "invalid request rejected",
+			method:  http.MethodPost,
+			target:  "/items",
+			body:    `{"name": "too long"}`,
+			handler: respond(400, "application/json", `{"error": "name is too long"}`),
+		},
+		{
+			name:    "invalid query parameter accepted",
+			method:  http.MethodGet,
+			target:  "/items/1?limit=ten",
+			handler: respond(200, "application/json", `{"id": 1, "status": "new", "owner": null}`),
+			want:    []string{`query parameter limit: "ten" is not integer`},
+		},
+		{
+			name:    "undocumented route",
+			method:  http.MethodGet,
+			target:  "/swagger/index.html",
+			handler: respond(200, "text/html", "<html></html>"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			api := ValidateContract(tt.handler, testDocument(), ContractOptions{
+				Report: func(m Mismatch) {
+					where := m.In
+					if m.Path != "" {
+						where += " " + m.Path
+					}
+					got = append(got, where+": "+m.Message)
+				},
+			})
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			api.ServeHTTP(httptest.NewRecorder(), req)
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("mismatches:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestValidateContractFail(t *testing.T) {
+	handler := respond(200, "application/json", `{"id": "1"}`)
+
+	api := ValidateContract(handler, testDocument(), ContractOptions{Fail: true, Report: func(Mismatch) {}})
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	var resp struct {
+		Error   string `json:"error"`
+		Details []struct {
+			Field   string `json:"field"`
+			Message string `json:"message"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == "" || len(resp.Details) != 3 {
+		t.Errorf("details = %+v, want the missing owner and status and the string id", resp.Details)
+	}
+
+	// Matching responses pass through unchanged
+	api = ValidateContract(respond(200, "application/json", `{"id": 1, "status": "new", "owner": null}`), testDocument(), ContractOptions{Fail: true})
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status": "new"`) {
+		t.Errorf("response = %d %s, want the handler's response", rec.Code, rec.Body)
+	}
+}
+
+func TestValidateContractBodyLimit(t *testing.T) {
+	called := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		respond(201, "application/json", `{"id": 1, "status": "new", "owner": null}`).ServeHTTP(w, r)
+	})
+	api := ValidateContract(handler, testDocument(), ContractOptions{Fail: true})
+
+	padding := strings.Repeat(" ", binding.DefaultMaxBodyBytes)
+	for _, tt := range []struct {
+		name string
+		body string
+		want int
+	}{
+		{"within limit", `{"name": "Ola"}`, http.StatusCreated},
+		{"too large", `{"name": "Ola"}` + padding, http.StatusRequestEntityTooLarge},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", rec.Code, rec.Body, tt.want)
+			}
+			if wantCalled := tt.want != http.StatusRequestEntityTooLarge; called != wantCalled {
+				t.Errorf("handler called = %v, want %v", called, wantCalled)
+			}
+		})
+	}
+}
+
+func TestParseEmbeddedDocument(t *testing.T) {
+	doc, err := Parse(docs.OpenAPI)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	op := doc.Paths["/api/v1/motor-vehicles/search"]["get"]
+	if op == nil {
+		t.Fatal("GET /api/v1/motor-vehicles/search is not documented")
+	}
+	schema := doc.Resolve(op.Responses["500"].Content["application/json"].Schema)
+	if schema == nil || !contains(schema.Required, "error") {
+		t.Errorf("500 response schema = %+v, want the JSON error response", schema)
+	}
+}
@@ -0,0 +1,250 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// annotatedOperation is an operation as written in the swaggo annotations of a handler
+type annotatedOperation struct {
+	// handler is the name of the function the annotations belong to
+	handler string
+	// id is the operation ID given with @ID
+	id          string
+	path        string
+	method      string
+	summary     string
+	description string
+	tags        []string
+	accept      []string
+	produce     []string
+	params      []annotatedParam
+	responses   []annotatedResponse
+	security    []string
+}
+
+// annotatedParam is an @Param annotation
+type annotatedParam struct {
+	name        string
+	in          string
+	typeName    string
+	required    bool
+	description string
+}
+
+// annotatedResponse is an @Success or @Failure annotation
+type annotatedResponse struct {
+	status string
+	// kind is object, array or string
+	kind        string
+	typeName    string
+	description string
+}
+
+// annotatedInfo holds the general API annotations of the main package
+type annotatedInfo struct {
+	title       string
+	version     string
+	description string
+	basicAuth   []string
+}
+
+var (
+	paramPattern    = regexp.MustCompile(`^(\S+)\s+(query|path|body)\s+(\S+)\s+(true|false)\s+"(.*)"$`)
+	responsePattern = regexp.MustCompile(`^(\d{3})\s+\{(object|array|string)\}\s+(\S+)(?:\s+"(.*)")?$`)
+	routerPattern   = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]$`)
+)
+
+// mediaTypes maps the short swaggo media type names to media types
+var mediaTypes = map[string]string{
+	"json":  "application/json",
+	"plain": "text/plain",
+	"csv":   "text/csv",
+}
+
+// parseOperations reads the annotated operations of the Go files in dir
+func parseOperations(dir string) ([]*annotatedOperation, error) {
+	files, err := parseDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []*annotatedOperation
+	for _, file := range files {
+		for _, group := range file.Comments {
+			lines := annotations(group)
+			if len(lines) == 0 {
+				continue
+			}
+			op, err := parseOperation(lines)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file.Name.Name, err)
+			}
+			if op == nil {
+				continue
+			}
+			// Operations sharing a handler are annotated in separate comment groups
+			// above it, so the handler is the first function after the group
+			if op.handler = nextFunc(file, group.End()); op.handler == "" {
+				return nil, fmt.Errorf("%s %s: no handler after the annotations", op.method, op.path)
+			}
+			operations = append(operations, op)
+		}
+	}
+	return operations, nil
+}
+
+// parseInfo reads the general API annotations of the Go files in dir
+func parseInfo(dir string) (*annotatedInfo, error) {
+	files, err := parseDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &annotatedInfo{}
+	for _, file := range files {
+		for _, group := range file.Comments {
+			for _, line := range annotations(group) {
+				key, value := splitAnnotation(line)
+				switch key {
+				case "@title":
+					info.title = value
+				case "@version":
+					info.version = value
+				case "@description":
+					info.description = value
+				case "@securityDefinitions.basic":
+					info.basicAuth = append(info.basicAuth, value)
+				}
+			}
+		}
+	}
+	if info.title == "" || info.version == "" {
+		return nil, fmt.Errorf("%s: missing @title or @version", dir)
+	}
+	return info, nil
+}
+
+// parseDir parses the non-test Go files in dir with their comments, in file name order
+func parseDir(dir string) ([]*ast.File, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// annotations returns the lines of a comment group that start with @
+func annotations(group *ast.CommentGroup) []string {
+	var lines []string
+	for _, line := range strings.Split(group.Text(), "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "@") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// splitAnnotation splits an annotation line into its key and value
+func splitAnnotation(line string) (string, string) {
+	key, value, _ := strings.Cut(line, " ")
+	return key, strings.TrimSpace(value)
+}
+
+// parseOperation parses the annotations of one operation, or returns nil when
+// the lines have no @Router
+func parseOperation(lines []string) (*annotatedOperation, error) {
+	op := &annotatedOperation{}
+	for _, line := range lines {
+		key, value := splitAnnotation(line)
+		switch key {
+		case "@ID":
+			op.id = value
+		case "@Summary":
+			op.summary = value
+		case "@Description":
+			op.description = value
+		case "@Tags":
+			for _, tag := range strings.Split(value, ",") {
+				op.tags = append(op.tags, strings.TrimSpace(tag))
+			}
+		case "@Accept":
+			op.accept = append(op.accept, mediaType(value))
+		case "@Produce":
+			op.produce = append(op.produce, mediaType(value))
+		case "@Param":
+			m := paramPattern.FindStringSubmatch(value)
+			if m == nil {
+				return nil, fmt.Errorf("invalid annotation %q", line)
+			}
+			op.params = append(op.params, annotatedParam{
+				name:        m[1],
+				in:          m[2],
+				typeName:    m[3],
+				required:    m[4] == "true",
+				description: m[5],
+			})
+		case "@Success", "@Failure":
+			m := responsePattern.FindStringSubmatch(value)
+			if m == nil {
+				return nil, fmt.Errorf("invalid annotation %q", line)
+			}
+			op.responses = append(op.responses, annotatedResponse{
+				status:      m[1],
+				kind:        m[2],
+				typeName:    m[3],
+				description: m[4],
+			})
+		case "@Router":
+			m := routerPattern.FindStringSubmatch(value)
+			if m == nil {
+				return nil, fmt.Errorf("invalid annotation %q", line)
+			}
+			op.path, op.method = m[1], strings.ToLower(m[2])
+		case "@Security":
+			op.security = append(op.security, value)
+		}
+	}
+	if op.path == "" {
+		return nil, nil
+	}
+	return op, nil
+}
+
+// mediaType returns the media type of a swaggo media type name
+func mediaType(name string) string {
+	if t, ok := mediaTypes[name]; ok {
+		return t
+	}
+	return name
+}
+
+// nextFunc returns the name of the first function declared after pos
+func nextFunc(file *ast.File, pos token.Pos) string {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Pos() > pos {
+			return fn.Name.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,287 @@
+// Package generator generates the OpenAPI 3.1 document of the API.
+// Operations are read from the swaggo annotations of the handlers, and
+// request and response schemas are reflected from the Go types the handlers
+// bind and encode, so the document describes what the handlers actually do.
+package generator
+
+import (
+	"bisnode/internal/openapi"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Directories holding the annotations, relative to the module root
+const (
+	mainDir     = "cmd/api"
+	handlersDir = "internal/handlers"
+)
+
+// Generate generates the OpenAPI document from the source of the module at root
+func Generate(root string) (*openapi.Document, error) {
+	info, err := parseInfo(filepath.Join(root, mainDir))
+	if err != nil {
+		return nil, err
+	}
+	operations, err := parseOperations(filepath.Join(root, handlersDir))
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSchemas()
+	if s.fields, err = fieldOverrides(s); err != nil {
+		return nil, err
+	}
+
+	doc := &openapi.Document{
+		OpenAPI: "3.1.0",
+		Info: openapi.Info{
+			Title:       info.title,
+			Description: info.description,
+			Version:     info.version,
+		},
+		Paths: make(map[string]openapi.PathItem),
+		Components: openapi.Components{
+			SecuritySchemes: make(map[string]*openapi.SecurityScheme),
+		},
+	}
+	for _, name := range info.basicAuth {
+		doc.Components.SecuritySchemes[name] = &openapi.SecurityScheme{Type: "http", Scheme: "basic"}
+	}
+
+	ids := make(map[string]bool)
+	for _, op := range operations {
+		operation, err := buildOperation(s, op, operations)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(op.method), op.path, err)
+		}
+		if ids[operation.OperationID] {
+			return nil, fmt.Errorf("%s %s: duplicate operation ID %s; set one with @ID", strings.ToUpper(op.method), op.path, operation.OperationID)
+		}
+		ids[operation.OperationID] = true
+		for _, name := range op.security {
+			if _, ok := doc.Components.SecuritySchemes[name]; !ok {
+				return nil, fmt.Errorf("%s %s: unknown security scheme %s", strings.ToUpper(op.method), op.path, name)
+			}
+		}
+
+		item := doc.Paths[op.path]
+		if item == nil {
+			item = make(openapi.PathItem)
+			doc.Paths[op.path] = item
+		}
+		if _, ok := item[op.method]; ok {
+			return nil, fmt.Errorf("%s %s is annotated twice", strings.ToUpper(op.method), op.path)
+		}
+		item[op.method] = operation
+	}
+
+	doc.Components.Schemas = s.components
+	return doc, nil
+}
+
+// buildOperation converts annotations into an operation. The operation ID
+// defaults to the method and handler name, and query parameters take their
+// constraints from the request type bound by the same handler.
+func buildOperation(s *schemas, op *annotatedOperation, all []*annotatedOperation) (*openapi.Operation, error) {
+	id := op.id
+	if id == "" {
+		id = op.method + op.handler
+	}
+	operation := &openapi.Operation{
+		OperationID: id,
+		Summary:     op.summary,
+		Description: op.description,
+		Tags:        op.tags,
+		Responses:   make(map[string]*openapi.Response),
+	}
+	for _, name := range op.security {
+		operation.Security = append(operation.Security, openapi.SecurityRequirement{name: {}})
+	}
+
+	fields := queryFields(handlerRequestType(op, all))
+	for _, p := range op.params {
+		if p.in == "body" {
+			body, err := buildRequestBody(s, op, p)
+			if err != nil {
+				return nil, err
+			}
+			operation.RequestBody = body
+			continue
+		}
+
+		param := &openapi.Parameter{
+			Name:        p.name,
+			In:          p.in,
+			Description: p.description,
+			// Path parameters are always required
+			Required:   p.required || p.in == "path",
+			Deprecated: strings.Contains(strings.ToLower(p.description), "deprecated"),
+		}
+		if f, ok := fields[p.name]; ok && p.in == "query" {
+			schema, err := s.schema(f.Type, requestUsage)
+			if err != nil {
+				return nil, err
+			}
+			param.Schema = withTags(schema, f)
+		} else {
+			schema, err := primitive(p.typeName)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", p.name, err)
+			}
+			param.Schema = schema
+		}
+		operation.Parameters = append(operation.Parameters, param)
+	}
+
+	for _, r := range op.responses {
+		response, err := buildResponse(s, op, r)
+		if err != nil {
+			return nil, err
+		}
+		operation.Responses[r.status] = response
+	}
+	return operation, nil
+}
+
+// buildRequestBody returns the request body of a body parameter. JSON media
+// types are described by the parameter's type, others as plain strings.
+func buildRequestBody(s *schemas, op *annotatedOperation, p annotatedParam) (*openapi.RequestBody, error) {
+	body := &openapi.RequestBody{
+		Description: p.description,
+		Required:    p.required,
+		Content:     make(map[string]*openapi.MediaType),
+	}
+
+	var schema *openapi.Schema
+	if p.typeName != "string" {
+		var err error
+		if schema, err = s.named(p.typeName, requestUsage); err != nil {
+			return nil, err
+		}
+	}
+
+	accept := op.accept
+	if len(accept) == 0 {
+		accept = []string{"application/json"}
+	}
+	for _, mt := range accept {
+		if isJSON(mt) && schema != nil {
+			body.Content[mt] = &openapi.MediaType{Schema: schema}
+		} else {
+			body.Content[mt] = &openapi.MediaType{Schema: &openapi.Schema{Type: openapi.Types{"string"}}}
+		}
+	}
+	return body, nil
+}
+
+// buildResponse returns a response. Errors are always JSON error responses;
+// successful responses are described by their type for JSON media types and
+// as plain strings otherwise.
+func buildResponse(s *schemas, op *annotatedOperation, r annotatedResponse) (*openapi.Response, error) {
+	code, _ := strconv.Atoi(r.status)
+	response := &openapi.Response{
+		Description: r.description,
+		Content:     make(map[string]*openapi.MediaType),
+	}
+	if response.Description == "" {
+		response.Description = http.StatusText(code)
+	}
+
+	var schema *openapi.Schema
+	if r.kind != "string" {
+		var err error
+		if schema, err = s.named(r.typeName, responseUsage); err != nil {
+			return nil, err
+		}
+		if r.kind == "array" {
+			schema = &openapi.Schema{Type: openapi.Types{"array"}, Items: schema}
+		}
+	}
+
+	if code >= 400 {
+		if schema == nil {
+			return nil, fmt.Errorf("response %d must be an error object", code)
+		}
+		response.Content["application/json"] = &openapi.MediaType{Schema: schema}
+		return response, nil
+	}
+
+	produce := op.produce
+	if len(produce) == 0 {
+		produce = []string{"application/json"}
+	}
+	for _, mt := range produce {
+		if isJSON(mt) && schema != nil {
+			response.Content[mt] = &openapi.MediaType{Schema: schema}
+		} else {
+			response.Content[mt] = &openapi.MediaType{Schema: &openapi.Schema{Type: openapi.Types{"string"}}}
+		}
+	}
+	return response, nil
+}
+
+// handlerRequestType returns the body type of the operations served by the
+// same handler as op, which the handler also binds the query string into
+func handlerRequestType(op *annotatedOperation, all []*annotatedOperation) reflect.Type {
+	for _, other := range all {
+		if other.handler != op.handler {
+			continue
+		}
+		for _, p := range other.params {
+			if p.in != "body" || p.typeName == "string" {
+				continue
+			}
+			if t, err := lookupType(p.typeName); err == nil {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// queryFields returns the fields of a request type by their query parameter names
+func queryFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	if t == nil {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for name, embedded := range queryFields(f.Type) {
+				fields[name] = embedded
+			}
+			continue
+		}
+		for _, name := range strings.Split(f.Tag.Get("query"), ",") {
+			if name != "" {
+				fields[name] = f
+			}
+		}
+	}
+	return fields
+}
+
+// primitive returns the schema of a parameter type name
+func primitive(name string) (*openapi.Schema, error) {
+	switch name {
+	case "string":
+		return &openapi.Schema{Type: openapi.Types{"string"}}, nil
+	case "int", "integer":
+		return &openapi.Schema{Type: openapi.Types{"integer"}}, nil
+	case "number", "float":
+		return &openapi.Schema{Type: openapi.Types{"number"}}, nil
+	case "bool", "boolean":
+		return &openapi.Schema{Type: openapi.Types{"boolean"}}, nil
+	}
+	return nil, fmt.Errorf("unsupported parameter type %s", name)
+}
+
+// isJSON reports whether a media type is JSON
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,182 @@
+package generator
+
+import (
+	"bisnode/internal/openapi"
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// root is the module root relative to this package
+const root = "../../.."
+
+func TestDocumentIsUpToDate(t *testing.T) {
+	doc, err := Generate(root)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	got, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile(filepath.Join(root, "docs", "openapi.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(append(got, '\n'), want) {
+		t.Error("docs/openapi.json is out of date; run go generate ./docs")
+	}
+}
+
+func TestRoutesMatchDocument(t *testing.T) {
+	doc, err := Generate(root)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	registered := registeredRoutes(t, filepath.Join(root, "internal", "routes"))
+	for route := range registered {
+		if !documented[route] {
+			t.Errorf("route %s is registered but not annotated with @Router", route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			t.Errorf("operation %s is documented but not registered in internal/routes", route)
+		}
+	}
+}
+
+// registeredRoutes returns the patterns passed to mux.HandleFunc and
+// mux.Handle in the Go files of dir
+func registeredRoutes(t *testing.T, dir string) map[string]bool {
+	t.Helper()
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, name := range names {
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "HandleFunc" && sel.Sel.Name != "Handle") {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Errorf("%s: route pattern is not a string literal", fset.Position(call.Pos()))
+				return true
+			}
+			pattern, _ := strconv.Unquote(lit.Value)
+			method, path, ok := strings.Cut(pattern, " ")
+			if !ok {
+				t.Errorf("%s: route %q has no method", fset.Position(call.Pos()), pattern)
+				return true
+			}
+			routes[method+" "+path] = true
+			return true
+		})
+	}
+	if len(routes) == 0 {
+		t.Fatalf("no routes found in %s", dir)
+	}
+	return routes
+}
+
+func TestSchemaRequiredFields(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	type response struct {
+		ID        string     `json:"id"`
+		Note      string     `json:"note,omitempty"`
+		Item      *item      `json:"item"`
+		DoneAt    *time.Time `json:"doneAt"`
+		Skipped   string     `json:"-"`
+		Anonymous struct {
+			Count int `json:"count"`
+		} `json:"anonymous"`
+	}
+	type request struct {
+		Query string `json:"query" validate:"required,max=10" example:"abc"`
+		Mode  string `json:"mode,omitempty" validate:"oneof=a b"`
+		Limit int    `json:"limit,omitempty" validate:"min=1"`
+	}
+
+	s := newSchemas()
+	if _, err := s.schema(reflect.TypeOf(response{}), responseUsage); err != nil {
+		t.Fatalf("schema(response) error = %v", err)
+	}
+	if _, err := s.schema(reflect.TypeOf(request{}), requestUsage); err != nil {
+		t.Fatalf("schema(request) error = %v", err)
+	}
+
+	resp := s.components["generator.response"]
+	if got, want := sorted(resp.Required), []string{"anonymous", "doneAt", "id", "item"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("response required = %v, want %v", got, want)
+	}
+	if _, ok := resp.Properties["-"]; ok {
+		t.Error("response has a property for a field tagged json:\"-\"")
+	}
+	if got := resp.Properties["item"].AnyOf; len(got) != 2 || got[0].Ref != openapi.RefPrefix+"generator.item" || !got[1].Type.Has("null") {
+		t.Errorf("item = %+v, want a nullable reference", resp.Properties["item"])
+	}
+	if got := resp.Properties["doneAt"]; !got.Type.Has("string") || !got.Type.Has("null") || got.Format != "date-time" {
+		t.Errorf("doneAt = %+v, want a nullable date-time string", got)
+	}
+	if got := resp.Properties["anonymous"]; got.Ref != "" || got.Properties["count"] == nil {
+		t.Errorf("anonymous = %+v, want an inline object", got)
+	}
+
+	req := s.components["generator.request"]
+	if got, want := req.Required, []string{"query"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("request required = %v, want %v", got, want)
+	}
+	if got := req.Properties["query"]; got.MaxLength == nil || *got.MaxLength != 10 || !reflect.DeepEqual(got.Examples, []interface{}{"abc"}) {
+		t.Errorf("query = %+v, want maxLength 10 and example abc", got)
+	}
+	if got := req.Properties["mode"].Enum; !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("mode enum = %v, want [a b]", got)
+	}
+	if got := req.Properties["limit"].Minimum; got == nil || *got != 1 {
+		t.Errorf("limit minimum = %v, want 1", got)
+	}
+
+	if _, err := s.schema(reflect.TypeOf(item{}), requestUsage); err == nil {
+		t.Error("schema() accepted a type used in both requests and responses")
+	}
+}
+
+// sorted returns a sorted copy of names
+func sorted(names []string) []string {
+	out := append([]string(nil), names...)
+	sort.Strings(out)
+	return out
+}
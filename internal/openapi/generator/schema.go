@@ -0,0 +1,269 @@
+package generator
+
+import (
+	"bisnode/internal/openapi"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// usage is whether a type is decoded from requests or encoded in responses,
+// which decides the fields a schema requires
+type usage int
+
+const (
+	// requestUsage requires the fields validated as required
+	requestUsage usage = iota + 1
+	// responseUsage requires the fields that are always encoded, those without omitempty
+	responseUsage
+)
+
+// schemas reflects Go types into component schemas
+type schemas struct {
+	components map[string]*openapi.Schema
+	usages     map[string]usage
+	// fields replaces the schema of a field, keyed by component name and JSON field name
+	fields map[string]*openapi.Schema
+}
+
+// newSchemas creates an empty set of component schemas
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*openapi.Schema),
+		usages:     make(map[string]usage),
+		fields:     make(map[string]*openapi.Schema),
+	}
+}
+
+// componentName returns the component name of a named type, such as "domain.SearchResult"
+func componentName(t reflect.Type) string {
+	return t.String()
+}
+
+// schema returns the schema of t, registering named structs as components
+func (s *schemas) schema(t reflect.Type, u usage) (*openapi.Schema, error) {
+	switch t {
+	case timeType:
+		return &openapi.Schema{Type: openapi.Types{"string"}, Format: "date-time"}, nil
+	case rawJSONType:
+		return &openapi.Schema{}, nil
+	}
+
+	var schema *openapi.Schema
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.schema(t.Elem(), u)
+	case reflect.String:
+		schema = &openapi.Schema{Type: openapi.Types{"string"}, Enum: enums[t]}
+	case reflect.Bool:
+		schema = &openapi.Schema{Type: openapi.Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema = &openapi.Schema{Type: openapi.Types{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema = &openapi.Schema{Type: openapi.Types{"integer"}, Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		schema = &openapi.Schema{Type: openapi.Types{"number"}}
+	case reflect.Slice, reflect.Array:
+		items, err := s.schema(t.Elem(), u)
+		if err != nil {
+			return nil, err
+		}
+		schema = &openapi.Schema{Type: openapi.Types{"array"}, Items: items}
+	case reflect.Map:
+		values, err := s.schema(t.Elem(), u)
+		if err != nil {
+			return nil, err
+		}
+		schema = &openapi.Schema{Type: openapi.Types{"object"}, AdditionalProperties: values}
+	case reflect.Interface:
+		schema = &openapi.Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t, "", u)
+		}
+		return s.component(t, u)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+	return schema, nil
+}
+
+// component registers a named struct as a component and returns a reference to it
+func (s *schemas) component(t reflect.Type, u usage) (*openapi.Schema, error) {
+	name := componentName(t)
+	ref := &openapi.Schema{Ref: openapi.RefPrefix + name}
+
+	if existing, ok := s.usages[name]; ok {
+		if existing != u {
+			return nil, fmt.Errorf("%s is used in both requests and responses", name)
+		}
+		return ref, nil
+	}
+
+	// Register the usage before reflecting the fields, so recursive types end
+	s.usages[name] = u
+	object, err := s.object(t, name, u)
+	if err != nil {
+		return nil, err
+	}
+	s.components[name] = object
+	return ref, nil
+}
+
+// object returns the schema of a struct's JSON fields
+func (s *schemas) object(t reflect.Type, name string, u usage) (*openapi.Schema, error) {
+	object := &openapi.Schema{Type: openapi.Types{"object"}, Properties: make(map[string]*openapi.Schema)}
+	if err := s.addFields(object, t, name, u); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// addFields adds the JSON fields of t to object, including those of embedded structs
+func (s *schemas) addFields(object *openapi.Schema, t reflect.Type, name string, u usage) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldName, opts, _ := strings.Cut(tag, ",")
+
+		// Embedded structs without a name of their own are flattened, as encoding/json does
+		if f.Anonymous && fieldName == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := s.addFields(object, ft, name, u); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if fieldName == "" {
+			fieldName = f.Name
+		}
+		omitempty := strings.Contains(","+opts+",", ",omitempty,")
+
+		field, ok := s.fields[name+"."+fieldName]
+		if !ok {
+			var err error
+			if field, err = s.schema(f.Type, u); err != nil {
+				return fmt.Errorf("%s.%s: %w", t, f.Name, err)
+			}
+		}
+		field = withTags(field, f)
+
+		switch u {
+		case requestUsage:
+			if hasRule(f.Tag.Get("validate"), "required") {
+				object.Required = append(object.Required, fieldName)
+			}
+		case responseUsage:
+			if !omitempty {
+				object.Required = append(object.Required, fieldName)
+				// Nil pointers are encoded as null unless omitted
+				if f.Type.Kind() == reflect.Pointer {
+					field = nullable(field)
+				}
+			}
+		}
+		object.Properties[fieldName] = field
+	}
+	return nil
+}
+
+// withTags returns a copy of schema with the constraints of the field's
+// validate tag and the examples of its example tag
+func withTags(schema *openapi.Schema, f reflect.StructField) *openapi.Schema {
+	validate, example := f.Tag.Get("validate"), f.Tag.Get("example")
+	if validate == "" && example == "" || schema.Ref != "" {
+		return schema
+	}
+
+	out := *schema
+	for _, rule := range strings.Split(validate, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "oneof":
+			out.Enum = strings.Fields(value)
+		case "min", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			switch {
+			case out.Type.Has("string") && key == "min":
+				out.MinLength = &n
+			case out.Type.Has("string"):
+				out.MaxLength = &n
+			case (out.Type.Has("integer") || out.Type.Has("number")) && key == "min":
+				out.Minimum = float(n)
+			case out.Type.Has("integer") || out.Type.Has("number"):
+				out.Maximum = float(n)
+			}
+		}
+	}
+
+	if example != "" {
+		var value interface{} = example
+		switch {
+		case out.Type.Has("integer"):
+			if n, err := strconv.Atoi(example); err == nil {
+				value = n
+			}
+		case out.Type.Has("number"):
+			if n, err := strconv.ParseFloat(example, 64); err == nil {
+				value = n
+			}
+		case out.Type.Has("boolean"):
+			if b, err := strconv.ParseBool(example); err == nil {
+				value = b
+			}
+		}
+		out.Examples = []interface{}{value}
+	}
+	return &out
+}
+
+// hasRule reports whether a validate tag contains rule
+func hasRule(validate, rule string) bool {
+	for _, r := range strings.Split(validate, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable returns a schema that also accepts null
+func nullable(schema *openapi.Schema) *openapi.Schema {
+	if schema.Ref != "" {
+		return &openapi.Schema{AnyOf: []*openapi.Schema{schema, {Type: openapi.Types{"null"}}}}
+	}
+	if len(schema.Type) == 0 {
+		return schema
+	}
+	out := *schema
+	out.Type = append(append(openapi.Types{}, schema.Type...), "null")
+	return &out
+}
+
+// float returns a pointer to n as a float64
+func float(n int) *float64 {
+	f := float64(n)
+	return &f
+}
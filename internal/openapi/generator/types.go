@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"bisnode/internal/domain"
+	"bisnode/internal/handlers"
+	"bisnode/internal/jobs"
+	"bisnode/internal/openapi"
+	"bisnode/internal/services/bisnode"
+	"bisnode/internal/vehicleid"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// types holds the Go types named in the handler annotations. Names without a
+// package are handler types.
+var types = typesOf(
+	handlers.BatchRequest{},
+	handlers.BatchResponse{},
+	handlers.ErrorResponse{},
+	handlers.JobResults{},
+	handlers.MetricsResponse{},
+	handlers.ScreeningReport{},
+	handlers.ScreeningRequest{},
+	handlers.SearchNearbyRequest{},
+	handlers.SearchOrganizationRequest{},
+	handlers.SearchOrganizationsByNameRequest{},
+	handlers.SearchPersonRequest{},
+	handlers.SearchRequest{},
+	handlers.WashRequest{},
+	bisnode.Screening{},
+	domain.HistoryResult{},
+	domain.NearbyResult{},
+	domain.OrganizationSearchResult{},
+	domain.SearchResult{},
+	domain.VehicleSearchResult{},
+	domain.WashResult{},
+	jobs.Job{},
+)
+
+// enums holds the values of the string types that are enumerations
+var enums = map[reflect.Type][]string{
+	reflect.TypeOf(domain.ListingType("")): {
+		string(domain.ListingTypePerson), string(domain.ListingTypeCompany), string(domain.ListingTypeUnknown),
+	},
+	reflect.TypeOf(domain.Gender("")): {
+		string(domain.GenderMale), string(domain.GenderFemale), string(domain.GenderUnknown),
+	},
+	reflect.TypeOf(domain.Quality("")): {
+		string(domain.QualityHigh), string(domain.QualityMedium), string(domain.QualityLow), string(domain.QualityUnknown),
+	},
+	reflect.TypeOf(domain.Channel("")): {
+		string(domain.ChannelTelemarketing), string(domain.ChannelDirectMail), string(domain.ChannelHumanitarian),
+	},
+	reflect.TypeOf(domain.WashStatus("")): {
+		string(domain.WashContactable), string(domain.WashReserved), string(domain.WashNotFound), string(domain.WashInvalid), string(domain.WashError),
+	},
+	reflect.TypeOf(domain.TimelineKind("")): {
+		string(domain.TimelineAddress), string(domain.TimelinePhone),
+	},
+	reflect.TypeOf(jobs.Status("")): {
+		string(jobs.StatusQueued), string(jobs.StatusRunning), string(jobs.StatusCompleted), string(jobs.StatusCancelled), string(jobs.StatusFailed),
+	},
+	reflect.TypeOf(handlers.BatchItemStatus("")): {
+		string(handlers.BatchItemOK), string(handlers.BatchItemNotFound), string(handlers.BatchItemInvalid), string(handlers.BatchItemError),
+	},
+	reflect.TypeOf(bisnode.ScreeningStatus("")): {
+		string(bisnode.ScreeningRunning), string(bisnode.ScreeningCompleted),
+	},
+	reflect.TypeOf(vehicleid.Kind("")): {
+		string(vehicleid.KindStandardPlate), string(vehicleid.KindTrailerPlate), string(vehicleid.KindDiplomaticPlate),
+		string(vehicleid.KindPersonalizedPlate), string(vehicleid.KindVIN),
+	},
+}
+
+// fieldOverrides returns the schemas of fields whose Go type does not say
+// what they hold, keyed by component name and JSON field name
+func fieldOverrides(s *schemas) (map[string]*openapi.Schema, error) {
+	searchResult, err := s.schema(reflect.TypeOf(domain.SearchResult{}), responseUsage)
+	if err != nil {
+		return nil, err
+	}
+	vehicleResult, err := s.schema(reflect.TypeOf(domain.VehicleSearchResult{}), responseUsage)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]*openapi.Schema{
+		// A search result for orgno and mobile items, a vehicle result for plate and vin items
+		"handlers.BatchItemResult.result": {AnyOf: []*openapi.Schema{searchResult, vehicleResult}},
+		// The stored results are encoded batch item results
+		"handlers.JobResults.items": {
+			Type:  openapi.Types{"array"},
+			Items: &openapi.Schema{Ref: openapi.RefPrefix + componentName(reflect.TypeOf(handlers.BatchItemResult{}))},
+		},
+	}, nil
+}
+
+// lookupType returns the Go type of a type name in the handler annotations
+func lookupType(name string) (reflect.Type, error) {
+	if !strings.Contains(name, ".") {
+		name = "handlers." + name
+	}
+	t, ok := types[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %s; add it to the types of package openapi", name)
+	}
+	return t, nil
+}
+
+// named returns the schema of a type name in the handler annotations. The
+// name object stands for any JSON object.
+func (s *schemas) named(name string, u usage) (*openapi.Schema, error) {
+	if name == "object" {
+		return &openapi.Schema{Type: openapi.Types{"object"}}, nil
+	}
+	t, err := lookupType(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.schema(t, u)
+}
+
+// typesOf maps the component names of values to their types
+func typesOf(values ...interface{}) map[string]reflect.Type {
+	m := make(map[string]reflect.Type, len(values))
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		m[componentName(t)] = t
+	}
+	return m
+}
@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaError is a part of a value that does not match its schema
+type SchemaError struct {
+	// Path locates the part in the value, such as "$.result[0].name"
+	Path    string
+	Message string
+}
+
+// ValidateValue checks a decoded JSON value against a schema of the document.
+// Numbers must be decoded as json.Number, so that integers can be told apart.
+func (d *Document) ValidateValue(s *Schema, value interface{}) []SchemaError {
+	var errs []SchemaError
+	d.validate(s, value, "$", &errs)
+	return errs
+}
+
+// validate appends the errors of value at path to errs
+func (d *Document) validate(s *Schema, value interface{}, path string, errs *[]SchemaError) {
+	if s.Ref != "" {
+		resolved := d.Resolve(s)
+		if resolved == nil {
+			*errs = append(*errs, SchemaError{path, fmt.Sprintf("unknown schema %s", s.Ref)})
+			return
+		}
+		s = resolved
+	}
+
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for _, alternative := range s.AnyOf {
+			if len(d.ValidateValue(alternative, value)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*errs = append(*errs, SchemaError{path, "does not match any of the allowed schemas"})
+			return
+		}
+	}
+
+	if len(s.Type) > 0 && !s.Type.Has(jsonType(value)) && !(s.Type.Has("number") && jsonType(value) == "integer") {
+		*errs = append(*errs, SchemaError{path, fmt.Sprintf("is %s, want %s", jsonType(value), strings.Join(s.Type, " or "))})
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		if len(s.Enum) > 0 && !contains(s.Enum, v) {
+			*errs = append(*errs, SchemaError{path, fmt.Sprintf("%q is not one of %s", v, strings.Join(s.Enum, ", "))})
+		}
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			*errs = append(*errs, SchemaError{path, fmt.Sprintf("must be at least %d characters", *s.MinLength)})
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			*errs = append(*errs, SchemaError{path, fmt.Sprintf("must be at most %d characters", *s.MaxLength)})
+		}
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			*errs = append(*errs, SchemaError{path, fmt.Sprintf("invalid number %s", v)})
+			return
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			*errs = append(*errs, SchemaError{path, fmt.Sprintf("must be at least %v", *s.Minimum)})
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			*errs = append(*errs, SchemaError{path, fmt.Sprintf("must be at most %v", *s.Maximum)})
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				d.validate(s.Items, item, path+"["+strconv.Itoa(i)+"]", errs)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, SchemaError{path + "." + name, "is required"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				d.validate(property, v[name], path+"."+name, errs)
+			} else if s.AdditionalProperties != nil {
+				d.validate(s.AdditionalProperties, v[name], path+"."+name, errs)
+			}
+		}
+	}
+}
+
+// jsonType returns the JSON Schema type of a decoded JSON value
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// contains reports whether values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
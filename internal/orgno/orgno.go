@@ -0,0 +1,134 @@
+// Package orgno normalizes and validates Norwegian organization numbers as
+// issued by the Brønnøysund Register Centre.
+package orgno
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrEmpty is returned when no organization number was given
+	ErrEmpty = errors.New("organization number cannot be empty")
+	// ErrInvalidCharacters is returned when the number contains anything but digits and separators
+	ErrInvalidCharacters = errors.New("organization number may only contain digits")
+	// ErrInvalidLength is returned when the number does not have 9 digits
+	ErrInvalidLength = errors.New("organization number must have 9 digits")
+	// ErrInvalidPrefix is returned when the number does not start with 8 or 9
+	ErrInvalidPrefix = errors.New("organization number must start with 8 or 9")
+	// ErrInvalidChecksum is returned when the MOD11 check digit does not match
+	ErrInvalidChecksum = errors.New("organization number has an invalid check digit")
+)
+
+// weights are the MOD11 weights for the first eight digits
+var weights = [8]int{3, 2, 7, 6, 5, 4, 3, 2}
+
+// Normalize strips formatting from an organization number, such as spaces,
+// dots and dashes, a leading "NO" country code and a trailing "MVA" VAT
+// suffix. "NO 923 609 016 MVA" becomes "923609016". The result is not
+// validated; see Parse.
+func Normalize(s string) (string, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return "", ErrEmpty
+	}
+
+	s = strings.TrimPrefix(s, "NO")
+	s = strings.TrimSuffix(s, "MVA")
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '\u00a0' || r == '.' || r == '-':
+			// Common separators in formatted numbers
+		default:
+			return "", ErrInvalidCharacters
+		}
+	}
+
+	if b.Len() == 0 {
+		return "", ErrEmpty
+	}
+
+	return b.String(), nil
+}
+
+// Validate checks that a normalized organization number has 9 digits, starts
+// with 8 or 9 and has a valid MOD11 check digit
+func Validate(orgNo string) error {
+	if len(orgNo) != 9 {
+		return ErrInvalidLength
+	}
+	for _, r := range orgNo {
+		if r < '0' || r > '9' {
+			return ErrInvalidCharacters
+		}
+	}
+	if orgNo[0] != '8' && orgNo[0] != '9' {
+		return ErrInvalidPrefix
+	}
+
+	check, ok := CheckDigit(orgNo[:8])
+	if !ok || check != int(orgNo[8]-'0') {
+		return ErrInvalidChecksum
+	}
+
+	return nil
+}
+
+// Parse normalizes and validates an organization number, returning it as 9 digits
+func Parse(s string) (string, error) {
+	orgNo, err := Normalize(s)
+	if err != nil {
+		return "", err
+	}
+	if err := Validate(orgNo); err != nil {
+		return "", err
+	}
+	return orgNo, nil
+}
+
+// IsValid reports whether s is a valid organization number in any accepted format
+func IsValid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// CheckDigit calculates the MOD11 check digit for the first eight digits of an
+// organization number. It reports false when no valid check digit exists,
+// which is the case when the remainder would give 10.
+func CheckDigit(digits string) (int, bool) {
+	if len(digits) != len(weights) {
+		return 0, false
+	}
+
+	sum := 0
+	for i, w := range weights {
+		d := digits[i]
+		if d < '0' || d > '9' {
+			return 0, false
+		}
+		sum += int(d-'0') * w
+	}
+
+	check := 11 - sum%11
+	switch check {
+	case 11:
+		return 0, true
+	case 10:
+		return 0, false
+	}
+
+	return check, true
+}
+
+// Format returns a 9-digit organization number grouped as "923 609 016".
+// Other input is returned unchanged.
+func Format(orgNo string) string {
+	if len(orgNo) != 9 {
+		return orgNo
+	}
+	return orgNo[:3] + " " + orgNo[3:6] + " " + orgNo[6:]
+}
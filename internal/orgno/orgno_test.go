@@ -0,0 +1,128 @@
+package orgno
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "digits", input: "923609016", want: "923609016"},
+		{name: "grouped with spaces", input: "923 609 016", want: "923609016"},
+		{name: "non-breaking spaces", input: "923\u00a0609\u00a0016", want: "923609016"},
+		{name: "dots and dashes", input: "923.609-016", want: "923609016"},
+		{name: "country code and vat suffix", input: "NO 923 609 016 MVA", want: "923609016"},
+		{name: "lowercase prefix and suffix", input: "no923609016mva", want: "923609016"},
+		{name: "surrounding space", input: "  923609016  ", want: "923609016"},
+		{name: "not validated", input: "123", want: "123"},
+		{name: "empty", input: "", wantErr: ErrEmpty},
+		{name: "only separators", input: " - . ", wantErr: ErrEmpty},
+		{name: "only prefix", input: "NO MVA", wantErr: ErrEmpty},
+		{name: "letters", input: "92360901A", wantErr: ErrInvalidCharacters},
+		{name: "slash", input: "923/609/016", wantErr: ErrInvalidCharacters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "valid", input: "923609016", want: "923609016"},
+		{name: "valid formatted", input: "NO 984 851 006 MVA", want: "984851006"},
+		{name: "check digit zero", input: "976820479", want: "976820479"},
+		{name: "too short", input: "92360901", wantErr: ErrInvalidLength},
+		{name: "too long", input: "9236090160", wantErr: ErrInvalidLength},
+		{name: "wrong prefix", input: "123609016", wantErr: ErrInvalidPrefix},
+		{name: "wrong check digit", input: "923609017", wantErr: ErrInvalidChecksum},
+		{name: "empty", input: "", wantErr: ErrEmpty},
+		{name: "letters", input: "ABC", wantErr: ErrInvalidCharacters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if valid := IsValid(tt.input); valid != (tt.wantErr == nil) {
+				t.Errorf("IsValid(%q) = %v, want %v", tt.input, valid, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   int
+		wantOK bool
+	}{
+		{digits: "92360901", want: 6, wantOK: true},
+		{digits: "98485100", want: 6, wantOK: true},
+		{digits: "81234567", want: 2, wantOK: true},
+		{digits: "9236090", wantOK: false},
+		{digits: "9236090A", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := CheckDigit(tt.digits)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("CheckDigit(%q) = %d, %v, want %d, %v", tt.digits, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got := Format("923609016"); got != "923 609 016" {
+		t.Errorf("Format(923609016) = %q, want %q", got, "923 609 016")
+	}
+	if got := Format("1234"); got != "1234" {
+		t.Errorf("Format(1234) = %q, want it unchanged", got)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{"923609016", "NO 923 609 016 MVA", "923.609-016", "", "NO", "92360901A", "123609016"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		got, err := Parse(s)
+		if err != nil {
+			if got != "" {
+				t.Errorf("Parse(%q) = %q with error %v, want empty result", s, got, err)
+			}
+			return
+		}
+
+		if err := Validate(got); err != nil {
+			t.Errorf("Parse(%q) = %q, which does not validate: %v", s, got, err)
+		}
+		if again, err := Parse(Format(got)); err != nil || again != got {
+			t.Errorf("Parse(Format(%q)) = %q, %v, want %q", got, again, err, got)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+// Package phone parses phone numbers into canonical E.164 form and classifies
+// Norwegian numbers by range.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// NorwayCountryCode is the calling code for Norway
+const NorwayCountryCode = "47"
+
+var (
+	// ErrEmpty is returned when no phone number was given
+	ErrEmpty = errors.New("phone number cannot be empty")
+	// ErrInvalidCharacters is returned when the number contains anything but digits, separators and a leading +
+	ErrInvalidCharacters = errors.New("phone number may only contain digits, spaces and a leading +")
+	// ErrInvalidLength is returned when the number has too few or too many digits
+	ErrInvalidLength = errors.New("phone number has an invalid number of digits")
+	// ErrInvalidRange is returned when a Norwegian number is in a range that is not assigned to subscribers
+	ErrInvalidRange = errors.New("phone number is not in a valid Norwegian number range")
+	// ErrNotNorwegian is returned when a Norwegian number was required but another country code was given
+	ErrNotNorwegian = errors.New("phone number is not a Norwegian number")
+	// ErrNotMobile is returned when a mobile number was required but a landline or service number was given
+	ErrNotMobile = errors.New("phone number is not a Norwegian mobile number")
+)
+
+// Type classifies a Norwegian phone number by its range
+type Type string
+
+const (
+	// TypeMobile covers the 4xx and 9xx ranges
+	TypeMobile Type = "mobile"
+	// TypeLandline covers the 2xx, 3xx, 5xx, 6xx and 7xx ranges
+	TypeLandline Type = "landline"
+	// TypeService covers the 8xx range of freephone and premium numbers
+	TypeService Type = "service"
+	// TypeUnknown is used for foreign numbers, which are not classified
+	TypeUnknown Type = "unknown"
+)
+
+// Number is a parsed phone number
+type Number struct {
+	// E164 is the canonical international form, such as "+4791234567"
+	E164 string `json:"e164"`
+	// CountryCode is the calling code without the +, such as "47"
+	CountryCode string `json:"countryCode"`
+	// National is the number without country code, as used in Bisnode searches
+	National string `json:"national"`
+	// Type is the range the number belongs to, only known for Norwegian numbers
+	Type Type `json:"type"`
+}
+
+// IsNorwegian reports whether the number has the Norwegian country code
+func (n Number) IsNorwegian() bool {
+	return n.CountryCode == NorwayCountryCode
+}
+
+// IsMobile reports whether the number is a Norwegian mobile number
+func (n Number) IsMobile() bool {
+	return n.IsNorwegian() && n.Type == TypeMobile
+}
+
+// Parse parses a phone number written in national or international form.
+// Numbers starting with + or 00 are international; 8-digit numbers without a
+// prefix are Norwegian, as are 10-digit numbers starting with 47 where the +
+// was left out. Spaces, dashes, dots and parentheses are ignored.
+func Parse(s string) (Number, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Number{}, ErrEmpty
+	}
+
+	international := false
+	if strings.HasPrefix(s, "+") {
+		international = true
+		s = s[1:]
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '\u00a0' || r == '-' || r == '.' || r == '(' || r == ')':
+			// Common separators in formatted numbers
+		default:
+			return Number{}, ErrInvalidCharacters
+		}
+	}
+	digits := b.String()
+	if digits == "" {
+		return Number{}, ErrEmpty
+	}
+
+	if !international && strings.HasPrefix(digits, "00") {
+		international = true
+		digits = digits[2:]
+	}
+
+	if !international {
+		switch {
+		case len(digits) == 8:
+			return parseNorwegian(digits)
+		case len(digits) == 10 && strings.HasPrefix(digits, NorwayCountryCode):
+			return parseNorwegian(digits[2:])
+		default:
+			return Number{}, ErrInvalidLength
+		}
+	}
+
+	// E.164 allows at most 15 digits including the country code
+	if len(digits) < 7 || len(digits) > 15 {
+		return Number{}, ErrInvalidLength
+	}
+
+	cc := countryCode(digits)
+	if cc == NorwayCountryCode {
+		return parseNorwegian(digits[len(cc):])
+	}
+
+	return Number{
+		E164:        "+" + digits,
+		CountryCode: cc,
+		National:    digits[len(cc):],
+		Type:        TypeUnknown,
+	}, nil
+}
+
+// ParseNorwegianMobile parses a phone number and requires it to be a Norwegian mobile number
+func ParseNorwegianMobile(s string) (Number, error) {
+	n, err := Parse(s)
+	if err != nil {
+		return Number{}, err
+	}
+	if !n.IsNorwegian() {
+		return Number{}, ErrNotNorwegian
+	}
+	if n.Type != TypeMobile {
+		return Number{}, ErrNotMobile
+	}
+	return n, nil
+}
+
+// parseNorwegian classifies an 8-digit national Norwegian number
+func parseNorwegian(national string) (Number, error) {
+	if len(national) != 8 {
+		return Number{}, ErrInvalidLength
+	}
+
+	var t Type
+	switch national[0] {
+	case '4', '9':
+		t = TypeMobile
+	case '2', '3', '5', '6', '7':
+		t = TypeLandline
+	case '8':
+		t = TypeService
+	default:
+		// 0xx and 1xx are reserved for short numbers and prefixes
+		return Number{}, ErrInvalidRange
+	}
+
+	return Number{
+		E164:        "+" + NorwayCountryCode + national,
+		CountryCode: NorwayCountryCode,
+		National:    national,
+		Type:        t,
+	}, nil
+}
+
+// twoDigitCodes lists the ITU-T E.164 country codes with two digits. Codes
+// starting with 1 or 7 have one digit and all others have three.
+var twoDigitCodes = map[string]bool{
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true,
+	"34": true, "36": true, "39": true, "40": true, "41": true, "43": true,
+	"44": true, "45": true, "46": true, "47": true, "48": true, "49": true,
+	"51": true, "52": true, "53": true, "54": true, "55": true, "56": true,
+	"57": true, "58": true, "60": true, "61": true, "62": true, "63": true,
+	"64": true, "65": true, "66": true, "81": true, "82": true, "84": true,
+	"86": true, "90": true, "91": true, "92": true, "93": true, "94": true,
+	"95": true, "98": true,
+}
+
+// countryCode returns the calling code at the start of an international number
+func countryCode(digits string) string {
+	switch {
+	case digits[0] == '1' || digits[0] == '7':
+		return digits[:1]
+	case twoDigitCodes[digits[:2]]:
+		return digits[:2]
+	default:
+		return digits[:3]
+	}
+}
@@ -0,0 +1,149 @@
+package phone
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Number
+		wantErr error
+	}{
+		{
+			name:  "national mobile",
+			input: "91234567",
+			want:  Number{E164: "+4791234567", CountryCode: "47", National: "91234567", Type: TypeMobile},
+		},
+		{
+			name:  "formatted mobile",
+			input: "912 34 567",
+			want:  Number{E164: "+4791234567", CountryCode: "47", National: "91234567", Type: TypeMobile},
+		},
+		{
+			name:  "non-breaking spaces",
+			input: "912\u00a034\u00a0567",
+			want:  Number{E164: "+4791234567", CountryCode: "47", National: "91234567", Type: TypeMobile},
+		},
+		{
+			name:  "international with plus",
+			input: "+47 412 34 567",
+			want:  Number{E164: "+4741234567", CountryCode: "47", National: "41234567", Type: TypeMobile},
+		},
+		{
+			name:  "international with 00",
+			input: "0047 91234567",
+			want:  Number{E164: "+4791234567", CountryCode: "47", National: "91234567", Type: TypeMobile},
+		},
+		{
+			name:  "country code without plus",
+			input: "4791234567",
+			want:  Number{E164: "+4791234567", CountryCode: "47", National: "91234567", Type: TypeMobile},
+		},
+		{
+			name:  "landline with parentheses",
+			input: "(22) 12-34.56",
+			want:  Number{E164: "+4722123456", CountryCode: "47", National: "22123456", Type: TypeLandline},
+		},
+		{
+			name:  "service number",
+			input: "80012345",
+			want:  Number{E164: "+4780012345", CountryCode: "47", National: "80012345", Type: TypeService},
+		},
+		{
+			name:  "swedish",
+			input: "+46 70 123 45 67",
+			want:  Number{E164: "+46701234567", CountryCode: "46", National: "701234567", Type: TypeUnknown},
+		},
+		{
+			name:  "north american",
+			input: "+1 202 555 0100",
+			want:  Number{E164: "+12025550100", CountryCode: "1", National: "2025550100", Type: TypeUnknown},
+		},
+		{
+			name:  "three digit country code",
+			input: "+354 555 1234",
+			want:  Number{E164: "+3545551234", CountryCode: "354", National: "5551234", Type: TypeUnknown},
+		},
+		{name: "empty", input: "", wantErr: ErrEmpty},
+		{name: "only separators", input: " - ", wantErr: ErrEmpty},
+		{name: "letters", input: "9123456a", wantErr: ErrInvalidCharacters},
+		{name: "plus in the middle", input: "47+91234567", wantErr: ErrInvalidCharacters},
+		{name: "too short", input: "9123456", wantErr: ErrInvalidLength},
+		{name: "too long", input: "912345678", wantErr: ErrInvalidLength},
+		{name: "international too long", input: "+4712345678901234", wantErr: ErrInvalidLength},
+		{name: "norwegian with wrong length", input: "+47 912345678", wantErr: ErrInvalidLength},
+		{name: "reserved range", input: "11234567", wantErr: ErrInvalidRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNorwegianMobile(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr error
+	}{
+		{input: "91234567"},
+		{input: "+47 41234567"},
+		{input: "22123456", wantErr: ErrNotMobile},
+		{input: "80012345", wantErr: ErrNotMobile},
+		{input: "+46701234567", wantErr: ErrNotNorwegian},
+		{input: "123", wantErr: ErrInvalidLength},
+	}
+
+	for _, tt := range tests {
+		n, err := ParseNorwegianMobile(tt.input)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseNorwegianMobile(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !n.IsMobile() {
+			t.Errorf("ParseNorwegianMobile(%q) = %+v, which is not a mobile number", tt.input, n)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{"91234567", "+47 912 34 567", "0047 91234567", "4791234567", "+46701234567", "+1 202 555 0100", "", "+", "00", "11234567"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		n, err := Parse(s)
+		if err != nil {
+			if n != (Number{}) {
+				t.Errorf("Parse(%q) = %+v with error %v, want zero Number", s, n, err)
+			}
+			return
+		}
+
+		if n.E164 != "+"+n.CountryCode+n.National {
+			t.Errorf("Parse(%q) = %+v, E164 does not match country code and national number", s, n)
+		}
+		if digits := strings.TrimPrefix(n.E164, "+"); len(digits) > 15 {
+			t.Errorf("Parse(%q) = %+v, more than 15 digits", s, n)
+		}
+		if n.IsNorwegian() && (len(n.National) != 8 || n.Type == TypeUnknown) {
+			t.Errorf("Parse(%q) = %+v, not a classified 8-digit Norwegian number", s, n)
+		}
+
+		again, err := Parse(n.E164)
+		if err != nil || again != n {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", n.E164, again, err, n)
+		}
+	})
+}
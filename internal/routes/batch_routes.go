@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"bisnode/internal/handlers"
+	"net/http"
+)
+
+// RegisterBatchRoutes registers the batch lookup routes
+func RegisterBatchRoutes(mux *http.ServeMux, h *handlers.BatchHandler) {
+	// Look up a mixed list of organizations, persons and vehicles
+	mux.HandleFunc("POST /api/v1/batch", h.Lookup)
+}
@@ -11,13 +11,20 @@ func RegisterDirectoryRoutes(mux *http.ServeMux, h *handlers.DirectoryHandler) {
 	mux.HandleFunc("POST /api/v1/directory/persons/search", h.SearchPerson)
 	mux.HandleFunc("GET /api/v1/directory/persons/search", h.SearchPerson)
 
+	// Address and phone history of a person by mobile number
+	mux.HandleFunc("GET /api/v1/directory/persons/{mobileNumber}/history", h.PersonHistory)
+
+	// Check phone numbers against marketing reservations
+	mux.HandleFunc("POST /api/v1/directory/wash", h.Wash)
+
 	// Organization search by organization number
 	mux.HandleFunc("GET /api/v1/directory/organizations/search", h.SearchOrganization)
 	mux.HandleFunc("POST /api/v1/directory/organizations/search", h.SearchOrganization)
 
+	// Organization search by name
+	mux.HandleFunc("GET /api/v1/directory/organizations/search-by-name", h.SearchOrganizationsByName)
+	mux.HandleFunc("POST /api/v1/directory/organizations/search-by-name", h.SearchOrganizationsByName)
+
 	// Health check endpoint
-	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	mux.HandleFunc("GET /health", handlers.Health)
 }
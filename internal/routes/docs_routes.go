@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"bisnode/internal/handlers"
+	"net/http"
+)
+
+// RegisterDocsRoutes registers the API documentation routes
+func RegisterDocsRoutes(mux *http.ServeMux, h *handlers.DocsHandler) {
+	// OpenAPI 3.1 document generated from the handlers
+	mux.HandleFunc("GET /openapi.json", h.OpenAPI)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"bisnode/internal/handlers"
+	"net/http"
+)
+
+// RegisterEnrichRoutes registers the CSV enrichment routes
+func RegisterEnrichRoutes(mux *http.ServeMux, h *handlers.EnrichHandler) {
+	// Append directory or motor vehicle data to the rows of a CSV file
+	mux.HandleFunc("POST /api/v1/enrich", h.Enrich)
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"bisnode/internal/handlers"
+	"net/http"
+)
+
+// RegisterGeoRoutes registers the directory location search routes
+func RegisterGeoRoutes(mux *http.ServeMux, h *handlers.GeoHandler) {
+	// Search for listings near a point or inside a bounding box
+	mux.HandleFunc("GET /api/v1/directory/nearby", h.SearchNearby)
+	mux.HandleFunc("POST /api/v1/directory/nearby", h.SearchNearby)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"bisnode/internal/handlers"
+	"net/http"
+)
+
+// RegisterJobRoutes registers the background job routes
+func RegisterJobRoutes(mux *http.ServeMux, h *handlers.JobHandler) {
+	// Submit and list jobs
+	mux.HandleFunc("POST /api/v1/jobs", h.Submit)
+	mux.HandleFunc("GET /api/v1/jobs", h.List)
+
+	// Follow, fetch the results of and cancel a job
+	mux.HandleFunc("GET /api/v1/jobs/{id}", h.Get)
+	mux.HandleFunc("GET /api/v1/jobs/{id}/results", h.Results)
+	mux.HandleFunc("POST /api/v1/jobs/{id}/cancel", h.Cancel)
+}
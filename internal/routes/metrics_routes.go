@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"bisnode/internal/handlers"
+	"net/http"
+)
+
+// RegisterMetricsRoutes registers the lookup metrics route
+func RegisterMetricsRoutes(mux *http.ServeMux, h *handlers.MetricsHandler) {
+	mux.HandleFunc("GET /metrics", h.Get)
+}
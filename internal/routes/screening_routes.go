@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"bisnode/internal/handlers"
+	"net/http"
+)
+
+// RegisterScreeningRoutes registers the phone list screening routes
+func RegisterScreeningRoutes(mux *http.ServeMux, h *handlers.ScreeningHandler) {
+	// Start screening a phone list and poll its progress
+	mux.HandleFunc("POST /api/v1/directory/screenings", h.StartScreening)
+	mux.HandleFunc("GET /api/v1/directory/screenings/{id}", h.GetScreening)
+
+	// Download the report of a completed screening
+	mux.HandleFunc("GET /api/v1/directory/screenings/{id}/report", h.GetScreeningReport)
+}
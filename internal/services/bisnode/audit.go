@@ -0,0 +1,205 @@
+package bisnode
+
+import (
+	"bisnode/internal/models"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// callerKey is the context key of the caller recorded in the audit log
+type callerKey struct{}
+
+// Caller identifies who made a lookup
+type Caller struct {
+	// Address is the client address of the request
+	Address string
+	// ClaimedUser is the user the request named, such as in basic auth. The
+	// API does not check credentials, so this is an unverified claim.
+	ClaimedUser string
+}
+
+// WithCaller returns a context that attributes lookups made with it to caller
+// in the audit log
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom returns the caller set with WithCaller, or the zero Caller
+func CallerFrom(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+// AuditEntry is one lookup in the audit log
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	// CallerAddress is the client address of the request, if known
+	CallerAddress string `json:"callerAddress,omitempty"`
+	// ClaimedUser is the unverified user the request named, if any
+	ClaimedUser string `json:"claimedUser,omitempty"`
+	Operation   string `json:"operation"`
+	// Query holds the looked up values in URL query form, such as "mobileNumber=91234567"
+	Query   string `json:"query"`
+	Results int    `json:"results"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AuditLog writes an AuditEntry for every lookup made through the audited
+// decorators, as one JSON object per line. It is safe for concurrent use.
+type AuditLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	now func() time.Time
+}
+
+// NewAuditLog creates an AuditLog writing to w
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{
+		enc: json.NewEncoder(w),
+		now: time.Now,
+	}
+}
+
+// record writes an entry for a lookup. Failing to write is logged rather than
+// failing the lookup.
+func (a *AuditLog) record(ctx context.Context, operation string, query url.Values, results int, err error) {
+	caller := CallerFrom(ctx)
+	entry := AuditEntry{
+		Time:          a.now().UTC(),
+		CallerAddress: caller.Address,
+		ClaimedUser:   caller.ClaimedUser,
+		Operation:     operation,
+		Query:         query.Encode(),
+		Results:       results,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.enc.Encode(entry); err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
+
+// queryValues returns the non-empty pairs of name and value as url.Values
+func queryValues(pairs ...string) url.Values {
+	values := url.Values{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			values.Set(pairs[i], pairs[i+1])
+		}
+	}
+	return values
+}
+
+// directoryResults returns the number of results in a directory response, which may be nil
+func directoryResults(resp *DirectoryResponse) int {
+	if resp == nil {
+		return 0
+	}
+	return len(resp.Result)
+}
+
+// auditedPersonSearcher records the lookups of a PersonSearcher
+type auditedPersonSearcher struct {
+	next PersonSearcher
+	log  *AuditLog
+}
+
+// NewAuditedPersonSearcher records the lookups of next in log
+func NewAuditedPersonSearcher(next PersonSearcher, log *AuditLog) PersonSearcher {
+	return &auditedPersonSearcher{next: next, log: log}
+}
+
+func (s *auditedPersonSearcher) SearchByMobileNumber(ctx context.Context, mobileNumber string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	resp, err := s.next.SearchByMobileNumber(ctx, mobileNumber, opts)
+	s.log.record(ctx, "persons.searchByMobileNumber", queryValues("mobileNumber", mobileNumber), directoryResults(resp), err)
+	return resp, err
+}
+
+func (s *auditedPersonSearcher) SearchPersons(ctx context.Context, query models.PersonSearchQuery) (*DirectoryResponse, error) {
+	resp, err := s.next.SearchPersons(ctx, query)
+	s.log.record(ctx, "persons.searchPersons", queryValues(
+		"firstName", query.FirstName,
+		"lastName", query.LastName,
+		"street", query.Street,
+		"zipCode", query.ZipCode,
+		"city", query.City,
+	), directoryResults(resp), err)
+	return resp, err
+}
+
+func (s *auditedPersonSearcher) SearchByZipCode(ctx context.Context, zipCode string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	resp, err := s.next.SearchByZipCode(ctx, zipCode, opts)
+	s.log.record(ctx, "persons.searchByZipCode", queryValues("zipCode", zipCode), directoryResults(resp), err)
+	return resp, err
+}
+
+// auditedOrganizationLookup records the lookups of an OrganizationLookup
+type auditedOrganizationLookup struct {
+	next OrganizationLookup
+	log  *AuditLog
+}
+
+// NewAuditedOrganizationLookup records the lookups of next in log
+func NewAuditedOrganizationLookup(next OrganizationLookup, log *AuditLog) OrganizationLookup {
+	return &auditedOrganizationLookup{next: next, log: log}
+}
+
+func (s *auditedOrganizationLookup) SearchByOrganizationNumber(ctx context.Context, orgNo string) (*DirectoryResponse, error) {
+	resp, err := s.next.SearchByOrganizationNumber(ctx, orgNo)
+	s.log.record(ctx, "organizations.searchByOrganizationNumber", queryValues("organizationNumber", orgNo), directoryResults(resp), err)
+	return resp, err
+}
+
+func (s *auditedOrganizationLookup) SearchOrganizationsByName(ctx context.Context, query models.OrganizationSearchQuery) (*OrganizationsResponse, error) {
+	resp, err := s.next.SearchOrganizationsByName(ctx, query)
+	results := 0
+	if resp != nil {
+		results = len(resp.Result)
+	}
+	s.log.record(ctx, "organizations.searchOrganizationsByName", queryValues(
+		"name", query.Name,
+		"city", query.City,
+		"zipCode", query.ZipCode,
+	), results, err)
+	return resp, err
+}
+
+// auditedVehicleLookup records the lookups of a VehicleLookup
+type auditedVehicleLookup struct {
+	next VehicleLookup
+	log  *AuditLog
+}
+
+// NewAuditedVehicleLookup records the lookups of next in log
+func NewAuditedVehicleLookup(next VehicleLookup, log *AuditLog) VehicleLookup {
+	return &auditedVehicleLookup{next: next, log: log}
+}
+
+func (s *auditedVehicleLookup) SearchByLicenseNumber(ctx context.Context, licenseNumber string) (*VehicleResponse, error) {
+	resp, err := s.next.SearchByLicenseNumber(ctx, licenseNumber)
+	s.log.record(ctx, "vehicles.searchByLicenseNumber", queryValues("licenseNumber", licenseNumber), vehicleResults(resp), err)
+	return resp, err
+}
+
+func (s *auditedVehicleLookup) SearchByVIN(ctx context.Context, vin string) (*VehicleResponse, error) {
+	resp, err := s.next.SearchByVIN(ctx, vin)
+	s.log.record(ctx, "vehicles.searchByVIN", queryValues("vin", vin), vehicleResults(resp), err)
+	return resp, err
+}
+
+// vehicleResults returns the number of results in a vehicle response, which may be nil
+func vehicleResults(resp *VehicleResponse) int {
+	if resp == nil {
+		return 0
+	}
+	return len(resp.Result)
+}
@@ -0,0 +1,75 @@
+package bisnode
+
+import (
+	"bisnode/internal/config"
+	"bisnode/internal/geo"
+	"bisnode/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAuditedPersonSearcher(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewAuditLog(&buf)
+
+	found := NewAuditedPersonSearcher(stubPersonSearcher{resp: &DirectoryResponse{
+		DirectorySearchResponse: models.DirectorySearchResponse{Result: []models.DirectoryResult{{FirstName: "Ola"}}},
+	}}, log)
+	failed := NewAuditedPersonSearcher(stubPersonSearcher{err: errors.New("status 502")}, log)
+
+	ctx := WithCaller(context.Background(), Caller{Address: "10.0.0.1", ClaimedUser: "alice"})
+	found.SearchByMobileNumber(ctx, "91234567", models.SearchOptions{})
+	failed.SearchPersons(context.Background(), models.PersonSearchQuery{FirstName: "Kari", City: "Oslo"})
+
+	var entries []AuditEntry
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e AuditEntry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("audit log is not JSON lines: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("audit log has %d entries, want 2", len(entries))
+	}
+
+	if e := entries[0]; e.CallerAddress != "10.0.0.1" || e.ClaimedUser != "alice" || e.Operation != "persons.searchByMobileNumber" || e.Query != "mobileNumber=91234567" || e.Results != 1 || e.Error != "" {
+		t.Errorf("first entry = %+v, want alice's mobile search with 1 result", e)
+	}
+	if e := entries[1]; e.CallerAddress != "" || e.Operation != "persons.searchPersons" || e.Query != "city=Oslo&firstName=Kari" || e.Error != "status 502" {
+		t.Errorf("second entry = %+v, want an anonymous failed person search", e)
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("entry time is zero")
+	}
+}
+
+func TestGeoSearchIsAudited(t *testing.T) {
+	var buf bytes.Buffer
+	persons := NewAuditedPersonSearcher(stubPersonSearcher{resp: &DirectoryResponse{
+		DirectorySearchResponse: models.DirectorySearchResponse{Result: []models.DirectoryResult{{FirstName: "Ola", Latitude: 59.9139, Longitude: 10.7522}}},
+	}}, NewAuditLog(&buf))
+	index := geo.NewZipIndex([]geo.ZipCode{{Code: "0155", City: "OSLO", Center: geo.Point{Latitude: 59.9127, Longitude: 10.7461}}})
+	service := NewGeoSearchService(persons, index, &config.GeoConfig{MaxRadiusMeters: 5000, MaxZipCodes: 10}, nil)
+
+	ctx := WithCaller(context.Background(), Caller{Address: "10.0.0.1"})
+	resp, err := service.SearchNearby(ctx, models.GeoSearchQuery{Center: &geo.Point{Latitude: 59.9139, Longitude: 10.7522}, RadiusMeters: 1000})
+	if err != nil {
+		t.Fatalf("SearchNearby() error = %v", err)
+	}
+	if len(resp.Result) != 1 {
+		t.Errorf("SearchNearby() = %+v, want Ola", resp.Result)
+	}
+
+	var e AuditEntry
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatalf("audit log = %q, want one entry: %v", buf.String(), err)
+	}
+	if e.Operation != "persons.searchByZipCode" || e.Query != "zipCode=0155" || e.CallerAddress != "10.0.0.1" {
+		t.Errorf("entry = %+v, want the zip code search of the nearby search", e)
+	}
+}
@@ -0,0 +1,123 @@
+package bisnode
+
+import (
+	"bisnode/internal/config"
+	"bisnode/internal/models"
+	"bisnode/internal/validation"
+	"context"
+	"sync"
+)
+
+// BatchItemType is the kind of lookup a batch item asks for
+type BatchItemType string
+
+const (
+	BatchOrganizationNumber BatchItemType = "orgno"
+	BatchMobileNumber       BatchItemType = "mobile"
+	BatchLicensePlate       BatchItemType = "plate"
+	BatchVIN                BatchItemType = "vin"
+)
+
+// BatchItem is a single lookup in a batch
+type BatchItem struct {
+	Type  BatchItemType
+	Value string
+}
+
+// BatchOutcome is the result of a single batch item. Directory is set for
+// organization and mobile number lookups, Vehicle for plate and VIN lookups.
+type BatchOutcome struct {
+	Item      BatchItem
+	Directory *DirectoryResponse
+	Vehicle   *VehicleResponse
+	Err       error
+}
+
+// BatchService looks up mixed lists of organizations, persons and vehicles
+type BatchService struct {
+	persons       PersonSearcher
+	organizations OrganizationLookup
+	vehicles      VehicleLookup
+	maxItems      int
+	concurrency   int
+}
+
+// NewBatchService creates a new BatchService
+func NewBatchService(persons PersonSearcher, organizations OrganizationLookup, vehicles VehicleLookup, cfg *config.BatchConfig) *BatchService {
+	return &BatchService{
+		persons:       persons,
+		organizations: organizations,
+		vehicles:      vehicles,
+		maxItems:      cfg.MaxItems,
+		concurrency:   max(cfg.Concurrency, 1),
+	}
+}
+
+// Lookup looks up every item with bounded concurrency. Outcomes are returned
+// in the order of items, and a failed item does not stop the others; the
+// returned error is only set when the batch as a whole is rejected.
+func (s *BatchService) Lookup(ctx context.Context, items []BatchItem) ([]BatchOutcome, error) {
+	if len(items) == 0 {
+		return nil, validation.Errorf("items", "is required")
+	}
+	if len(items) > s.maxItems {
+		return nil, validation.Errorf("items", "must contain at most %d items", s.maxItems)
+	}
+
+	outcomes := make([]BatchOutcome, len(items))
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		outcomes[i].Item = item
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			outcomes[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(o *BatchOutcome) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			*o = s.LookupItem(ctx, o.Item)
+		}(&outcomes[i])
+	}
+	wg.Wait()
+
+	return outcomes, nil
+}
+
+// LookupItem runs the service call for a single item
+func (s *BatchService) LookupItem(ctx context.Context, item BatchItem) BatchOutcome {
+	o := BatchOutcome{Item: item}
+	switch item.Type {
+	case BatchOrganizationNumber:
+		o.Directory, o.Err = s.organizations.SearchByOrganizationNumber(ctx, item.Value)
+	case BatchMobileNumber:
+		o.Directory, o.Err = s.persons.SearchByMobileNumber(ctx, item.Value, models.SearchOptions{})
+	case BatchLicensePlate:
+		o.Vehicle, o.Err = s.vehicles.SearchByLicenseNumber(ctx, item.Value)
+	case BatchVIN:
+		o.Vehicle, o.Err = s.vehicles.SearchByVIN(ctx, item.Value)
+	default:
+		o.Err = validation.Errorf("type", "must be one of orgno, mobile, plate, vin")
+	}
+	return o
+}
+
+// Found reports whether the lookup succeeded with at least one result
+func (o BatchOutcome) Found() bool {
+	switch {
+	case o.Err != nil:
+		return false
+	case o.Directory != nil:
+		return len(o.Directory.Result) > 0
+	case o.Vehicle != nil:
+		return len(o.Vehicle.Result) > 0
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package bisnode
+
+import (
+	"bisnode/internal/config"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBatchServiceZeroConcurrency(t *testing.T) {
+	s := NewBatchService(stubPersonSearcher{resp: &DirectoryResponse{}}, nil, nil, &config.BatchConfig{MaxItems: 10})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Lookup(context.Background(), []BatchItem{{Type: BatchMobileNumber, Value: "91234567"}})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Lookup() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lookup() with a zero concurrency never returned")
+	}
+}
@@ -0,0 +1,213 @@
+package bisnode
+
+import (
+	"bisnode/internal/cache"
+	"bisnode/internal/config"
+	"bisnode/internal/domain"
+	"bisnode/internal/models"
+	"bisnode/internal/orgno"
+	"bisnode/internal/phone"
+	"bisnode/internal/vehicleid"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// responseCache caches Bisnode responses and annotates each response it
+// returns with its freshness. A nil responseCache calls fetch directly.
+type responseCache[V any] struct {
+	cache *cache.Cache[V]
+	// annotate returns a copy of a cached value with f set, so the freshness
+	// of one request does not leak into others
+	annotate func(v V, f *domain.Freshness) V
+}
+
+// newResponseCache creates a responseCache, or returns nil when caching is disabled
+func newResponseCache[V any](cfg *config.CacheConfig, annotate func(V, *domain.Freshness) V) *responseCache[V] {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	opts := cache.Options{
+		TTL:        time.Duration(cfg.TTLSeconds) * time.Second,
+		MaxEntries: cfg.MaxEntries,
+	}
+	if cfg.StaleWhileRevalidateSeconds != nil {
+		opts.StaleWhileRevalidate = time.Duration(*cfg.StaleWhileRevalidateSeconds) * time.Second
+	}
+	if cfg.StaleIfErrorSeconds != nil {
+		opts.StaleIfError = time.Duration(*cfg.StaleIfErrorSeconds) * time.Second
+	}
+
+	return &responseCache[V]{
+		cache:    cache.New[V](opts),
+		annotate: annotate,
+	}
+}
+
+// get returns the response cached under key, calling fetch when there is none
+func (c *responseCache[V]) get(ctx context.Context, key string, fetch cache.FetchFunc[V]) (V, error) {
+	if c == nil {
+		return fetch(ctx)
+	}
+
+	res, err := c.cache.Get(ctx, key, fetch)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	return c.annotate(res.Value, &domain.Freshness{
+		Status:     string(res.State),
+		FetchedAt:  res.StoredAt,
+		AgeSeconds: int(res.Age.Seconds()),
+	}), nil
+}
+
+// directoryFreshness copies a directory response with its freshness set
+func directoryFreshness(resp *DirectoryResponse, f *domain.Freshness) *DirectoryResponse {
+	annotated := *resp
+	annotated.Freshness = f
+	return &annotated
+}
+
+// organizationsFreshness copies an organization search response with its freshness set
+func organizationsFreshness(resp *OrganizationsResponse, f *domain.Freshness) *OrganizationsResponse {
+	annotated := *resp
+	annotated.Freshness = f
+	return &annotated
+}
+
+// vehicleFreshness copies a motor vehicle response with its freshness set
+func vehicleFreshness(resp *VehicleResponse, f *domain.Freshness) *VehicleResponse {
+	annotated := *resp
+	annotated.Freshness = f
+	return &annotated
+}
+
+// cachedPersonSearcher caches the responses of a PersonSearcher
+type cachedPersonSearcher struct {
+	next  PersonSearcher
+	cache *responseCache[*DirectoryResponse]
+}
+
+// NewCachedPersonSearcher caches the responses of next as configured by cfg,
+// and returns next unchanged when caching is disabled
+func NewCachedPersonSearcher(next PersonSearcher, cfg *config.CacheConfig) PersonSearcher {
+	c := newResponseCache(cfg, directoryFreshness)
+	if c == nil {
+		return next
+	}
+	return &cachedPersonSearcher{next: next, cache: c}
+}
+
+func (s *cachedPersonSearcher) SearchByMobileNumber(ctx context.Context, mobileNumber string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	// Invalid numbers are rejected by next and never cached
+	number, err := phone.ParseNorwegianMobile(mobileNumber)
+	if err != nil {
+		return s.next.SearchByMobileNumber(ctx, mobileNumber, opts)
+	}
+
+	key := fmt.Sprintf("mobile:%s:%s", opts.CacheKey(), number.National)
+	return s.cache.get(ctx, key, func(ctx context.Context) (*DirectoryResponse, error) {
+		return s.next.SearchByMobileNumber(ctx, number.National, opts)
+	})
+}
+
+func (s *cachedPersonSearcher) SearchPersons(ctx context.Context, query models.PersonSearchQuery) (*DirectoryResponse, error) {
+	key := fmt.Sprintf("persons:%s:%s", query.Options.CacheKey(), strings.ToLower(strings.Join([]string{
+		strings.TrimSpace(query.FirstName), strings.TrimSpace(query.LastName), strings.TrimSpace(query.Street),
+		strings.TrimSpace(query.ZipCode), strings.TrimSpace(query.City),
+	}, "|")))
+	return s.cache.get(ctx, key, func(ctx context.Context) (*DirectoryResponse, error) {
+		return s.next.SearchPersons(ctx, query)
+	})
+}
+
+func (s *cachedPersonSearcher) SearchByZipCode(ctx context.Context, zipCode string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	key := fmt.Sprintf("zip:%s:%s", opts.CacheKey(), strings.ReplaceAll(zipCode, " ", ""))
+	return s.cache.get(ctx, key, func(ctx context.Context) (*DirectoryResponse, error) {
+		return s.next.SearchByZipCode(ctx, zipCode, opts)
+	})
+}
+
+// cachedOrganizationLookup caches the responses of an OrganizationLookup
+type cachedOrganizationLookup struct {
+	next          OrganizationLookup
+	organizations *responseCache[*DirectoryResponse]
+	names         *responseCache[*OrganizationsResponse]
+}
+
+// NewCachedOrganizationLookup caches the responses of next as configured by
+// cfg, and returns next unchanged when caching is disabled
+func NewCachedOrganizationLookup(next OrganizationLookup, cfg *config.CacheConfig) OrganizationLookup {
+	organizations := newResponseCache(cfg, directoryFreshness)
+	if organizations == nil {
+		return next
+	}
+	return &cachedOrganizationLookup{
+		next:          next,
+		organizations: organizations,
+		names:         newResponseCache(cfg, organizationsFreshness),
+	}
+}
+
+func (s *cachedOrganizationLookup) SearchByOrganizationNumber(ctx context.Context, orgNo string) (*DirectoryResponse, error) {
+	cleanOrgNo, err := orgno.Parse(orgNo)
+	if err != nil {
+		return s.next.SearchByOrganizationNumber(ctx, orgNo)
+	}
+
+	return s.organizations.get(ctx, "organization:"+cleanOrgNo, func(ctx context.Context) (*DirectoryResponse, error) {
+		return s.next.SearchByOrganizationNumber(ctx, cleanOrgNo)
+	})
+}
+
+func (s *cachedOrganizationLookup) SearchOrganizationsByName(ctx context.Context, query models.OrganizationSearchQuery) (*OrganizationsResponse, error) {
+	key := fmt.Sprintf("organizations:%s:%s", query.Options.CacheKey(), strings.ToLower(strings.Join([]string{
+		strings.TrimSpace(query.Name), strings.TrimSpace(query.City), strings.TrimSpace(query.ZipCode),
+	}, "|")))
+	return s.names.get(ctx, key, func(ctx context.Context) (*OrganizationsResponse, error) {
+		return s.next.SearchOrganizationsByName(ctx, query)
+	})
+}
+
+// cachedVehicleLookup caches the responses of a VehicleLookup
+type cachedVehicleLookup struct {
+	next  VehicleLookup
+	cache *responseCache[*VehicleResponse]
+}
+
+// NewCachedVehicleLookup caches the responses of next as configured by cfg,
+// and returns next unchanged when caching is disabled
+func NewCachedVehicleLookup(next VehicleLookup, cfg *config.CacheConfig) VehicleLookup {
+	c := newResponseCache(cfg, vehicleFreshness)
+	if c == nil {
+		return next
+	}
+	return &cachedVehicleLookup{next: next, cache: c}
+}
+
+func (s *cachedVehicleLookup) SearchByLicenseNumber(ctx context.Context, licenseNumber string) (*VehicleResponse, error) {
+	id, err := vehicleid.Parse(licenseNumber)
+	if err != nil {
+		return s.next.SearchByLicenseNumber(ctx, licenseNumber)
+	}
+
+	return s.cache.get(ctx, "vehicle:"+id.Value, func(ctx context.Context) (*VehicleResponse, error) {
+		return s.next.SearchByLicenseNumber(ctx, id.Value)
+	})
+}
+
+func (s *cachedVehicleLookup) SearchByVIN(ctx context.Context, vin string) (*VehicleResponse, error) {
+	// Plates are rejected by next, so only VINs share the license number cache
+	id, err := vehicleid.Parse(vin)
+	if err != nil || id.Kind != vehicleid.KindVIN {
+		return s.next.SearchByVIN(ctx, vin)
+	}
+
+	return s.cache.get(ctx, "vehicle:"+id.Value, func(ctx context.Context) (*VehicleResponse, error) {
+		return s.next.SearchByVIN(ctx, id.Value)
+	})
+}
@@ -0,0 +1,41 @@
+package bisnode
+
+import (
+	"bisnode/internal/bisnodefake"
+	"bisnode/internal/config"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCachedOrganizationLookup(t *testing.T) {
+	fake, cfg := newFakeBisnode(t)
+	cacheCfg := &config.CacheConfig{
+		Enabled:    true,
+		TTLSeconds: 300,
+		MaxEntries: 100,
+	}
+	service := NewCachedOrganizationLookup(NewDirectoryService(NewDirectoryClient(cfg), nil), cacheCfg)
+
+	for i := 0; i < 2; i++ {
+		resp, err := service.SearchByOrganizationNumber(context.Background(), "923609016")
+		if err != nil {
+			t.Fatalf("SearchByOrganizationNumber() error = %v", err)
+		}
+		if resp.Freshness == nil || resp.Freshness.Status != "fresh" {
+			t.Errorf("Freshness = %+v, want fresh", resp.Freshness)
+		}
+	}
+	if n := len(fake.Requests()); n != 1 {
+		t.Errorf("Bisnode received %d requests, want 1 with the second served from cache", n)
+	}
+
+	// Failures are not cached
+	fake.Fail(bisnodefake.Fault{Status: http.StatusServiceUnavailable, Count: 1})
+	if _, err := service.SearchByOrganizationNumber(context.Background(), "984851006"); err == nil {
+		t.Fatal("SearchByOrganizationNumber() error = nil, want the upstream failure")
+	}
+	if _, err := service.SearchByOrganizationNumber(context.Background(), "984851006"); err != nil {
+		t.Errorf("SearchByOrganizationNumber() after a failure error = %v, want it retried", err)
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 )
 
 // DirectoryClient handles communication with the Bisnode Directory Search API
@@ -27,28 +26,36 @@ func NewDirectoryClient(cfg *config.BisnodeConfig) *DirectoryClient {
 	)
 
 	return &DirectoryClient{
-		baseURL:    "https://api.bisnode.no",
+		baseURL:    baseURL(cfg),
 		authHeader: "Basic " + auth,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		httpClient: newHTTPClient(cfg),
 	}
 }
 
-// SearchPerson searches for a person by mobile number
-func (c *DirectoryClient) SearchPerson(ctx context.Context, mobileNumber string) (*models.DirectorySearchResponse, error) {
-	url := fmt.Sprintf("%s/search/norway/directory", c.baseURL)
-	
+// Search runs a freetext directory search, such as for a mobile number or a
+// combination of name and address. opts must have every field set.
+func (c *DirectoryClient) Search(ctx context.Context, searchString string, opts models.SearchOptions) (*models.DirectorySearchResponse, error) {
 	// Prepare the request body
 	reqBody := models.DirectorySearchRequest{}
 	reqBody.Form.Type = "Freetext"
-	reqBody.Form.SearchString = mobileNumber
-	
+	reqBody.Form.SearchString = searchString
+
 	// Set search options
-	reqBody.Options.SearchMode = 3      // Smart Exact + Phonetic
-	reqBody.Options.OnlyFoundWords = true
-	reqBody.Options.ListingType = 2     // Person only
-	reqBody.Options.ResultLimit = 10    // Limit to 10 results
+	reqBody.Options.SearchMode = opts.SearchMode
+	reqBody.Options.ResultLimit = opts.ResultLimit
+	if opts.OnlyFoundWords != nil {
+		reqBody.Options.OnlyFoundWords = *opts.OnlyFoundWords
+	}
+	if opts.ListingType != nil {
+		reqBody.Options.ListingType = *opts.ListingType
+	}
+
+	return c.search(ctx, reqBody)
+}
+
+// search posts a directory search request
+func (c *DirectoryClient) search(ctx context.Context, reqBody models.DirectorySearchRequest) (*models.DirectorySearchResponse, error) {
+	url := fmt.Sprintf("%s/search/norway/directory", c.baseURL)
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
@@ -67,7 +74,7 @@ func (c *DirectoryClient) SearchPerson(ctx context.Context, mobileNumber string)
 
 	req.Header.Set("Authorization", c.authHeader)
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -76,7 +83,7 @@ func (c *DirectoryClient) SearchPerson(ctx context.Context, mobileNumber string)
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", 
+		return nil, fmt.Errorf("API request failed with status %d: %s",
 			resp.StatusCode, string(body))
 	}
 
@@ -0,0 +1,171 @@
+package bisnode
+
+import (
+	"bisnode/internal/bisnodefake"
+	"bisnode/internal/config"
+	"bisnode/internal/models"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeBisnode starts a fake Bisnode API requiring the credentials in the
+// returned configuration
+func newFakeBisnode(t *testing.T) (*bisnodefake.Server, *config.BisnodeConfig) {
+	t.Helper()
+
+	fake := bisnodefake.New(bisnodefake.Options{ClientID: "client", ClientSecret: "secret"})
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, &config.BisnodeConfig{
+		BaseURL:      server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+	}
+}
+
+func TestClientBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"", config.DefaultBisnodeBaseURL},
+		{"http://localhost:9090/", "http://localhost:9090"},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.BisnodeConfig{BaseURL: tt.baseURL}
+		if got := NewDirectoryClient(cfg).baseURL; got != tt.want {
+			t.Errorf("NewDirectoryClient(%q).baseURL = %q, want %q", tt.baseURL, got, tt.want)
+		}
+		if got := NewMotorVehicleClient(cfg).baseURL; got != tt.want {
+			t.Errorf("NewMotorVehicleClient(%q).baseURL = %q, want %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
+
+func TestDirectoryClientSearch(t *testing.T) {
+	fake, cfg := newFakeBisnode(t)
+	client := NewDirectoryClient(cfg)
+
+	onlyFoundWords := true
+	listing := models.ListingTypePerson
+	resp, err := client.Search(context.Background(), "91234567", models.SearchOptions{
+		SearchMode:     models.SearchModeSmart,
+		OnlyFoundWords: &onlyFoundWords,
+		ListingType:    &listing,
+		ResultLimit:    5,
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(resp.Result) != 1 || resp.Result[0].FirstName != "Ola" || resp.Result[0].Mobile != "91234567" {
+		t.Fatalf("Search() = %+v, want Ola Nordmann", resp.Result)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("Bisnode received %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if req.Method != http.MethodPost || req.Path != "/search/norway/directory" {
+		t.Errorf("request = %s %s, want POST /search/norway/directory", req.Method, req.Path)
+	}
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("client:secret"))
+	if got := req.Header.Get("Authorization"); got != wantAuth {
+		t.Errorf("Authorization = %q, want %q", got, wantAuth)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var body models.DirectorySearchRequest
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		t.Fatalf("request body is not a search request: %v", err)
+	}
+	if body.Form.Type != "Freetext" || body.Form.SearchString != "91234567" {
+		t.Errorf("request form = %+v, want a freetext search for 91234567", body.Form)
+	}
+	if body.Options.SearchMode != models.SearchModeSmart || !body.Options.OnlyFoundWords ||
+		body.Options.ListingType != models.ListingTypePerson || body.Options.ResultLimit != 5 {
+		t.Errorf("request options = %+v, want the options passed to Search", body.Options)
+	}
+}
+
+func TestDirectoryClientSearchByOrganizationNumber(t *testing.T) {
+	fake, cfg := newFakeBisnode(t)
+	// A trailing slash in the configured base URL must not end up in the path
+	cfg.BaseURL += "/"
+	client := NewDirectoryClient(cfg)
+
+	resp, err := client.SearchByOrganizationNumber(context.Background(), "923609016")
+	if err != nil {
+		t.Fatalf("SearchByOrganizationNumber() error = %v", err)
+	}
+	if len(resp.Result) != 1 || resp.Result[0].LastName != "Eksempel AS" {
+		t.Fatalf("SearchByOrganizationNumber() = %+v, want Eksempel AS", resp.Result)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 1 || requests[0].Method != http.MethodGet || requests[0].Path != "/search/norway/directory/923609016" {
+		t.Errorf("Bisnode received %+v, want one GET /search/norway/directory/923609016", requests)
+	}
+}
+
+func TestDirectoryClientErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   bisnodefake.Fault
+		wantErr string
+	}{
+		{name: "unauthorized", fault: bisnodefake.Fault{Status: http.StatusUnauthorized}, wantErr: "status 401"},
+		{name: "rate limited", fault: bisnodefake.Fault{Status: http.StatusTooManyRequests}, wantErr: "status 429"},
+		{name: "unavailable", fault: bisnodefake.Fault{Status: http.StatusServiceUnavailable}, wantErr: "status 503"},
+		{name: "malformed json", fault: bisnodefake.Fault{Malformed: true}, wantErr: "failed to decode response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, cfg := newFakeBisnode(t)
+			fake.Fail(tt.fault)
+			client := NewDirectoryClient(cfg)
+
+			_, err := client.SearchByOrganizationNumber(context.Background(), "923609016")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SearchByOrganizationNumber() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDirectoryClientWrongCredentials(t *testing.T) {
+	_, cfg := newFakeBisnode(t)
+	cfg.ClientSecret = "wrong"
+	client := NewDirectoryClient(cfg)
+
+	_, err := client.SearchByOrganizationNumber(context.Background(), "923609016")
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Errorf("SearchByOrganizationNumber() error = %v, want status 401", err)
+	}
+}
+
+func TestDirectoryClientContextDeadline(t *testing.T) {
+	fake, cfg := newFakeBisnode(t)
+	fake.SetLatency(time.Second)
+	client := NewDirectoryClient(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.SearchByOrganizationNumber(ctx, "923609016")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SearchByOrganizationNumber() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
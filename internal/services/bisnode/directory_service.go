@@ -1,34 +1,51 @@
 package bisnode
 
 import (
+	"bisnode/internal/config"
 	"bisnode/internal/models"
+	"bisnode/internal/orgno"
+	"bisnode/internal/phone"
+	"bisnode/internal/validation"
 	"context"
 	"fmt"
+	"strings"
 )
 
-// DirectoryService handles directory search operations
+// DirectoryService handles directory search operations. Responses are not
+// cached; wrap the service with NewCachedPersonSearcher and
+// NewCachedOrganizationLookup for that.
 type DirectoryService struct {
 	client *DirectoryClient
+	search searchDefaults
 }
 
-// NewDirectoryService creates a new DirectoryService
-func NewDirectoryService(client *DirectoryClient) *DirectoryService {
+// NewDirectoryService creates a new DirectoryService.
+// searchCfg provides the defaults and limits for search options callers leave unset.
+func NewDirectoryService(client *DirectoryClient, searchCfg *config.SearchConfig) *DirectoryService {
 	return &DirectoryService{
 		client: client,
+		search: newSearchDefaults(searchCfg),
 	}
 }
 
-// SearchByMobileNumber searches for a person by mobile number
-func (s *DirectoryService) SearchByMobileNumber(ctx context.Context, mobileNumber string) (*models.DirectorySearchResponse, error) {
-	if mobileNumber == "" {
-		return nil, fmt.Errorf("mobile number cannot be empty")
+// SearchByMobileNumber searches for a person by mobile number. Unset options
+// are taken from the server configuration, with the listing type defaulting to persons.
+func (s *DirectoryService) SearchByMobileNumber(ctx context.Context, mobileNumber string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	// Landlines and foreign numbers are rejected before spending a Bisnode
+	// lookup on them
+	number, err := phone.ParseNorwegianMobile(mobileNumber)
+	if err != nil {
+		return nil, validation.Errorf("mobileNumber", "%v", err)
 	}
+	cleanNumber := number.National
 
-	// Remove any non-digit characters from the mobile number
-	cleanNumber := cleanPhoneNumber(mobileNumber)
+	opts, err = s.search.resolve(opts, models.ListingTypePerson)
+	if err != nil {
+		return nil, err
+	}
 
 	// Search for the person in the directory
-	result, err := s.client.SearchPerson(ctx, cleanNumber)
+	result, err := s.freetext(ctx, cleanNumber, opts)
 	if err != nil {
 		return nil, fmt.Errorf("error searching directory: %w", err)
 	}
@@ -36,17 +53,47 @@ func (s *DirectoryService) SearchByMobileNumber(ctx context.Context, mobileNumbe
 	return result, nil
 }
 
-// SearchByOrganizationNumber searches for a company by organization number
-func (s *DirectoryService) SearchByOrganizationNumber(ctx context.Context, orgNo string) (*models.DirectorySearchResponse, error) {
-	if orgNo == "" {
-		return nil, fmt.Errorf("organization number cannot be empty")
+// SearchPersons searches for persons by name and address. The fields are
+// combined into a freetext query and the results are ranked by how closely
+// they match each field.
+func (s *DirectoryService) SearchPersons(ctx context.Context, query models.PersonSearchQuery) (*DirectoryResponse, error) {
+	query = normalizePersonQuery(query)
+	if err := validatePersonQuery(query); err != nil {
+		return nil, err
 	}
 
-	// Clean the organization number (remove spaces and dots)
-	cleanOrgNo := cleanOrganizationNumber(orgNo)
+	opts, err := s.search.resolve(query.Options, models.ListingTypePerson)
+	if err != nil {
+		return nil, err
+	}
+	query.Options = opts
 
-	// Search for the company in the directory
-	result, err := s.client.SearchByOrganizationNumber(ctx, cleanOrgNo)
+	searchString := personSearchString(query)
+	result, err := s.freetext(ctx, searchString, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error searching directory: %w", err)
+	}
+
+	result.Result = rankPersons(result.Result, query)
+
+	return result, nil
+}
+
+// SearchByZipCode lists the persons and companies in a zip code. Unset
+// options are taken from the server configuration, with the listing type
+// defaulting to all.
+func (s *DirectoryService) SearchByZipCode(ctx context.Context, zipCode string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	zipCode = strings.ReplaceAll(zipCode, " ", "")
+	if !validZipCode(zipCode) {
+		return nil, validation.Errorf("zipCode", "must be 4 digits")
+	}
+
+	opts, err := s.search.resolve(opts, models.ListingTypeAll)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.freetext(ctx, zipCode, opts)
 	if err != nil {
 		return nil, fmt.Errorf("error searching directory: %w", err)
 	}
@@ -54,24 +101,55 @@ func (s *DirectoryService) SearchByOrganizationNumber(ctx context.Context, orgNo
 	return result, nil
 }
 
-// cleanPhoneNumber removes all non-digit characters from a phone number
-func cleanPhoneNumber(phone string) string {
-	var result []rune
-	for _, r := range phone {
-		if r >= '0' && r <= '9' {
-			result = append(result, r)
-		}
+// SearchOrganizationsByName searches for companies by name, optionally
+// filtered by city and zip code, and returns a summary of each match
+func (s *DirectoryService) SearchOrganizationsByName(ctx context.Context, query models.OrganizationSearchQuery) (*OrganizationsResponse, error) {
+	query = normalizeOrganizationQuery(query)
+	if err := validateOrganizationQuery(query); err != nil {
+		return nil, err
 	}
-	return string(result)
+
+	opts, err := s.search.resolve(query.Options, models.ListingTypeCompany)
+	if err != nil {
+		return nil, err
+	}
+	query.Options = opts
+
+	searchString := organizationSearchString(query)
+	result, err := s.freetext(ctx, searchString, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error searching directory: %w", err)
+	}
+
+	return &OrganizationsResponse{
+		OrganizationSearchResponse: models.OrganizationSearchResponse{
+			Result: summarizeOrganizations(result.Result, query),
+		},
+	}, nil
 }
 
-// cleanOrganizationNumber removes spaces and dots from an organization number
-func cleanOrganizationNumber(orgNo string) string {
-	var result []rune
-	for _, r := range orgNo {
-		if (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') {
-			result = append(result, r)
-		}
+// SearchByOrganizationNumber searches for a company by organization number
+func (s *DirectoryService) SearchByOrganizationNumber(ctx context.Context, orgNo string) (*DirectoryResponse, error) {
+	// Normalize and validate the organization number before spending a Bisnode lookup on it
+	cleanOrgNo, err := orgno.Parse(orgNo)
+	if err != nil {
+		return nil, validation.Errorf("organizationNumber", "%v", err)
+	}
+
+	// Search for the company in the directory
+	result, err := s.client.SearchByOrganizationNumber(ctx, cleanOrgNo)
+	if err != nil {
+		return nil, fmt.Errorf("error searching directory: %w", err)
+	}
+
+	return &DirectoryResponse{DirectorySearchResponse: *result}, nil
+}
+
+// freetext runs a freetext search
+func (s *DirectoryService) freetext(ctx context.Context, searchString string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	result, err := s.client.Search(ctx, searchString, opts)
+	if err != nil {
+		return nil, err
 	}
-	return string(result)
+	return &DirectoryResponse{DirectorySearchResponse: *result}, nil
 }
@@ -0,0 +1,126 @@
+package bisnode
+
+import (
+	"bisnode/internal/models"
+	"bisnode/internal/validation"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDirectoryServiceSearchByMobileNumber(t *testing.T) {
+	fake, cfg := newFakeBisnode(t)
+	service := NewDirectoryService(NewDirectoryClient(cfg), nil)
+
+	resp, err := service.SearchByMobileNumber(context.Background(), "+47 912 34 567", models.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchByMobileNumber() error = %v", err)
+	}
+	if len(resp.Result) != 1 || resp.Result[0].FirstName != "Ola" {
+		t.Fatalf("SearchByMobileNumber() = %+v, want Ola Nordmann", resp.Result)
+	}
+
+	// The number is sent in national form, with the defaults for unset options
+	var body models.DirectorySearchRequest
+	if err := json.Unmarshal(fake.Requests()[0].Body, &body); err != nil {
+		t.Fatalf("request body is not a search request: %v", err)
+	}
+	if body.Form.SearchString != "91234567" {
+		t.Errorf("search string = %q, want 91234567", body.Form.SearchString)
+	}
+	if body.Options.ListingType != models.ListingTypePerson || body.Options.SearchMode != models.SearchModeSmart || body.Options.ResultLimit != 10 {
+		t.Errorf("options = %+v, want the person listing type and server defaults", body.Options)
+	}
+}
+
+func TestDirectoryServiceRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		search func(*DirectoryService) error
+		field  string
+	}{
+		{
+			name: "landline",
+			search: func(s *DirectoryService) error {
+				_, err := s.SearchByMobileNumber(context.Background(), "22123456", models.SearchOptions{})
+				return err
+			},
+			field: "mobileNumber",
+		},
+		{
+			name: "foreign mobile",
+			search: func(s *DirectoryService) error {
+				_, err := s.SearchByMobileNumber(context.Background(), "+46701234567", models.SearchOptions{})
+				return err
+			},
+			field: "mobileNumber",
+		},
+		{
+			name: "result limit above maximum",
+			search: func(s *DirectoryService) error {
+				_, err := s.SearchByMobileNumber(context.Background(), "91234567", models.SearchOptions{ResultLimit: 1000})
+				return err
+			},
+			field: "limit",
+		},
+		{
+			name: "organization number check digit",
+			search: func(s *DirectoryService) error {
+				_, err := s.SearchByOrganizationNumber(context.Background(), "923609017")
+				return err
+			},
+			field: "organizationNumber",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, cfg := newFakeBisnode(t)
+			service := NewDirectoryService(NewDirectoryClient(cfg), nil)
+
+			err := tt.search(service)
+			ve, ok := validation.As(err)
+			if !ok {
+				t.Fatalf("error = %v, want a validation error", err)
+			}
+			if len(ve.Fields) != 1 || ve.Fields[0].Field != tt.field {
+				t.Errorf("validation error fields = %+v, want %s", ve.Fields, tt.field)
+			}
+			if n := len(fake.Requests()); n != 0 {
+				t.Errorf("Bisnode received %d requests for invalid input, want 0", n)
+			}
+		})
+	}
+}
+
+func TestDirectoryServiceSearchByOrganizationNumber(t *testing.T) {
+	fake, cfg := newFakeBisnode(t)
+	service := NewDirectoryService(NewDirectoryClient(cfg), nil)
+
+	resp, err := service.SearchByOrganizationNumber(context.Background(), "NO 923 609 016 MVA")
+	if err != nil {
+		t.Fatalf("SearchByOrganizationNumber() error = %v", err)
+	}
+	if len(resp.Result) != 1 || resp.Result[0].OrganizationNumber != "923609016" {
+		t.Fatalf("SearchByOrganizationNumber() = %+v, want 923609016", resp.Result)
+	}
+	if resp.Freshness != nil {
+		t.Errorf("Freshness = %+v, want nil without a cache", resp.Freshness)
+	}
+	if path := fake.Requests()[0].Path; path != "/search/norway/directory/923609016" {
+		t.Errorf("request path = %q, want the normalized organization number", path)
+	}
+}
+
+func TestDirectoryServiceSearchOrganizationsByName(t *testing.T) {
+	_, cfg := newFakeBisnode(t)
+	service := NewDirectoryService(NewDirectoryClient(cfg), nil)
+
+	resp, err := service.SearchOrganizationsByName(context.Background(), models.OrganizationSearchQuery{Name: "eksempel"})
+	if err != nil {
+		t.Fatalf("SearchOrganizationsByName() error = %v", err)
+	}
+	if len(resp.Result) != 1 || resp.Result[0].OrganizationNumber != "923609016" || resp.Result[0].Name != "Eksempel AS" {
+		t.Errorf("SearchOrganizationsByName() = %+v, want Eksempel AS", resp.Result)
+	}
+}
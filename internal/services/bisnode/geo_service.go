@@ -0,0 +1,192 @@
+package bisnode
+
+import (
+	"bisnode/internal/config"
+	"bisnode/internal/geo"
+	"bisnode/internal/models"
+	"bisnode/internal/validation"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// geoSearchConcurrency is the number of zip codes searched in parallel
+const geoSearchConcurrency = 4
+
+// ErrGeoSearchUnavailable is returned when no zip code index has been configured
+var ErrGeoSearchUnavailable = errors.New("geo search is not configured")
+
+// GeoSearchService searches the directory for listings near a location.
+// Bisnode cannot search by coordinates, so the zip codes around the location
+// are searched and the results are filtered by their distance.
+type GeoSearchService struct {
+	persons     PersonSearcher
+	search      searchDefaults
+	index       *geo.ZipIndex
+	maxRadius   float64
+	zipMargin   float64
+	maxZipCodes int
+}
+
+// NewGeoSearchService creates a new GeoSearchService. index may be nil, in
+// which case every search fails with ErrGeoSearchUnavailable. Each zip code
+// is searched through persons, so pass it decorated with the same caching,
+// metrics and auditing as other lookups. searchCfg provides the defaults and
+// limits for search options callers leave unset.
+func NewGeoSearchService(persons PersonSearcher, index *geo.ZipIndex, cfg *config.GeoConfig, searchCfg *config.SearchConfig) *GeoSearchService {
+	return &GeoSearchService{
+		persons:     persons,
+		search:      newSearchDefaults(searchCfg),
+		index:       index,
+		maxRadius:   float64(cfg.MaxRadiusMeters),
+		zipMargin:   float64(cfg.ZipMarginMeters),
+		maxZipCodes: cfg.MaxZipCodes,
+	}
+}
+
+// SearchNearby returns the listings within the queried area, nearest first
+func (s *GeoSearchService) SearchNearby(ctx context.Context, query models.GeoSearchQuery) (*models.GeoSearchResponse, error) {
+	if s.index == nil {
+		return nil, ErrGeoSearchUnavailable
+	}
+
+	center, radius, err := s.area(query)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := s.search.resolve(query.Options, models.ListingTypeAll)
+	if err != nil {
+		return nil, err
+	}
+
+	zipCodes := s.index.Nearby(center, radius+s.zipMargin)
+	if len(zipCodes) > s.maxZipCodes {
+		zipCodes = zipCodes[:s.maxZipCodes]
+	}
+
+	listings, err := s.searchZipCodes(ctx, zipCodes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []models.GeoSearchResult{}
+	seen := make(map[string]bool)
+	for _, r := range listings {
+		p := geo.Point{Latitude: r.Latitude, Longitude: r.Longitude}
+		if p.IsZero() {
+			continue
+		}
+
+		distance := geo.Distance(center, p)
+		if query.BoundingBox != nil {
+			if !query.BoundingBox.Contains(p) {
+				continue
+			}
+		} else if distance > radius {
+			continue
+		}
+
+		key := listingKey(r)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		results = append(results, models.GeoSearchResult{DirectoryResult: r, DistanceMeters: distance})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].DistanceMeters < results[j].DistanceMeters
+	})
+	if len(results) > opts.ResultLimit {
+		results = results[:opts.ResultLimit]
+	}
+
+	return &models.GeoSearchResponse{
+		Center:       center,
+		RadiusMeters: radius,
+		Result:       results,
+	}, nil
+}
+
+// area validates the query and returns the center and radius to search
+func (s *GeoSearchService) area(query models.GeoSearchQuery) (geo.Point, float64, error) {
+	switch {
+	case query.BoundingBox != nil && query.Center != nil:
+		return geo.Point{}, 0, validation.Errorf("boundingBox", "cannot be combined with a center point")
+	case query.BoundingBox != nil:
+		if !query.BoundingBox.Valid() {
+			return geo.Point{}, 0, validation.Errorf("boundingBox", "must have valid coordinates with min south-west of max")
+		}
+		radius := query.BoundingBox.Radius()
+		if radius > s.maxRadius {
+			return geo.Point{}, 0, validation.Errorf("boundingBox", "must fit within a radius of %.0f meters", s.maxRadius)
+		}
+		return query.BoundingBox.Center(), radius, nil
+	case query.Center != nil:
+		if !query.Center.Valid() {
+			return geo.Point{}, 0, validation.Errorf("latitude", "%v", geo.ErrInvalidCoordinate)
+		}
+		// Written so that a NaN radius, which fails every comparison, is rejected
+		if !(query.RadiusMeters > 0 && query.RadiusMeters <= s.maxRadius) {
+			return geo.Point{}, 0, validation.Errorf("radius", "must be between 1 and %.0f meters", s.maxRadius)
+		}
+		return *query.Center, query.RadiusMeters, nil
+	}
+
+	return geo.Point{}, 0, validation.Errorf("latitude", "a center point or bounding box is required")
+}
+
+// searchZipCodes searches the directory for each zip code with bounded
+// concurrency and returns all listings found
+func (s *GeoSearchService) searchZipCodes(ctx context.Context, zipCodes []geo.ZipCode, opts models.SearchOptions) ([]models.DirectoryResult, error) {
+	// Fetch as much as possible per zip code, since most listings will be
+	// filtered out by distance. The caller's limit applies to the final result.
+	opts.ResultLimit = s.search.maxResultLimit
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		listings []models.DirectoryResult
+		firstErr error
+	)
+	sem := make(chan struct{}, geoSearchConcurrency)
+
+	for _, zip := range zipCodes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(zip geo.ZipCode) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := s.persons.SearchByZipCode(ctx, zip.Code, opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("error searching zip code %s: %w", zip.Code, err)
+				}
+				return
+			}
+			listings = append(listings, result.Result...)
+		}(zip)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return listings, nil
+}
+
+// listingKey identifies a listing so that it is only returned once when it
+// is found through several zip codes
+func listingKey(r models.DirectoryResult) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s|%s",
+		r.Type, r.OrganizationNumber, r.FirstName, r.LastName,
+		r.StreetName, r.HouseNo, r.ZipCode, r.Mobile)
+}
@@ -0,0 +1,67 @@
+package bisnode
+
+import (
+	"bisnode/internal/domain"
+	"bisnode/internal/models"
+	"bisnode/internal/vehicleid"
+	"context"
+)
+
+// DirectoryResponse is a Bisnode directory search response and how current it is
+type DirectoryResponse struct {
+	models.DirectorySearchResponse
+	// Freshness is set when the response was served from cache
+	Freshness *domain.Freshness
+}
+
+// OrganizationsResponse is the result of an organization name search and how current it is
+type OrganizationsResponse struct {
+	models.OrganizationSearchResponse
+	// Freshness is set when the response was served from cache
+	Freshness *domain.Freshness
+}
+
+// VehicleResponse is a Bisnode motor vehicle search response, the identifier
+// that was searched for and how current the response is
+type VehicleResponse struct {
+	models.MotorVehicleSearchResponse
+	// Identifier is the classified search term, including decoded VIN information
+	Identifier vehicleid.Identifier
+	// Freshness is set when the response was served from cache
+	Freshness *domain.Freshness
+}
+
+// PersonSearcher finds persons in the directory. Invalid input is rejected
+// with a validation.Error before Bisnode is called.
+type PersonSearcher interface {
+	// SearchByMobileNumber searches for a person by Norwegian mobile number
+	SearchByMobileNumber(ctx context.Context, mobileNumber string, opts models.SearchOptions) (*DirectoryResponse, error)
+	// SearchPersons searches for persons by name and address, best match first
+	SearchPersons(ctx context.Context, query models.PersonSearchQuery) (*DirectoryResponse, error)
+	// SearchByZipCode lists everyone in a zip code, persons and companies alike
+	SearchByZipCode(ctx context.Context, zipCode string, opts models.SearchOptions) (*DirectoryResponse, error)
+}
+
+// OrganizationLookup finds organizations in the directory. Invalid input is
+// rejected with a validation.Error before Bisnode is called.
+type OrganizationLookup interface {
+	// SearchByOrganizationNumber looks up a company by organization number
+	SearchByOrganizationNumber(ctx context.Context, orgNo string) (*DirectoryResponse, error)
+	// SearchOrganizationsByName searches for companies by name
+	SearchOrganizationsByName(ctx context.Context, query models.OrganizationSearchQuery) (*OrganizationsResponse, error)
+}
+
+// VehicleLookup finds motor vehicles. Invalid input is rejected with a
+// validation.Error before Bisnode is called.
+type VehicleLookup interface {
+	// SearchByLicenseNumber looks up a vehicle by license plate or VIN
+	SearchByLicenseNumber(ctx context.Context, licenseNumber string) (*VehicleResponse, error)
+	// SearchByVIN looks up a vehicle by VIN only
+	SearchByVIN(ctx context.Context, vin string) (*VehicleResponse, error)
+}
+
+var (
+	_ PersonSearcher     = (*DirectoryService)(nil)
+	_ OrganizationLookup = (*DirectoryService)(nil)
+	_ VehicleLookup      = (*MotorVehicleService)(nil)
+)
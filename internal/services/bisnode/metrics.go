@@ -0,0 +1,178 @@
+package bisnode
+
+import (
+	"bisnode/internal/models"
+	"bisnode/internal/validation"
+	"context"
+	"sync"
+	"time"
+)
+
+// OperationStats holds the counters of one lookup operation
+type OperationStats struct {
+	// Calls is the number of lookups, including failed ones
+	Calls int64 `json:"calls"`
+	// Invalid is the number of lookups rejected as invalid input
+	Invalid int64 `json:"invalid"`
+	// Failed is the number of lookups that failed for any other reason
+	Failed int64 `json:"failed"`
+	// AverageMillis and MaxMillis are the lookup durations in milliseconds
+	AverageMillis float64 `json:"averageMillis"`
+	MaxMillis     float64 `json:"maxMillis"`
+}
+
+// Metrics counts the lookups made through the metered decorators. It is safe
+// for concurrent use.
+type Metrics struct {
+	mu         sync.Mutex
+	since      time.Time
+	operations map[string]*operationCounters
+	now        func() time.Time
+}
+
+// operationCounters accumulates the stats of one operation
+type operationCounters struct {
+	calls, invalid, failed int64
+	total, max             time.Duration
+}
+
+// NewMetrics creates a new Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		since:      time.Now(),
+		operations: make(map[string]*operationCounters),
+		now:        time.Now,
+	}
+}
+
+// Since returns when counting started
+func (m *Metrics) Since() time.Time {
+	return m.since
+}
+
+// Snapshot returns the current stats by operation name, such as "persons.searchByMobileNumber"
+func (m *Metrics) Snapshot() map[string]OperationStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]OperationStats, len(m.operations))
+	for name, c := range m.operations {
+		s := OperationStats{
+			Calls:     c.calls,
+			Invalid:   c.invalid,
+			Failed:    c.failed,
+			MaxMillis: float64(c.max) / float64(time.Millisecond),
+		}
+		if c.calls > 0 {
+			s.AverageMillis = float64(c.total) / float64(c.calls) / float64(time.Millisecond)
+		}
+		stats[name] = s
+	}
+	return stats
+}
+
+// observe records a lookup that started at start and ended with err
+func (m *Metrics) observe(operation string, start time.Time, err error) {
+	elapsed := m.now().Sub(start)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.operations[operation]
+	if !ok {
+		c = &operationCounters{}
+		m.operations[operation] = c
+	}
+	c.calls++
+	c.total += elapsed
+	c.max = max(c.max, elapsed)
+	if err != nil {
+		if _, ok := validation.As(err); ok {
+			c.invalid++
+		} else {
+			c.failed++
+		}
+	}
+}
+
+// meteredPersonSearcher counts the lookups of a PersonSearcher
+type meteredPersonSearcher struct {
+	next    PersonSearcher
+	metrics *Metrics
+}
+
+// NewMeteredPersonSearcher counts the lookups of next in metrics
+func NewMeteredPersonSearcher(next PersonSearcher, metrics *Metrics) PersonSearcher {
+	return &meteredPersonSearcher{next: next, metrics: metrics}
+}
+
+func (s *meteredPersonSearcher) SearchByMobileNumber(ctx context.Context, mobileNumber string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	start := s.metrics.now()
+	resp, err := s.next.SearchByMobileNumber(ctx, mobileNumber, opts)
+	s.metrics.observe("persons.searchByMobileNumber", start, err)
+	return resp, err
+}
+
+func (s *meteredPersonSearcher) SearchPersons(ctx context.Context, query models.PersonSearchQuery) (*DirectoryResponse, error) {
+	start := s.metrics.now()
+	resp, err := s.next.SearchPersons(ctx, query)
+	s.metrics.observe("persons.searchPersons", start, err)
+	return resp, err
+}
+
+func (s *meteredPersonSearcher) SearchByZipCode(ctx context.Context, zipCode string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	start := s.metrics.now()
+	resp, err := s.next.SearchByZipCode(ctx, zipCode, opts)
+	s.metrics.observe("persons.searchByZipCode", start, err)
+	return resp, err
+}
+
+// meteredOrganizationLookup counts the lookups of an OrganizationLookup
+type meteredOrganizationLookup struct {
+	next    OrganizationLookup
+	metrics *Metrics
+}
+
+// NewMeteredOrganizationLookup counts the lookups of next in metrics
+func NewMeteredOrganizationLookup(next OrganizationLookup, metrics *Metrics) OrganizationLookup {
+	return &meteredOrganizationLookup{next: next, metrics: metrics}
+}
+
+func (s *meteredOrganizationLookup) SearchByOrganizationNumber(ctx context.Context, orgNo string) (*DirectoryResponse, error) {
+	start := s.metrics.now()
+	resp, err := s.next.SearchByOrganizationNumber(ctx, orgNo)
+	s.metrics.observe("organizations.searchByOrganizationNumber", start, err)
+	return resp, err
+}
+
+func (s *meteredOrganizationLookup) SearchOrganizationsByName(ctx context.Context, query models.OrganizationSearchQuery) (*OrganizationsResponse, error) {
+	start := s.metrics.now()
+	resp, err := s.next.SearchOrganizationsByName(ctx, query)
+	s.metrics.observe("organizations.searchOrganizationsByName", start, err)
+	return resp, err
+}
+
+// meteredVehicleLookup counts the lookups of a VehicleLookup
+type meteredVehicleLookup struct {
+	next    VehicleLookup
+	metrics *Metrics
+}
+
+// NewMeteredVehicleLookup counts the lookups of next in metrics
+func NewMeteredVehicleLookup(next VehicleLookup, metrics *Metrics) VehicleLookup {
+	return &meteredVehicleLookup{next: next, metrics: metrics}
+}
+
+func (s *meteredVehicleLookup) SearchByLicenseNumber(ctx context.Context, licenseNumber string) (*VehicleResponse, error) {
+	start := s.metrics.now()
+	resp, err := s.next.SearchByLicenseNumber(ctx, licenseNumber)
+	s.metrics.observe("vehicles.searchByLicenseNumber", start, err)
+	return resp, err
+}
+
+func (s *meteredVehicleLookup) SearchByVIN(ctx context.Context, vin string) (*VehicleResponse, error) {
+	start := s.metrics.now()
+	resp, err := s.next.SearchByVIN(ctx, vin)
+	s.metrics.observe("vehicles.searchByVIN", start, err)
+	return resp, err
+}
@@ -0,0 +1,53 @@
+package bisnode
+
+import (
+	"bisnode/internal/models"
+	"bisnode/internal/validation"
+	"context"
+	"errors"
+	"testing"
+)
+
+// stubPersonSearcher answers person searches without Bisnode
+type stubPersonSearcher struct {
+	resp *DirectoryResponse
+	err  error
+}
+
+func (s stubPersonSearcher) SearchByMobileNumber(ctx context.Context, mobileNumber string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	return s.resp, s.err
+}
+
+func (s stubPersonSearcher) SearchPersons(ctx context.Context, query models.PersonSearchQuery) (*DirectoryResponse, error) {
+	return s.resp, s.err
+}
+
+func (s stubPersonSearcher) SearchByZipCode(ctx context.Context, zipCode string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	return s.resp, s.err
+}
+
+func TestMeteredPersonSearcher(t *testing.T) {
+	metrics := NewMetrics()
+	ok := NewMeteredPersonSearcher(stubPersonSearcher{resp: &DirectoryResponse{}}, metrics)
+	invalid := NewMeteredPersonSearcher(stubPersonSearcher{err: validation.Errorf("mobileNumber", "is invalid")}, metrics)
+	failed := NewMeteredPersonSearcher(stubPersonSearcher{err: errors.New("status 502")}, metrics)
+
+	ctx := context.Background()
+	ok.SearchByMobileNumber(ctx, "91234567", models.SearchOptions{})
+	ok.SearchByMobileNumber(ctx, "91234567", models.SearchOptions{})
+	invalid.SearchByMobileNumber(ctx, "1", models.SearchOptions{})
+	failed.SearchByMobileNumber(ctx, "91234567", models.SearchOptions{})
+	ok.SearchPersons(ctx, models.PersonSearchQuery{LastName: "Nordmann"})
+
+	stats := metrics.Snapshot()
+	mobile := stats["persons.searchByMobileNumber"]
+	if mobile.Calls != 4 || mobile.Invalid != 1 || mobile.Failed != 1 {
+		t.Errorf("searchByMobileNumber stats = %+v, want 4 calls, 1 invalid and 1 failed", mobile)
+	}
+	if persons := stats["persons.searchPersons"]; persons.Calls != 1 || persons.Invalid != 0 || persons.Failed != 0 {
+		t.Errorf("searchPersons stats = %+v, want 1 successful call", persons)
+	}
+	if len(stats) != 2 {
+		t.Errorf("Snapshot() has %d operations, want 2", len(stats))
+	}
+}
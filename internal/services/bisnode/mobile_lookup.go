@@ -0,0 +1,50 @@
+package bisnode
+
+import (
+	"bisnode/internal/models"
+	"context"
+	"sync"
+)
+
+// mobileLookupConcurrency is the number of mobile numbers looked up in parallel
+const mobileLookupConcurrency = 4
+
+// MobileLookup is the outcome of looking up a single mobile number
+type MobileLookup struct {
+	// Number is the mobile number as given by the caller
+	Number string
+	Result *DirectoryResponse
+	Err    error
+}
+
+// LookupMobileNumbers searches persons for each mobile number with bounded
+// concurrency. Lookups are returned in the order of numbers, and a failed
+// lookup does not stop the others. Numbers not yet looked up when ctx is
+// cancelled fail with the context's error.
+func LookupMobileNumbers(ctx context.Context, persons PersonSearcher, numbers []string, opts models.SearchOptions) []MobileLookup {
+	lookups := make([]MobileLookup, len(numbers))
+	sem := make(chan struct{}, mobileLookupConcurrency)
+	var wg sync.WaitGroup
+
+	for i, number := range numbers {
+		lookups[i].Number = number
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			lookups[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(l *MobileLookup) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			l.Result, l.Err = persons.SearchByMobileNumber(ctx, l.Number, opts)
+		}(&lookups[i])
+	}
+	wg.Wait()
+
+	return lookups
+}
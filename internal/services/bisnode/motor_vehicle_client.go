@@ -3,6 +3,8 @@ package bisnode
 import (
 	"bisnode/internal/config"
 	"bisnode/internal/models"
+	"bisnode/internal/orgno"
+	"bisnode/internal/vehicleid"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -11,7 +13,6 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"time"
 )
 
 // MotorVehicleClient handles communication with the Bisnode Motor Vehicle API
@@ -27,21 +28,17 @@ func NewMotorVehicleClient(cfg *config.BisnodeConfig) *MotorVehicleClient {
 	auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
 
 	return &MotorVehicleClient{
-		baseURL:    "https://api.bisnode.no",
+		baseURL:    baseURL(cfg),
 		authHeader: auth,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		httpClient: newHTTPClient(cfg),
 	}
 }
 
-// SearchByLicenseNumber searches for a vehicle by license number or VIN
-func (c *MotorVehicleClient) SearchByLicenseNumber(ctx context.Context, searchTerm string) (*models.MotorVehicleSearchResponse, error) {
-	log.Printf("Searching for motor vehicle with search term: %s", searchTerm)
-
-	if searchTerm == "" {
-		return nil, fmt.Errorf("search term cannot be empty")
-	}
+// Search looks up a vehicle by a classified identifier. Input is validated
+// by MotorVehicleService; see vehicleid.Parse.
+func (c *MotorVehicleClient) Search(ctx context.Context, id vehicleid.Identifier) (*models.MotorVehicleSearchResponse, error) {
+	searchTerm := id.Value
+	log.Printf("Searching for motor vehicle with search term: %s (%s)", searchTerm, id.Kind)
 
 	// URL encode the search term to handle special characters
 	encodedTerm := url.QueryEscape(searchTerm)
@@ -101,12 +98,28 @@ func (c *MotorVehicleClient) SearchByLicenseNumber(ctx context.Context, searchTe
 		return nil, err
 	}
 
+	for i := range result.Result {
+		normalizeOwners(&result.Result[i])
+	}
+
 	log.Printf("Successfully retrieved motor vehicle data")
 	return &result, nil
 }
 
-// SearchByVIN searches for a vehicle by VIN (Vehicle Identification Number)
-func (c *MotorVehicleClient) SearchByVIN(ctx context.Context, vin string) (*models.MotorVehicleSearchResponse, error) {
-	// VIN search uses the same endpoint as license number search
-	return c.SearchByLicenseNumber(ctx, vin)
+// normalizeOwners rewrites the organization numbers of a vehicle's owners to
+// the canonical 9-digit form. Numbers that fail validation are left as-is
+// since they are Bisnode's data, not caller input.
+func normalizeOwners(v *models.MotorVehicle) {
+	for _, owner := range []*models.Owner{&v.Owner, &v.CoOwner, &v.LeasingUser} {
+		if owner.OrganizationNumber == "" {
+			continue
+		}
+		orgNo, err := orgno.Parse(owner.OrganizationNumber)
+		if err != nil {
+			log.Printf("Vehicle %s has owner with invalid organization number %q: %v", v.RegNo, owner.OrganizationNumber, err)
+			continue
+		}
+		owner.OrganizationNumber = orgNo
+	}
 }
+
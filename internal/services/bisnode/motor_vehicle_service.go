@@ -0,0 +1,54 @@
+package bisnode
+
+import (
+	"bisnode/internal/validation"
+	"bisnode/internal/vehicleid"
+	"context"
+	"fmt"
+)
+
+// MotorVehicleService handles motor vehicle search operations
+type MotorVehicleService struct {
+	client *MotorVehicleClient
+}
+
+// NewMotorVehicleService creates a new MotorVehicleService
+func NewMotorVehicleService(client *MotorVehicleClient) *MotorVehicleService {
+	return &MotorVehicleService{
+		client: client,
+	}
+}
+
+// SearchByLicenseNumber searches for a vehicle by license number or VIN
+func (s *MotorVehicleService) SearchByLicenseNumber(ctx context.Context, licenseNumber string) (*VehicleResponse, error) {
+	// Bisnode accepts both plates and VINs, so either is allowed here
+	id, err := vehicleid.Parse(licenseNumber)
+	if err != nil {
+		return nil, validation.Errorf("licenseNumber", "%v", err)
+	}
+
+	return s.search(ctx, id)
+}
+
+// SearchByVIN searches for a vehicle by VIN (Vehicle Identification Number)
+func (s *MotorVehicleService) SearchByVIN(ctx context.Context, vin string) (*VehicleResponse, error) {
+	id, err := vehicleid.Parse(vin)
+	if err == nil && id.Kind != vehicleid.KindVIN {
+		err = vehicleid.ErrInvalidVINLength
+	}
+	if err != nil {
+		return nil, validation.Errorf("vin", "%v", err)
+	}
+
+	return s.search(ctx, id)
+}
+
+// search looks up a validated vehicle identifier
+func (s *MotorVehicleService) search(ctx context.Context, id vehicleid.Identifier) (*VehicleResponse, error) {
+	result, err := s.client.Search(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error searching motor vehicles: %w", err)
+	}
+
+	return &VehicleResponse{MotorVehicleSearchResponse: *result, Identifier: id}, nil
+}
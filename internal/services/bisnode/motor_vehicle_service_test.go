@@ -0,0 +1,120 @@
+package bisnode
+
+import (
+	"bisnode/internal/bisnodefake"
+	"bisnode/internal/validation"
+	"bisnode/internal/vehicleid"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMotorVehicleServiceSearchByLicenseNumber(t *testing.T) {
+	fake, cfg := newFakeBisnode(t)
+	service := NewMotorVehicleService(NewMotorVehicleClient(cfg))
+
+	resp, err := service.SearchByLicenseNumber(context.Background(), "ab 12-345")
+	if err != nil {
+		t.Fatalf("SearchByLicenseNumber() error = %v", err)
+	}
+	if len(resp.Result) != 1 || resp.Result[0].BrandName != "BMW" {
+		t.Fatalf("SearchByLicenseNumber() = %+v, want the BMW", resp.Result)
+	}
+	if resp.Identifier.Kind != vehicleid.KindStandardPlate || resp.Identifier.Value != "AB12345" {
+		t.Errorf("Identifier = %+v, want standard plate AB12345", resp.Identifier)
+	}
+	if got := resp.Result[0].Owner.OrganizationNumber; got != "923609016" {
+		t.Errorf("Owner.OrganizationNumber = %q, want 923609016", got)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 1 || requests[0].Method != http.MethodGet || requests[0].Path != "/search/norway/motorvehicle/v2/AB12345" {
+		t.Errorf("Bisnode received %+v, want one GET /search/norway/motorvehicle/v2/AB12345", requests)
+	}
+}
+
+func TestMotorVehicleServiceSearchByVIN(t *testing.T) {
+	fake, cfg := newFakeBisnode(t)
+	service := NewMotorVehicleService(NewMotorVehicleClient(cfg))
+
+	resp, err := service.SearchByVIN(context.Background(), "wbakg7c5xbe123456")
+	if err != nil {
+		t.Fatalf("SearchByVIN() error = %v", err)
+	}
+	if len(resp.Result) != 1 || resp.Result[0].RegNo != "AB12345" {
+		t.Fatalf("SearchByVIN() = %+v, want AB12345", resp.Result)
+	}
+	if resp.Identifier.VIN == nil || resp.Identifier.VIN.Manufacturer != "BMW" {
+		t.Errorf("Identifier = %+v, want a decoded BMW VIN", resp.Identifier)
+	}
+	if n := len(fake.Requests()); n != 1 {
+		t.Errorf("Bisnode received %d requests, want 1", n)
+	}
+}
+
+func TestMotorVehicleServiceRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		search func(*MotorVehicleService) error
+		field  string
+	}{
+		{
+			name: "invalid plate",
+			search: func(s *MotorVehicleService) error {
+				_, err := s.SearchByLicenseNumber(context.Background(), "AB!2345")
+				return err
+			},
+			field: "licenseNumber",
+		},
+		{
+			name: "plate given as vin",
+			search: func(s *MotorVehicleService) error {
+				_, err := s.SearchByVIN(context.Background(), "AB12345")
+				return err
+			},
+			field: "vin",
+		},
+		{
+			name: "vin with wrong check digit",
+			search: func(s *MotorVehicleService) error {
+				_, err := s.SearchByVIN(context.Background(), "1HGCM82643A004352")
+				return err
+			},
+			field: "vin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, cfg := newFakeBisnode(t)
+			service := NewMotorVehicleService(NewMotorVehicleClient(cfg))
+
+			err := tt.search(service)
+			ve, ok := validation.As(err)
+			if !ok {
+				t.Fatalf("error = %v, want a validation error", err)
+			}
+			if len(ve.Fields) != 1 || ve.Fields[0].Field != tt.field {
+				t.Errorf("validation error fields = %+v, want %s", ve.Fields, tt.field)
+			}
+			if n := len(fake.Requests()); n != 0 {
+				t.Errorf("Bisnode received %d requests for invalid input, want 0", n)
+			}
+		})
+	}
+}
+
+func TestMotorVehicleServiceUpstreamError(t *testing.T) {
+	fake, cfg := newFakeBisnode(t)
+	fake.Fail(bisnodefake.Fault{Path: "/search/norway/motorvehicle", Status: http.StatusBadGateway})
+	service := NewMotorVehicleService(NewMotorVehicleClient(cfg))
+
+	_, err := service.SearchByLicenseNumber(context.Background(), "AB12345")
+	if err == nil || !strings.Contains(err.Error(), "status 502") {
+		t.Errorf("SearchByLicenseNumber() error = %v, want status 502", err)
+	}
+	if _, ok := validation.As(err); ok {
+		t.Errorf("SearchByLicenseNumber() error = %v, want an upstream error, not a validation error", err)
+	}
+}
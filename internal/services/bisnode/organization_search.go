@@ -0,0 +1,92 @@
+package bisnode
+
+import (
+	"bisnode/internal/models"
+	"bisnode/internal/orgno"
+	"bisnode/internal/validation"
+	"strings"
+)
+
+// normalizeOrganizationQuery trims the query fields
+func normalizeOrganizationQuery(q models.OrganizationSearchQuery) models.OrganizationSearchQuery {
+	q.Name = strings.Join(strings.Fields(q.Name), " ")
+	q.City = strings.Join(strings.Fields(q.City), " ")
+	q.ZipCode = strings.ReplaceAll(q.ZipCode, " ", "")
+	return q
+}
+
+// validateOrganizationQuery checks that a normalized query is specific enough to search for
+func validateOrganizationQuery(q models.OrganizationSearchQuery) error {
+	var fieldErrs []validation.FieldError
+
+	if len([]rune(q.Name)) < 2 {
+		fieldErrs = append(fieldErrs, validation.FieldError{Field: "name", Message: "must be at least 2 characters"})
+	}
+	if q.ZipCode != "" && !validZipCode(q.ZipCode) {
+		fieldErrs = append(fieldErrs, validation.FieldError{Field: "zipCode", Message: "must be 4 digits"})
+	}
+	if q.Options.ListingType != nil && *q.Options.ListingType != models.ListingTypeCompany {
+		fieldErrs = append(fieldErrs, validation.FieldError{Field: "listingType", Message: "must be company for an organization search"})
+	}
+
+	if len(fieldErrs) > 0 {
+		return &validation.Error{Fields: fieldErrs}
+	}
+	return nil
+}
+
+// organizationSearchString builds the Bisnode freetext query from the name and filters
+func organizationSearchString(q models.OrganizationSearchQuery) string {
+	parts := []string{q.Name}
+	if q.ZipCode != "" {
+		parts = append(parts, q.ZipCode)
+	}
+	if q.City != "" {
+		parts = append(parts, q.City)
+	}
+	return strings.Join(parts, " ")
+}
+
+// summarizeOrganizations converts directory results into organization
+// summaries. Freetext matching is fuzzy, so results outside the city and zip
+// code filters are dropped here, as are listings without an organization
+// number and duplicate listings of the same organization.
+func summarizeOrganizations(results []models.DirectoryResult, q models.OrganizationSearchQuery) []models.OrganizationSummary {
+	summaries := []models.OrganizationSummary{}
+	seen := make(map[string]bool)
+
+	for _, r := range results {
+		orgNo, err := orgno.Parse(r.OrganizationNumber)
+		if err != nil || seen[orgNo] {
+			continue
+		}
+		if q.ZipCode != "" && r.ZipCode != q.ZipCode {
+			continue
+		}
+		if q.City != "" && !strings.EqualFold(r.City, q.City) {
+			continue
+		}
+		seen[orgNo] = true
+
+		summaries = append(summaries, models.OrganizationSummary{
+			OrganizationNumber: orgNo,
+			Name:               organizationName(r),
+			StreetName:         r.StreetName,
+			HouseNo:            strings.TrimSpace(r.HouseNo + r.Entrance),
+			ZipCode:            r.ZipCode,
+			City:               r.City,
+		})
+
+		if len(summaries) == q.Options.ResultLimit {
+			break
+		}
+	}
+
+	return summaries
+}
+
+// organizationName returns the name of a company listing, which Bisnode
+// stores in the last name field
+func organizationName(r models.DirectoryResult) string {
+	return strings.TrimSpace(strings.Join(strings.Fields(r.FirstName+" "+r.MiddleName+" "+r.LastName), " "))
+}
@@ -0,0 +1,149 @@
+package bisnode
+
+import (
+	"bisnode/internal/models"
+	"bisnode/internal/validation"
+	"sort"
+	"strings"
+)
+
+// normalizePersonQuery trims the query fields
+func normalizePersonQuery(q models.PersonSearchQuery) models.PersonSearchQuery {
+	q.FirstName = strings.Join(strings.Fields(q.FirstName), " ")
+	q.LastName = strings.Join(strings.Fields(q.LastName), " ")
+	q.Street = strings.Join(strings.Fields(q.Street), " ")
+	q.ZipCode = strings.ReplaceAll(q.ZipCode, " ", "")
+	q.City = strings.Join(strings.Fields(q.City), " ")
+	return q
+}
+
+// validatePersonQuery checks that a normalized query is specific enough to search for
+func validatePersonQuery(q models.PersonSearchQuery) error {
+	var fieldErrs []validation.FieldError
+
+	if q.FirstName == "" && q.LastName == "" && q.Street == "" {
+		fieldErrs = append(fieldErrs, validation.FieldError{
+			Field:   "lastName",
+			Message: "a first name, last name or street is required",
+		})
+	}
+	if q.ZipCode != "" && !validZipCode(q.ZipCode) {
+		fieldErrs = append(fieldErrs, validation.FieldError{Field: "zipCode", Message: "must be 4 digits"})
+	}
+
+	if len(fieldErrs) > 0 {
+		return &validation.Error{Fields: fieldErrs}
+	}
+	return nil
+}
+
+// validZipCode reports whether zip is a 4-digit Norwegian zip code
+func validZipCode(zip string) bool {
+	return len(zip) == 4 && strings.Trim(zip, "0123456789") == ""
+}
+
+// personSearchString builds the Bisnode freetext query from the structured fields
+func personSearchString(q models.PersonSearchQuery) string {
+	var parts []string
+	for _, p := range []string{q.FirstName, q.LastName, q.Street, q.ZipCode, q.City} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// rankPersons returns the results ordered by how well they match the query,
+// best match first. Results with equal scores keep Bisnode's order.
+func rankPersons(results []models.DirectoryResult, q models.PersonSearchQuery) []models.DirectoryResult {
+	type scored struct {
+		result models.DirectoryResult
+		score  int
+	}
+
+	items := make([]scored, len(results))
+	for i, r := range results {
+		items[i] = scored{result: r, score: personScore(&r, q)}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].score > items[j].score
+	})
+
+	ranked := make([]models.DirectoryResult, len(items))
+	for i, item := range items {
+		ranked[i] = item.result
+	}
+
+	if limit := q.Options.ResultLimit; limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return ranked
+}
+
+// personScore scores a result against the query. Exact matches on a field
+// count more than prefix matches, and names count more than address fields.
+func personScore(r *models.DirectoryResult, q models.PersonSearchQuery) int {
+	score := 0
+
+	if q.FirstName != "" {
+		given := strings.TrimSpace(r.FirstName + " " + r.MiddleName)
+		score += matchScore(given, q.FirstName, 10)
+	}
+	if q.LastName != "" {
+		score += matchScore(r.LastName, q.LastName, 10)
+	}
+	if q.Street != "" {
+		street, houseNo := splitStreet(q.Street)
+		score += matchScore(r.StreetName, street, 6)
+		if houseNo != "" && strings.EqualFold(r.HouseNo+r.Entrance, houseNo) {
+			score += 2
+		}
+	}
+	if q.ZipCode != "" && r.ZipCode == q.ZipCode {
+		score += 4
+	}
+	if q.City != "" {
+		score += matchScore(r.City, q.City, 4)
+	}
+
+	return score
+}
+
+// matchScore returns weight for a case-insensitive exact match, half of it
+// when every word in want starts a word in got, and 0 otherwise
+func matchScore(got, want string, weight int) int {
+	got, want = strings.ToLower(got), strings.ToLower(want)
+	if got == want {
+		return weight
+	}
+
+	gotWords := strings.Fields(got)
+	for _, w := range strings.Fields(want) {
+		found := false
+		for _, g := range gotWords {
+			if strings.HasPrefix(g, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+	return weight / 2
+}
+
+// splitStreet splits "Storgata 5B" into the street name and house number
+func splitStreet(street string) (string, string) {
+	i := strings.LastIndex(street, " ")
+	if i < 0 {
+		return street, ""
+	}
+	houseNo := street[i+1:]
+	if houseNo == "" || houseNo[0] < '0' || houseNo[0] > '9' {
+		return street, ""
+	}
+	return street[:i], houseNo
+}
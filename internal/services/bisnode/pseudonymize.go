@@ -0,0 +1,177 @@
+package bisnode
+
+import (
+	"bisnode/internal/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	pseudoFirstNames = []string{"Ola", "Kari", "Per", "Anne", "Lars", "Ingrid", "Nils", "Marit", "Jon", "Solveig", "Erik", "Astrid"}
+	pseudoLastNames  = []string{"Nordmann", "Hansen", "Johansen", "Olsen", "Larsen", "Andersen", "Pedersen", "Nilsen", "Berg", "Haugen"}
+	pseudoStreets    = []string{"Eksempelveien", "Testgata", "Prøvestien", "Fiktivveien", "Demogata", "Skissebakken"}
+)
+
+// pseudonymizer replaces personal data in Bisnode responses with fictional
+// values before they are recorded. The values are derived from the original
+// with a keyed hash, so a person keeps the same pseudonym across recordings
+// while the original cannot be recovered without the key. Companies are
+// public records and are left as-is.
+type pseudonymizer struct {
+	key []byte
+}
+
+// newPseudonymizer creates a pseudonymizer deriving pseudonyms from key
+func newPseudonymizer(key string) *pseudonymizer {
+	return &pseudonymizer{key: []byte(key)}
+}
+
+// Sanitize pseudonymizes a directory or motor vehicle response body. Error
+// bodies may echo the search string, so they are replaced by the status text.
+func (p *pseudonymizer) Sanitize(resp *http.Response, body []byte) ([]byte, error) {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return []byte(http.StatusText(resp.StatusCode)), nil
+	}
+
+	switch path := resp.Request.URL.Path; {
+	case strings.Contains(path, "/motorvehicle/"):
+		var resp models.MotorVehicleSearchResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+		for i := range resp.Result {
+			p.owner(&resp.Result[i].Owner)
+			p.owner(&resp.Result[i].CoOwner)
+			p.owner(&resp.Result[i].LeasingUser)
+		}
+		return json.Marshal(resp)
+
+	case strings.Contains(path, "/directory"):
+		var resp models.DirectorySearchResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+		for i := range resp.Result {
+			p.directoryResult(&resp.Result[i])
+		}
+		return json.Marshal(resp)
+	}
+
+	return body, nil
+}
+
+// directoryResult pseudonymizes a person listing
+func (p *pseudonymizer) directoryResult(d *models.DirectoryResult) {
+	if strings.EqualFold(d.Type, "company") {
+		return
+	}
+
+	identity := d.FirstName + " " + d.LastName + " " + d.Born
+	d.FirstName = p.pick("first", identity, pseudoFirstNames)
+	if d.MiddleName != "" {
+		d.MiddleName = p.pick("middle", identity, pseudoFirstNames)
+	}
+	d.LastName = p.pick("last", identity, pseudoLastNames)
+	d.Born = yearOnly(d.Born)
+	d.Dead = yearOnly(d.Dead)
+	// Months and days of the age would give away the exact birth date
+	d.Age.Month, d.Age.Day = 0, 0
+
+	d.StreetName, d.HouseNo = p.street(d.StreetName, d.HouseNo)
+	d.Longitude, d.Latitude = coarse(d.Longitude), coarse(d.Latitude)
+	d.Telephone = p.phoneNumber(d.Telephone)
+	d.Mobile = p.phoneNumber(d.Mobile)
+
+	for i := range d.Addresses {
+		a := &d.Addresses[i]
+		a.StreetName, a.HouseNo = p.street(a.StreetName, a.HouseNo)
+		a.Longitude, a.Latitude = coarse(a.Longitude), coarse(a.Latitude)
+	}
+	for i := range d.Phones {
+		d.Phones[i].Number = p.phoneNumber(d.Phones[i].Number)
+	}
+}
+
+// owner pseudonymizes a vehicle owner who is a person
+func (p *pseudonymizer) owner(o *models.Owner) {
+	if o.OrganizationNumber != "" || o.Name == "" {
+		return
+	}
+
+	identity := o.Name + " " + o.Born
+	o.Name = p.pick("first", identity, pseudoFirstNames) + " " + p.pick("last", identity, pseudoLastNames)
+	o.Born = yearOnly(o.Born)
+	if o.Address != "" {
+		street, _ := p.street(o.Address, "")
+		o.Address = fmt.Sprintf("%s %d", street, 1+p.sum("houseno", o.Address)%99)
+	}
+}
+
+// street returns a fictional street and house number for an address. The zip
+// code and city are kept, so recordings still work with geo search.
+func (p *pseudonymizer) street(name, houseNo string) (string, string) {
+	if name == "" {
+		return "", houseNo
+	}
+	address := name + " " + houseNo
+	street := p.pick("street", address, pseudoStreets)
+	if houseNo != "" {
+		houseNo = fmt.Sprint(1 + p.sum("houseno", address)%99)
+	}
+	return street, houseNo
+}
+
+// phoneNumber returns a fictional number with the same length and first
+// digit, so mobile numbers still look like mobile numbers
+func (p *pseudonymizer) phoneNumber(number string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, number)
+	if len(digits) < 2 {
+		return number
+	}
+
+	n := p.sum("phone", digits)
+	out := []byte(digits)
+	for i := 1; i < len(out); i++ {
+		out[i] = byte('0' + n%10)
+		n /= 10
+	}
+	return string(out)
+}
+
+// yearOnly keeps only the year of a date, so ages stay roughly right
+func yearOnly(date string) string {
+	if len(date) < 4 {
+		return date
+	}
+	return date[:4] + "-01-01"
+}
+
+// pick chooses a value from choices for the original value
+func (p *pseudonymizer) pick(field, original string, choices []string) string {
+	return choices[p.sum(field, original)%uint64(len(choices))]
+}
+
+// sum returns a keyed hash of an original value
+func (p *pseudonymizer) sum(field, original string) uint64 {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(field + "\x00" + strings.ToLower(original)))
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+// coarse rounds a coordinate to about a kilometer
+func coarse(coordinate float64) float64 {
+	if coordinate == 0 {
+		return 0
+	}
+	return float64(int(coordinate*100)) / 100
+}
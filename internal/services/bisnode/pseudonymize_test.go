@@ -0,0 +1,129 @@
+package bisnode
+
+import (
+	"bisnode/internal/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// sanitize runs the pseudonymizer over a response to a request for path
+func sanitize(t *testing.T, p *pseudonymizer, path string, status int, body interface{}) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := &http.Response{StatusCode: status, Request: httptest.NewRequest(http.MethodPost, path, nil)}
+	out, err := p.Sanitize(resp, data)
+	if err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	return out
+}
+
+func TestPseudonymizeDirectory(t *testing.T) {
+	person := models.DirectoryResult{
+		Type:       "person",
+		Born:       "1980-05-17",
+		Age:        models.DirectoryAge{Year: 45, Month: 3, Day: 2},
+		FirstName:  "Ola",
+		LastName:   "Nordmann",
+		StreetName: "Storgata",
+		HouseNo:    "12",
+		ZipCode:    "0155",
+		City:       "OSLO",
+		Longitude:  10.752245,
+		Latitude:   59.913868,
+		Mobile:     "91234567",
+		Addresses:  []models.DirectoryAddress{{StreetName: "Storgata", HouseNo: "12", ZipCode: "0155"}},
+		Phones:     []models.DirectoryPhone{{Number: "+47 912 34 567"}},
+	}
+	company := models.DirectoryResult{Type: "company", OrganizationNumber: "923609016", LastName: "Eksempel AS", StreetName: "Storgata"}
+	body := models.DirectorySearchResponse{Result: []models.DirectoryResult{person, company}}
+
+	p := newPseudonymizer("key")
+	out := sanitize(t, p, "/search/norway/directory", http.StatusOK, body)
+
+	var got models.DirectorySearchResponse
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	pseudo := got.Result[0]
+	for _, original := range []string{"Ola", "Nordmann", "Storgata", "91234567", "912 34 567", "1980-05-17", "10.752245"} {
+		if data := mustMarshal(t, pseudo); strings.Contains(data, original) {
+			t.Errorf("pseudonymized person still contains %q: %s", original, data)
+		}
+	}
+	if pseudo.Born != "1980-01-01" || pseudo.Age.Year != 45 || pseudo.Age.Month != 0 || pseudo.Age.Day != 0 {
+		t.Errorf("born = %s, age = %+v, want only the year", pseudo.Born, pseudo.Age)
+	}
+	if len(pseudo.Mobile) != 8 || pseudo.Mobile[0] != '9' {
+		t.Errorf("mobile = %s, want eight digits starting with 9", pseudo.Mobile)
+	}
+	if pseudo.ZipCode != "0155" || pseudo.City != "OSLO" || pseudo.Latitude != 59.91 {
+		t.Errorf("zip code, city and latitude = %s %s %v, want 0155 OSLO 59.91", pseudo.ZipCode, pseudo.City, pseudo.Latitude)
+	}
+	if mustMarshal(t, got.Result[1]) != mustMarshal(t, company) {
+		t.Errorf("company = %+v, want it unchanged", got.Result[1])
+	}
+
+	// The same key gives the same pseudonym; another key gives another
+	again := sanitize(t, newPseudonymizer("key"), "/search/norway/directory", http.StatusOK, body)
+	if string(again) != string(out) {
+		t.Errorf("pseudonyms differ between runs with the same key:\n%s\n%s", out, again)
+	}
+	var other models.DirectorySearchResponse
+	json.Unmarshal(sanitize(t, newPseudonymizer("other key"), "/search/norway/directory", http.StatusOK, body), &other)
+	if other.Result[0].Mobile == pseudo.Mobile {
+		t.Errorf("mobile = %s with both keys, want different pseudonyms", pseudo.Mobile)
+	}
+}
+
+func TestPseudonymizeVehicleOwners(t *testing.T) {
+	var vehicle models.MotorVehicle
+	vehicle.RegNo = "AB12345"
+	vehicle.Owner = models.Owner{Name: "Ola Nordmann", Born: "1980-05-17", Address: "Storgata 12", Zipcode: "0155", City: "OSLO"}
+	vehicle.LeasingUser = models.Owner{OrganizationNumber: "923609016", Name: "Eksempel AS", Address: "Storgata 1"}
+	body := models.MotorVehicleSearchResponse{Result: []models.MotorVehicle{vehicle}}
+
+	out := sanitize(t, newPseudonymizer("key"), "/search/norway/motorvehicle/v2/AB12345", http.StatusOK, body)
+
+	var got models.MotorVehicleSearchResponse
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	owner := got.Result[0].Owner
+	if owner.Name == "Ola Nordmann" || strings.Contains(owner.Address, "Storgata") || owner.Born != "1980-01-01" {
+		t.Errorf("owner = %+v, want a pseudonymized person", owner)
+	}
+	if owner.Zipcode != "0155" || owner.City != "OSLO" || got.Result[0].RegNo != "AB12345" {
+		t.Errorf("owner = %+v, regNo = %s, want the zip code, city and vehicle kept", owner, got.Result[0].RegNo)
+	}
+	if got.Result[0].LeasingUser != vehicle.LeasingUser {
+		t.Errorf("leasing company = %+v, want it unchanged", got.Result[0].LeasingUser)
+	}
+}
+
+func TestPseudonymizeErrors(t *testing.T) {
+	body := map[string]string{"message": "no match for Ola Nordmann"}
+
+	out := sanitize(t, newPseudonymizer("key"), "/search/norway/directory", http.StatusNotFound, body)
+	if string(out) != "Not Found" {
+		t.Errorf("sanitized error = %q, want the status text only", out)
+	}
+}
+
+// mustMarshal returns v as JSON
+func mustMarshal(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,240 @@
+package bisnode
+
+import (
+	"bisnode/internal/config"
+	"bisnode/internal/domain"
+	"bisnode/internal/models"
+	"bisnode/internal/validation"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrScreeningNotFound is returned for unknown or expired screenings
+	ErrScreeningNotFound = errors.New("screening not found")
+	// ErrScreeningNotFinished is returned when the report of a running screening is requested
+	ErrScreeningNotFinished = errors.New("screening has not finished")
+	// ErrScreeningsFull is returned when a screening cannot start until others
+	// finish or expire
+	ErrScreeningsFull = errors.New("too many screenings")
+)
+
+// ScreeningStatus is the progress of a screening
+type ScreeningStatus string
+
+const (
+	ScreeningRunning   ScreeningStatus = "running"
+	ScreeningCompleted ScreeningStatus = "completed"
+)
+
+// Screening describes a phone list being checked against the marketing reservations
+type Screening struct {
+	ID          string          `json:"id"`
+	Channel     domain.Channel  `json:"channel"`
+	Status      ScreeningStatus `json:"status"`
+	Total       int             `json:"total"`
+	Processed   int             `json:"processed"`
+	Contactable int             `json:"contactable"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty"`
+}
+
+// screening is a Screening with the entries of its report
+type screening struct {
+	Screening
+	entries []domain.WashEntry
+}
+
+// ScreeningService screens phone lists in the background, since lists of
+// thousands of numbers take longer than an HTTP request may run. Screenings
+// are kept in memory and are lost on restart, so the number running and the
+// number kept are capped.
+type ScreeningService struct {
+	persons     PersonSearcher
+	concurrency int
+	maxNumbers  int
+	maxRunning  int
+	maxStored   int
+	retention   time.Duration
+
+	mu         sync.Mutex
+	screenings map[string]*screening
+	now        func() time.Time
+}
+
+// NewScreeningService creates a new ScreeningService
+func NewScreeningService(persons PersonSearcher, cfg *config.ScreeningConfig) *ScreeningService {
+	return &ScreeningService{
+		persons:     persons,
+		concurrency: max(cfg.Concurrency, 1),
+		maxNumbers:  cfg.MaxNumbers,
+		maxRunning:  cfg.MaxRunning,
+		maxStored:   cfg.MaxStored,
+		retention:   time.Duration(cfg.RetentionMinutes) * time.Minute,
+		screenings:  make(map[string]*screening),
+		now:         time.Now,
+	}
+}
+
+// Start begins screening numbers for contact through channel and returns
+// immediately. The screening runs detached from ctx, which is only used for
+// logging the caller's cancellation. ErrScreeningsFull is returned when too
+// many screenings are running or kept.
+func (s *ScreeningService) Start(ctx context.Context, numbers []string, channel domain.Channel) (Screening, error) {
+	if len(numbers) == 0 {
+		return Screening{}, validation.Errorf("phoneNumbers", "is required")
+	}
+	if len(numbers) > s.maxNumbers {
+		return Screening{}, validation.Errorf("phoneNumbers", "must contain at most %d numbers", s.maxNumbers)
+	}
+
+	id, err := newScreeningID()
+	if err != nil {
+		return Screening{}, err
+	}
+
+	sc := &screening{
+		Screening: Screening{
+			ID:        id,
+			Channel:   channel,
+			Status:    ScreeningRunning,
+			Total:     len(numbers),
+			CreatedAt: s.now(),
+		},
+		entries: make([]domain.WashEntry, len(numbers)),
+	}
+
+	s.mu.Lock()
+	s.purge()
+	if err := s.checkCapacity(); err != nil {
+		s.mu.Unlock()
+		return Screening{}, err
+	}
+	s.screenings[id] = sc
+	snapshot := sc.Screening
+	s.mu.Unlock()
+
+	go s.run(context.WithoutCancel(ctx), sc, numbers)
+
+	return snapshot, nil
+}
+
+// Get returns the progress of a screening
+func (s *ScreeningService) Get(id string) (Screening, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge()
+	sc, ok := s.screenings[id]
+	if !ok {
+		return Screening{}, ErrScreeningNotFound
+	}
+	return sc.Screening, nil
+}
+
+// Report returns the verdict for each number of a completed screening, in
+// the order the numbers were submitted
+func (s *ScreeningService) Report(id string) (Screening, []domain.WashEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge()
+	sc, ok := s.screenings[id]
+	if !ok {
+		return Screening{}, nil, ErrScreeningNotFound
+	}
+	if sc.Status != ScreeningCompleted {
+		return sc.Screening, nil, ErrScreeningNotFinished
+	}
+	return sc.Screening, sc.entries, nil
+}
+
+// run looks up every number with bounded concurrency and records the verdicts
+func (s *ScreeningService) run(ctx context.Context, sc *screening, numbers []string) {
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+
+	for i, number := range numbers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, number string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := s.persons.SearchByMobileNumber(ctx, number, models.SearchOptions{})
+			entry := MobileLookup{Number: number, Result: result, Err: err}.WashEntry(sc.Channel)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			sc.entries[i] = entry
+			sc.Processed++
+			if entry.Contactable {
+				sc.Contactable++
+			}
+		}(i, number)
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	completedAt := s.now()
+	sc.Status = ScreeningCompleted
+	sc.CompletedAt = &completedAt
+	s.mu.Unlock()
+
+	log.Printf("Screening %s completed: %d of %d numbers contactable", sc.ID, sc.Contactable, sc.Total)
+}
+
+// checkCapacity returns ErrScreeningsFull when another screening may not
+// start. The caller must hold s.mu.
+func (s *ScreeningService) checkCapacity() error {
+	if len(s.screenings) >= s.maxStored {
+		return fmt.Errorf("%w: %d screenings are kept until their reports expire", ErrScreeningsFull, s.maxStored)
+	}
+	running := 0
+	for _, sc := range s.screenings {
+		if sc.Status == ScreeningRunning {
+			running++
+		}
+	}
+	if running >= s.maxRunning {
+		return fmt.Errorf("%w: %d screenings are running", ErrScreeningsFull, running)
+	}
+	return nil
+}
+
+// purge removes completed screenings past their retention. The caller must hold s.mu.
+func (s *ScreeningService) purge() {
+	now := s.now()
+	for id, sc := range s.screenings {
+		if sc.CompletedAt != nil && now.Sub(*sc.CompletedAt) > s.retention {
+			delete(s.screenings, id)
+		}
+	}
+}
+
+// WashEntry decides whether the looked up number may be contacted through channel
+func (l MobileLookup) WashEntry(channel domain.Channel) domain.WashEntry {
+	if l.Err != nil {
+		status := domain.WashError
+		if _, ok := validation.As(l.Err); ok {
+			status = domain.WashInvalid
+		}
+		return domain.NewWashError(l.Number, status, l.Err)
+	}
+	return domain.NewWashEntry(l.Number, domain.NewListings(l.Result.Result), channel)
+}
+
+// newScreeningID returns a random identifier for a screening
+func newScreeningID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
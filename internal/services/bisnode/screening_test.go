@@ -0,0 +1,112 @@
+package bisnode
+
+import (
+	"bisnode/internal/config"
+	"bisnode/internal/domain"
+	"bisnode/internal/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingPersonSearcher answers mobile searches once release is closed
+type blockingPersonSearcher struct {
+	stubPersonSearcher
+	release chan struct{}
+}
+
+func (s blockingPersonSearcher) SearchByMobileNumber(ctx context.Context, mobileNumber string, opts models.SearchOptions) (*DirectoryResponse, error) {
+	<-s.release
+	return &DirectoryResponse{}, nil
+}
+
+// waitForScreening polls a screening until it has completed
+func waitForScreening(t *testing.T, s *ScreeningService, id string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sc, err := s.Get(id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if sc.Status == ScreeningCompleted {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("screening = %+v, gave up waiting", sc)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestScreeningServiceLimitsRunning(t *testing.T) {
+	persons := blockingPersonSearcher{release: make(chan struct{})}
+	s := NewScreeningService(persons, &config.ScreeningConfig{
+		Concurrency: 1, MaxNumbers: 10, RetentionMinutes: 60, MaxRunning: 2, MaxStored: 10,
+	})
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		sc, err := s.Start(ctx, []string{"91234567"}, domain.ChannelTelemarketing)
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		ids = append(ids, sc.ID)
+	}
+	if _, err := s.Start(ctx, []string{"91234567"}, domain.ChannelTelemarketing); !errors.Is(err, ErrScreeningsFull) {
+		t.Fatalf("Start() with 2 running error = %v, want ErrScreeningsFull", err)
+	}
+
+	close(persons.release)
+	for _, id := range ids {
+		waitForScreening(t, s, id)
+	}
+	if _, err := s.Start(ctx, []string{"91234567"}, domain.ChannelTelemarketing); err != nil {
+		t.Errorf("Start() after the others completed error = %v", err)
+	}
+}
+
+func TestScreeningServiceLimitsStored(t *testing.T) {
+	s := NewScreeningService(stubPersonSearcher{resp: &DirectoryResponse{}}, &config.ScreeningConfig{
+		Concurrency: 1, MaxNumbers: 10, RetentionMinutes: 60, MaxRunning: 10, MaxStored: 2,
+	})
+	now := time.Now()
+	s.mu.Lock()
+	s.now = func() time.Time { return now }
+	s.mu.Unlock()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		sc, err := s.Start(ctx, []string{"91234567"}, domain.ChannelTelemarketing)
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		waitForScreening(t, s, sc.ID)
+	}
+	if _, err := s.Start(ctx, []string{"91234567"}, domain.ChannelTelemarketing); !errors.Is(err, ErrScreeningsFull) {
+		t.Fatalf("Start() with 2 kept error = %v, want ErrScreeningsFull", err)
+	}
+
+	// Once the reports expire there is room again
+	s.mu.Lock()
+	s.now = func() time.Time { return now.Add(61 * time.Minute) }
+	s.mu.Unlock()
+	if _, err := s.Start(ctx, []string{"91234567"}, domain.ChannelTelemarketing); err != nil {
+		t.Errorf("Start() after the reports expired error = %v", err)
+	}
+}
+
+func TestScreeningServiceZeroConcurrency(t *testing.T) {
+	s := NewScreeningService(stubPersonSearcher{resp: &DirectoryResponse{}}, &config.ScreeningConfig{
+		MaxNumbers: 10, RetentionMinutes: 60, MaxRunning: 1, MaxStored: 10,
+	})
+
+	sc, err := s.Start(context.Background(), []string{"91234567"}, domain.ChannelTelemarketing)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitForScreening(t, s, sc.ID)
+}
@@ -0,0 +1,82 @@
+package bisnode
+
+import (
+	"bisnode/internal/config"
+	"bisnode/internal/models"
+	"bisnode/internal/validation"
+	"fmt"
+)
+
+// searchDefaults are the resolved search defaults and limits from the configuration
+type searchDefaults struct {
+	searchMode     models.SearchMode
+	onlyFoundWords bool
+	resultLimit    int
+	maxResultLimit int
+}
+
+// newSearchDefaults resolves the search configuration. A nil cfg, or unset
+// fields, fall back to the settings the service has always used.
+func newSearchDefaults(cfg *config.SearchConfig) searchDefaults {
+	d := searchDefaults{
+		searchMode:     models.SearchModeSmart,
+		onlyFoundWords: true,
+		resultLimit:    10,
+		maxResultLimit: 100,
+	}
+	if cfg == nil {
+		return d
+	}
+
+	if mode, err := models.ParseSearchMode(cfg.DefaultSearchMode); err == nil {
+		d.searchMode = mode
+	}
+	if cfg.DefaultOnlyFoundWords != nil {
+		d.onlyFoundWords = *cfg.DefaultOnlyFoundWords
+	}
+	if cfg.MaxResultLimit > 0 {
+		d.maxResultLimit = cfg.MaxResultLimit
+	}
+	if cfg.DefaultResultLimit > 0 {
+		d.resultLimit = min(cfg.DefaultResultLimit, d.maxResultLimit)
+	}
+
+	return d
+}
+
+// resolve fills in the options the caller did not set, using listing as the
+// default listing type, and validates them
+func (d searchDefaults) resolve(opts models.SearchOptions, listing models.ListingType) (models.SearchOptions, error) {
+	if opts.SearchMode == 0 {
+		opts.SearchMode = d.searchMode
+	}
+	if opts.ListingType == nil {
+		opts.ListingType = &listing
+	}
+	if opts.OnlyFoundWords == nil {
+		onlyFoundWords := d.onlyFoundWords
+		opts.OnlyFoundWords = &onlyFoundWords
+	}
+	if opts.ResultLimit == 0 {
+		opts.ResultLimit = d.resultLimit
+	}
+
+	var fieldErrs []validation.FieldError
+	if _, err := models.ParseSearchMode(opts.SearchMode.String()); err != nil {
+		fieldErrs = append(fieldErrs, validation.FieldError{Field: "searchMode", Message: "must be one of exact, phonetic, smart"})
+	}
+	if _, err := models.ParseListingType(opts.ListingType.String()); err != nil {
+		fieldErrs = append(fieldErrs, validation.FieldError{Field: "listingType", Message: "must be one of all, company, person"})
+	}
+	if opts.ResultLimit < 1 || opts.ResultLimit > d.maxResultLimit {
+		fieldErrs = append(fieldErrs, validation.FieldError{
+			Field:   "limit",
+			Message: fmt.Sprintf("must be between 1 and %d", d.maxResultLimit),
+		})
+	}
+
+	if len(fieldErrs) > 0 {
+		return opts, &validation.Error{Fields: fieldErrs}
+	}
+	return opts, nil
+}
@@ -0,0 +1,39 @@
+package bisnode
+
+import (
+	"bisnode/internal/cassette"
+	"bisnode/internal/config"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// baseURL returns the configured Bisnode API without a trailing slash, or the
+// default API when none is configured
+func baseURL(cfg *config.BisnodeConfig) string {
+	if cfg.BaseURL == "" {
+		return config.DefaultBisnodeBaseURL
+	}
+	return strings.TrimSuffix(cfg.BaseURL, "/")
+}
+
+// newHTTPClient creates the HTTP client for calling Bisnode, recording or
+// replaying its traffic when cfg.Cassette asks for it
+func newHTTPClient(cfg *config.BisnodeConfig) *http.Client {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	switch cfg.Cassette.Mode {
+	case cassette.ModeRecord:
+		log.Printf("Recording Bisnode responses to %s", cfg.Cassette.Dir)
+		sanitizer := newPseudonymizer(cfg.Cassette.PseudonymKey)
+		client.Transport = cassette.NewRecorder(cfg.Cassette.Dir, sanitizer.key, http.DefaultTransport, sanitizer.Sanitize)
+	case cassette.ModeReplay:
+		log.Printf("Replaying Bisnode responses from %s", cfg.Cassette.Dir)
+		client.Transport = cassette.NewReplayer(cfg.Cassette.Dir, []byte(cfg.Cassette.PseudonymKey))
+	}
+
+	return client
+}
@@ -0,0 +1,42 @@
+// Package validation describes invalid input independently of where it came
+// from, so services can reject it without depending on HTTP request binding.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a validation failure for a single field
+type FieldError struct {
+	// Field is the name of the field as seen by API callers
+	Field string `json:"field" example:"organizationNumber"`
+	// Message explains why the value was rejected
+	Message string `json:"message" example:"organization number must have 9 digits"`
+}
+
+// Error is returned when input is well-formed but contains invalid values
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s %s", f.Field, f.Message))
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Errorf builds an Error for a single field
+func Errorf(field, format string, args ...interface{}) error {
+	return &Error{Fields: []FieldError{{Field: field, Message: fmt.Sprintf(format, args...)}}}
+}
+
+// As returns the Error wrapped in err, if any
+func As(err error) (*Error, bool) {
+	var ve *Error
+	ok := errors.As(err, &ve)
+	return ve, ok
+}
@@ -0,0 +1,72 @@
+package vehicleid
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// plateLetters are the letters used in the two-letter prefix of standard
+// plates. I, M, O and Q are left out to avoid confusion with digits and
+// other letters, as are Æ, Ø and Å.
+const plateLetters = "ABCDEFGHJKLNPRSTUVWXYZ"
+
+// personalizedLetters are the characters allowed on personalized plates
+const personalizedLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZÆØÅ0123456789"
+
+// ParsePlate classifies s as a Norwegian license plate
+func ParsePlate(s string) (Identifier, error) {
+	value := normalize(s)
+	if value == "" {
+		return Identifier{}, ErrEmpty
+	}
+
+	kind, ok := plateKind(value)
+	if !ok {
+		return Identifier{}, ErrUnrecognized
+	}
+
+	return Identifier{Kind: kind, Value: value}, nil
+}
+
+// plateKind returns the plate format a normalized value matches
+func plateKind(value string) (Kind, bool) {
+	if strings.HasPrefix(value, "CD") && (len(value) == 6 || len(value) == 7) && allDigits(value[2:]) {
+		return KindDiplomaticPlate, true
+	}
+
+	if len(value) == 6 || len(value) == 7 {
+		if strings.ContainsRune(plateLetters, rune(value[0])) &&
+			strings.ContainsRune(plateLetters, rune(value[1])) &&
+			allDigits(value[2:]) {
+			if len(value) == 7 {
+				return KindStandardPlate, true
+			}
+			return KindTrailerPlate, true
+		}
+	}
+
+	n := utf8.RuneCountInString(value)
+	if n < 2 || n > 7 {
+		return "", false
+	}
+	for _, r := range value {
+		if !strings.ContainsRune(personalizedLetters, r) {
+			return "", false
+		}
+	}
+
+	return KindPersonalizedPlate, true
+}
+
+// allDigits reports whether s is non-empty and only contains the digits 0-9
+func allDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+// Package vehicleid classifies and validates vehicle identifiers: Norwegian
+// license plates and 17-character Vehicle Identification Numbers (VIN).
+package vehicleid
+
+import (
+	"errors"
+	"strings"
+)
+
+// Kind is the type of vehicle identifier
+type Kind string
+
+const (
+	// KindStandardPlate is two letters followed by five digits, such as "AB12345"
+	KindStandardPlate Kind = "standard_plate"
+	// KindTrailerPlate is two letters followed by four digits, used for
+	// trailers as well as motorcycles and tractors, such as "AB1234"
+	KindTrailerPlate Kind = "trailer_plate"
+	// KindDiplomaticPlate is "CD" followed by four or five digits
+	KindDiplomaticPlate Kind = "diplomatic_plate"
+	// KindPersonalizedPlate is a custom plate of 2 to 7 letters and digits
+	KindPersonalizedPlate Kind = "personalized_plate"
+	// KindVIN is a 17-character Vehicle Identification Number
+	KindVIN Kind = "vin"
+)
+
+var (
+	// ErrEmpty is returned when no identifier was given
+	ErrEmpty = errors.New("vehicle identifier cannot be empty")
+	// ErrUnrecognized is returned when the input is neither a Norwegian plate nor a VIN
+	ErrUnrecognized = errors.New("not a valid Norwegian license plate or VIN")
+)
+
+// Identifier is a classified vehicle identifier
+type Identifier struct {
+	// Kind is the type of identifier
+	Kind Kind `json:"kind"`
+	// Value is the normalized identifier as sent to Bisnode
+	Value string `json:"value"`
+	// VIN holds the decoded VIN when Kind is KindVIN
+	VIN *VINInfo `json:"vin,omitempty"`
+}
+
+// IsPlate reports whether the identifier is a license plate
+func (id Identifier) IsPlate() bool {
+	return id.Kind != KindVIN
+}
+
+// Parse classifies s as a Norwegian license plate or a VIN. Spaces and dashes
+// are ignored and letters are upper-cased. VINs are validated as described in
+// ParseVIN.
+func Parse(s string) (Identifier, error) {
+	value := normalize(s)
+	if value == "" {
+		return Identifier{}, ErrEmpty
+	}
+
+	if len(value) == vinLength {
+		info, err := ParseVIN(value)
+		if err != nil {
+			return Identifier{}, err
+		}
+		return Identifier{Kind: KindVIN, Value: value, VIN: &info}, nil
+	}
+
+	if kind, ok := plateKind(value); ok {
+		return Identifier{Kind: kind, Value: value}, nil
+	}
+
+	return Identifier{}, ErrUnrecognized
+}
+
+// normalize upper-cases s and removes spaces and dashes
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(strings.TrimSpace(s)) {
+		if r == ' ' || r == '\u00a0' || r == '-' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package vehicleid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantKind  Kind
+		wantValue string
+		wantErr   error
+	}{
+		{name: "standard plate", input: "AB12345", wantKind: KindStandardPlate, wantValue: "AB12345"},
+		{name: "formatted plate", input: " ab 12-345 ", wantKind: KindStandardPlate, wantValue: "AB12345"},
+		{name: "non-breaking space", input: "AB\u00a012345", wantKind: KindStandardPlate, wantValue: "AB12345"},
+		{name: "trailer plate", input: "AB1234", wantKind: KindTrailerPlate, wantValue: "AB1234"},
+		{name: "diplomatic plate", input: "CD12345", wantKind: KindDiplomaticPlate, wantValue: "CD12345"},
+		{name: "personalized plate", input: "tesla", wantKind: KindPersonalizedPlate, wantValue: "TESLA"},
+		{name: "personalized plate with norwegian letters", input: "blåbær", wantKind: KindPersonalizedPlate, wantValue: "BLÅBÆR"},
+		{name: "excluded prefix letter is personalized", input: "IO12345", wantKind: KindPersonalizedPlate, wantValue: "IO12345"},
+		{name: "vin", input: "1HGCM82633A004352", wantKind: KindVIN, wantValue: "1HGCM82633A004352"},
+		{name: "european vin without check digit", input: "wbakg7c5xbe123456", wantKind: KindVIN, wantValue: "WBAKG7C5XBE123456"},
+		{name: "vin with wrong check digit", input: "1HGCM82643A004352", wantErr: ErrInvalidVINCheckDigit},
+		{name: "vin with excluded letter", input: "1HGCM82633A00435O", wantErr: ErrInvalidVINCharacters},
+		{name: "empty", input: "  ", wantErr: ErrEmpty},
+		{name: "too long for a plate", input: "ABCDEFGH", wantErr: ErrUnrecognized},
+		{name: "single character", input: "A", wantErr: ErrUnrecognized},
+		{name: "punctuation", input: "AB!2345", wantErr: ErrUnrecognized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Kind != tt.wantKind || got.Value != tt.wantValue {
+				t.Errorf("Parse(%q) = %s %q, want %s %q", tt.input, got.Kind, got.Value, tt.wantKind, tt.wantValue)
+			}
+			if (got.VIN != nil) != (tt.wantKind == KindVIN) {
+				t.Errorf("Parse(%q) VIN = %+v, want decoded VIN only for kind vin", tt.input, got.VIN)
+			}
+		})
+	}
+}
+
+func TestParseVIN(t *testing.T) {
+	info, err := ParseVIN("1HGCM82633A004352")
+	if err != nil {
+		t.Fatalf("ParseVIN() error = %v", err)
+	}
+	want := VINInfo{
+		WMI:                "1HG",
+		Manufacturer:       "Honda",
+		Region:             "North America",
+		Country:            "United States",
+		ModelYear:          2003,
+		CheckDigitRequired: true,
+		CheckDigitValid:    true,
+		SerialNumber:       "004352",
+	}
+	if info != want {
+		t.Errorf("ParseVIN() = %+v, want %+v", info, want)
+	}
+
+	if _, err := ParseVIN("1HGCM82633A00435"); !errors.Is(err, ErrInvalidVINLength) {
+		t.Errorf("ParseVIN() of 16 characters error = %v, want %v", err, ErrInvalidVINLength)
+	}
+}
+
+func TestParsePlateRejectsVIN(t *testing.T) {
+	if _, err := ParsePlate("1HGCM82633A004352"); !errors.Is(err, ErrUnrecognized) {
+		t.Errorf("ParsePlate() of a VIN error = %v, want %v", err, ErrUnrecognized)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{"AB12345", "ab 12-345", "CD1234", "TESLA", "BLÅBÆR", "1HGCM82633A004352", "WBAKG7C5XBE123456", "", "A"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		id, err := Parse(s)
+		if err != nil {
+			return
+		}
+
+		if id.Value == "" {
+			t.Fatalf("Parse(%q) = %+v, empty value", s, id)
+		}
+		again, err := Parse(id.Value)
+		if err != nil || again.Kind != id.Kind || again.Value != id.Value {
+			t.Errorf("Parse(%q) = %s %q, %v, want %s %q", id.Value, again.Kind, again.Value, err, id.Kind, id.Value)
+		}
+		if id.Kind == KindVIN && id.VIN.CheckDigitRequired && !id.VIN.CheckDigitValid {
+			t.Errorf("Parse(%q) accepted a VIN with an invalid required check digit", s)
+		}
+	})
+}
@@ -0,0 +1,294 @@
+package vehicleid
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// vinLength is the number of characters in a VIN
+const vinLength = 17
+
+var (
+	// ErrInvalidVINLength is returned when a VIN does not have 17 characters
+	ErrInvalidVINLength = errors.New("VIN must have 17 characters")
+	// ErrInvalidVINCharacters is returned when a VIN contains I, O, Q or anything but letters and digits
+	ErrInvalidVINCharacters = errors.New("VIN may only contain digits and the letters A-Z except I, O and Q")
+	// ErrInvalidVINCheckDigit is returned when the check digit of a VIN that requires one does not match
+	ErrInvalidVINCheckDigit = errors.New("VIN has an invalid check digit")
+)
+
+// VINInfo is the information that can be decoded from a VIN without a lookup
+type VINInfo struct {
+	// WMI is the World Manufacturer Identifier, the first three characters
+	WMI string `json:"wmi"`
+	// Manufacturer is the manufacturer registered for the WMI, if known
+	Manufacturer string `json:"manufacturer,omitempty"`
+	// Region is the continent the vehicle was manufactured in
+	Region string `json:"region"`
+	// Country is the country the vehicle was manufactured in, if known
+	Country string `json:"country,omitempty"`
+	// ModelYear is decoded from the tenth character, 0 if it is not a year code
+	ModelYear int `json:"modelYear,omitempty"`
+	// CheckDigitRequired is true for VINs from regions where the ninth character must be a check digit
+	CheckDigitRequired bool `json:"checkDigitRequired"`
+	// CheckDigitValid reports whether the ninth character matches the calculated check digit
+	CheckDigitValid bool `json:"checkDigitValid"`
+	// SerialNumber is the manufacturer's production sequence number, the last six characters
+	SerialNumber string `json:"serialNumber"`
+}
+
+// ParseVIN validates and decodes a VIN. The check digit is only enforced for
+// VINs from North America and China, where it is mandatory; elsewhere its
+// validity is reported in CheckDigitValid.
+func ParseVIN(s string) (VINInfo, error) {
+	vin := normalize(s)
+	if vin == "" {
+		return VINInfo{}, ErrEmpty
+	}
+	if len(vin) != vinLength {
+		return VINInfo{}, ErrInvalidVINLength
+	}
+	for i := 0; i < len(vin); i++ {
+		if _, ok := transliterate(vin[i]); !ok {
+			return VINInfo{}, ErrInvalidVINCharacters
+		}
+	}
+
+	wmi := vin[:3]
+	info := VINInfo{
+		WMI:          wmi,
+		Manufacturer: manufacturers[wmi],
+		Region:       region(vin[0]),
+		Country:      country(vin[:2]),
+		SerialNumber: vin[11:],
+	}
+
+	check, _ := CheckDigit(vin)
+	info.CheckDigitValid = vin[8] == check
+	info.CheckDigitRequired = northAmerican(vin[0]) || vin[0] == 'L'
+	if info.CheckDigitRequired && !info.CheckDigitValid {
+		return VINInfo{}, ErrInvalidVINCheckDigit
+	}
+
+	info.ModelYear = modelYear(vin[9], vin[6], northAmerican(vin[0]), time.Now().Year())
+
+	return info, nil
+}
+
+// vinWeights are the position weights used when calculating the check digit
+var vinWeights = [vinLength]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// CheckDigit calculates the check digit of a 17-character VIN, which is '0'-'9' or 'X'
+func CheckDigit(vin string) (byte, bool) {
+	if len(vin) != vinLength {
+		return 0, false
+	}
+
+	sum := 0
+	for i := 0; i < vinLength; i++ {
+		v, ok := transliterate(vin[i])
+		if !ok {
+			return 0, false
+		}
+		sum += v * vinWeights[i]
+	}
+
+	if r := sum % 11; r != 10 {
+		return byte('0' + r), true
+	}
+	return 'X', true
+}
+
+// transliterate returns the numeric value of a VIN character
+func transliterate(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c >= 'A' && c <= 'H':
+		return int(c-'A') + 1, true
+	case c >= 'J' && c <= 'N':
+		return int(c-'J') + 1, true
+	case c == 'P':
+		return 7, true
+	case c == 'R':
+		return 9, true
+	case c >= 'S' && c <= 'Z':
+		return int(c-'S') + 2, true
+	}
+	// I, O and Q are not allowed
+	return 0, false
+}
+
+// yearCodes are the model year codes in order, repeating every 30 years from 1980
+const yearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// modelYear decodes the tenth character of a VIN. The codes repeat every 30
+// years; North American VINs use a letter in the seventh position from 2010
+// onwards, elsewhere the latest year that is not after next year is chosen.
+func modelYear(code, pos7 byte, northAmerica bool, currentYear int) int {
+	i := strings.IndexByte(yearCodes, code)
+	if i < 0 {
+		return 0
+	}
+	year := 1980 + i
+
+	if northAmerica {
+		if pos7 < '0' || pos7 > '9' {
+			year += 30
+		}
+		return year
+	}
+
+	for year+30 <= currentYear+1 {
+		year += 30
+	}
+	return year
+}
+
+// northAmerican reports whether the first VIN character is a North American region code
+func northAmerican(c byte) bool {
+	return c >= '1' && c <= '5'
+}
+
+// region returns the continent for the first character of a VIN
+func region(c byte) string {
+	switch {
+	case c >= 'A' && c <= 'H':
+		return "Africa"
+	case c >= 'J' && c <= 'R':
+		return "Asia"
+	case c >= 'S' && c <= 'Z':
+		return "Europe"
+	case northAmerican(c):
+		return "North America"
+	case c == '6' || c == '7':
+		return "Oceania"
+	case c == '8' || c == '9':
+		return "South America"
+	}
+	return ""
+}
+
+// vinOrder is the order of characters used for country code ranges
+const vinOrder = "ABCDEFGHJKLMNPRSTUVWXYZ1234567890"
+
+// countryRanges maps ranges of the first two VIN characters to countries.
+// Only the most common manufacturing countries are listed.
+var countryRanges = []struct {
+	from, to string
+	country  string
+}{
+	{"AA", "AH", "South Africa"},
+	{"JA", "J0", "Japan"},
+	{"KL", "KR", "South Korea"},
+	{"LA", "L0", "China"},
+	{"MA", "ME", "India"},
+	{"NL", "NR", "Turkey"},
+	{"SA", "SM", "United Kingdom"},
+	{"SN", "ST", "Germany"},
+	{"SU", "SZ", "Poland"},
+	{"TA", "TH", "Switzerland"},
+	{"TJ", "TP", "Czech Republic"},
+	{"TR", "TV", "Hungary"},
+	{"TW", "T1", "Portugal"},
+	{"UU", "U7", "Romania"},
+	{"VA", "VE", "Austria"},
+	{"VF", "VR", "France"},
+	{"VS", "VW", "Spain"},
+	{"WA", "W0", "Germany"},
+	{"XL", "XR", "Netherlands"},
+	{"XS", "XW", "Russia"},
+	{"YA", "YE", "Belgium"},
+	{"YF", "YK", "Finland"},
+	{"YS", "YW", "Sweden"},
+	{"YX", "Y2", "Norway"},
+	{"ZA", "ZR", "Italy"},
+	{"1A", "10", "United States"},
+	{"2A", "20", "Canada"},
+	{"3A", "3W", "Mexico"},
+	{"4A", "40", "United States"},
+	{"5A", "50", "United States"},
+	{"6A", "6W", "Australia"},
+	{"7A", "7E", "New Zealand"},
+	{"8A", "8E", "Argentina"},
+	{"9A", "9E", "Brazil"},
+}
+
+// country returns the manufacturing country for the first two characters of a VIN
+func country(prefix string) string {
+	if len(prefix) != 2 {
+		return ""
+	}
+	pos := strings.IndexByte(vinOrder, prefix[1])
+	for _, r := range countryRanges {
+		if r.from[0] != prefix[0] {
+			continue
+		}
+		if pos >= strings.IndexByte(vinOrder, r.from[1]) && pos <= strings.IndexByte(vinOrder, r.to[1]) {
+			return r.country
+		}
+	}
+	return ""
+}
+
+// manufacturers maps common World Manufacturer Identifiers to manufacturer names
+var manufacturers = map[string]string{
+	"1FA": "Ford",
+	"1FT": "Ford",
+	"1G1": "Chevrolet",
+	"1HG": "Honda",
+	"1N4": "Nissan",
+	"2T1": "Toyota",
+	"3VW": "Volkswagen",
+	"4T1": "Toyota",
+	"5YJ": "Tesla",
+	"JHM": "Honda",
+	"JMZ": "Mazda",
+	"JN1": "Nissan",
+	"JTD": "Toyota",
+	"JTE": "Toyota",
+	"JTM": "Toyota",
+	"KMH": "Hyundai",
+	"KNA": "Kia",
+	"KNE": "Kia",
+	"LPS": "Polestar",
+	"LRW": "Tesla",
+	"LVS": "Ford",
+	"NMT": "Toyota",
+	"SAJ": "Jaguar",
+	"SAL": "Land Rover",
+	"SJN": "Nissan",
+	"TMB": "Skoda",
+	"TMA": "Hyundai",
+	"U5Y": "Kia",
+	"UU1": "Dacia",
+	"VF1": "Renault",
+	"VF3": "Peugeot",
+	"VF7": "Citroën",
+	"VNK": "Toyota",
+	"VSS": "SEAT",
+	"VWV": "Volkswagen",
+	"W0L": "Opel",
+	"W1K": "Mercedes-Benz",
+	"W1N": "Mercedes-Benz",
+	"WAU": "Audi",
+	"WBA": "BMW",
+	"WBY": "BMW",
+	"WDB": "Mercedes-Benz",
+	"WDD": "Mercedes-Benz",
+	"WF0": "Ford",
+	"WMW": "MINI",
+	"WP0": "Porsche",
+	"WP1": "Porsche",
+	"WV1": "Volkswagen Commercial Vehicles",
+	"WV2": "Volkswagen Commercial Vehicles",
+	"WVG": "Volkswagen",
+	"WVW": "Volkswagen",
+	"XP7": "Tesla",
+	"YS3": "Saab",
+	"YV1": "Volvo",
+	"YV4": "Volvo",
+	"ZFA": "Fiat",
+	"ZAR": "Alfa Romeo",
+}
@@ -0,0 +1,503 @@
+// Package client is a Go client for the Bisnode API served by this module.
+// It covers person, organization, nearby and vehicle searches, washing and
+// screening phone lists, batch lookups, CSV enrichment and background jobs.
+// The health, metrics and documentation endpoints are left out. Failed
+// requests are returned as *APIError, which can be matched with errors.Is
+// against ErrInvalidRequest, ErrNotFound and the other Err* errors.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options holds the optional settings of a Client
+type Options struct {
+	// Username and Password are sent with basic auth when Username is set
+	Username string
+	Password string
+	// HTTPClient sends the requests; a client with a 60 second timeout is used when nil
+	HTTPClient *http.Client
+	// MaxRetries is how many times a lookup is retried after a network error,
+	// 429 Too Many Requests or 502, 503 or 504. Defaults to 3; a negative
+	// value disables retries.
+	MaxRetries int
+	// RetryWait is the wait before the first retry, doubled for each further
+	// retry. Defaults to 500 milliseconds. A longer Retry-After from the
+	// server is honored.
+	RetryWait time.Duration
+}
+
+// Client calls the Bisnode API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+}
+
+// New creates a Client for the API at baseURL, such as "http://localhost:8080"
+func New(baseURL string, opts Options) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		username:   opts.Username,
+		password:   opts.Password,
+		httpClient: opts.HTTPClient,
+		maxRetries: opts.MaxRetries,
+		retryWait:  opts.RetryWait,
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: 60 * time.Second}
+	}
+	switch {
+	case c.maxRetries == 0:
+		c.maxRetries = 3
+	case c.maxRetries < 0:
+		c.maxRetries = 0
+	}
+	if c.retryWait <= 0 {
+		c.retryWait = 500 * time.Millisecond
+	}
+	return c
+}
+
+// SearchPersonByMobileNumber searches for a person by Norwegian mobile number. opts may be nil.
+func (c *Client) SearchPersonByMobileNumber(ctx context.Context, mobileNumber string, opts *SearchOptions) (*SearchResult, error) {
+	body := struct {
+		MobileNumber string `json:"mobileNumber"`
+		SearchOptions
+	}{MobileNumber: mobileNumber}
+	if opts != nil {
+		body.SearchOptions = *opts
+	}
+
+	var result SearchResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/directory/persons/search", body, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchPersons searches for persons by name and address, best match first
+func (c *Client) SearchPersons(ctx context.Context, query PersonQuery) (*SearchResult, error) {
+	var result SearchResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/directory/persons/search", query, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PersonHistory returns the address and phone history of the persons with a mobile number
+func (c *Client) PersonHistory(ctx context.Context, mobileNumber string) (*HistoryResult, error) {
+	var result HistoryResult
+	path := "/api/v1/directory/persons/" + url.PathEscape(mobileNumber) + "/history"
+	if err := c.do(ctx, http.MethodGet, path, nil, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchOrganization looks up a company by its nine-digit organization number
+func (c *Client) SearchOrganization(ctx context.Context, organizationNumber string) (*SearchResult, error) {
+	body := struct {
+		OrganizationNumber string `json:"organizationNumber"`
+	}{organizationNumber}
+
+	var result SearchResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/directory/organizations/search", body, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchOrganizationsByName searches for companies by name
+func (c *Client) SearchOrganizationsByName(ctx context.Context, query OrganizationQuery) (*OrganizationSearchResult, error) {
+	var result OrganizationSearchResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/directory/organizations/search-by-name", query, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchNearby searches for persons and companies around a point or inside a
+// bounding box, nearest first
+func (c *Client) SearchNearby(ctx context.Context, query NearbyQuery) (*NearbyResult, error) {
+	body := struct {
+		Latitude     *float64 `json:"latitude,omitempty"`
+		Longitude    *float64 `json:"longitude,omitempty"`
+		Radius       float64  `json:"radius,omitempty"`
+		MinLatitude  *float64 `json:"minLatitude,omitempty"`
+		MinLongitude *float64 `json:"minLongitude,omitempty"`
+		MaxLatitude  *float64 `json:"maxLatitude,omitempty"`
+		MaxLongitude *float64 `json:"maxLongitude,omitempty"`
+		SearchOptions
+	}{Radius: query.Radius, SearchOptions: query.SearchOptions}
+	if query.Center != nil {
+		body.Latitude, body.Longitude = &query.Center.Latitude, &query.Center.Longitude
+	}
+	if query.Box != nil {
+		body.MinLatitude, body.MinLongitude = &query.Box.Min.Latitude, &query.Box.Min.Longitude
+		body.MaxLatitude, body.MaxLongitude = &query.Box.Max.Latitude, &query.Box.Max.Longitude
+	}
+
+	var result NearbyResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/directory/nearby", body, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Wash checks up to 100 mobile numbers against the marketing reservations
+// for channel. Use StartScreening for longer lists.
+func (c *Client) Wash(ctx context.Context, channel string, phoneNumbers []string) (*WashResult, error) {
+	body := struct {
+		Channel      string   `json:"channel"`
+		PhoneNumbers []string `json:"phoneNumbers"`
+	}{channel, phoneNumbers}
+
+	var result WashResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/directory/wash", body, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StartScreening starts checking a list of mobile numbers against the
+// marketing reservations for channel in the background. Starting is not
+// retried, since a retry could start the screening twice. It fails with
+// ErrUnavailable while the server is screening as many lists as it allows.
+func (c *Client) StartScreening(ctx context.Context, channel string, phoneNumbers []string) (*Screening, error) {
+	body := struct {
+		Channel      string   `json:"channel"`
+		PhoneNumbers []string `json:"phoneNumbers"`
+	}{channel, phoneNumbers}
+
+	var screening Screening
+	if err := c.do(ctx, http.MethodPost, "/api/v1/directory/screenings", body, &screening, false); err != nil {
+		return nil, err
+	}
+	return &screening, nil
+}
+
+// Screening returns the progress of a screening
+func (c *Client) Screening(ctx context.Context, id string) (*Screening, error) {
+	var screening Screening
+	if err := c.do(ctx, http.MethodGet, "/api/v1/directory/screenings/"+url.PathEscape(id), nil, &screening, true); err != nil {
+		return nil, err
+	}
+	return &screening, nil
+}
+
+// ScreeningReport returns the verdict for every number of a completed
+// screening. It fails with ErrConflict while the screening is running.
+func (c *Client) ScreeningReport(ctx context.Context, id string) (*ScreeningReport, error) {
+	var report ScreeningReport
+	path := "/api/v1/directory/screenings/" + url.PathEscape(id) + "/report?format=json"
+	if err := c.do(ctx, http.MethodGet, path, nil, &report, true); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// WaitForScreening polls a screening every interval until it has completed, and returns it
+func (c *Client) WaitForScreening(ctx context.Context, id string, interval time.Duration) (*Screening, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		screening, err := c.Screening(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if screening.Finished() {
+			return screening, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SearchVehicleByLicenseNumber looks up a vehicle by license plate. VINs are accepted too.
+func (c *Client) SearchVehicleByLicenseNumber(ctx context.Context, licenseNumber string) (*VehicleSearchResult, error) {
+	body := struct {
+		LicenseNumber string `json:"licenseNumber"`
+	}{licenseNumber}
+
+	var result VehicleSearchResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/motor-vehicles/search", body, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchVehicleByVIN looks up a vehicle by VIN
+func (c *Client) SearchVehicleByVIN(ctx context.Context, vin string) (*VehicleSearchResult, error) {
+	body := struct {
+		VIN string `json:"vin"`
+	}{vin}
+
+	var result VehicleSearchResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/motor-vehicles/search", body, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Batch looks up a mixed list of items in one request. Each item has its own
+// status; an invalid item does not fail the batch.
+func (c *Client) Batch(ctx context.Context, items []BatchItem) (*BatchResult, error) {
+	body := struct {
+		Items []BatchItem `json:"items"`
+	}{items}
+
+	var result BatchResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/batch", body, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Enrich looks up one column of a CSV file with a header row, and writes the
+// file to out with the selected fields and an error column appended to every
+// row. Nothing is written to out when the API rejects the file. Enriching is
+// not retried, since file can only be read once.
+func (c *Client) Enrich(ctx context.Context, file io.Reader, out io.Writer, opts EnrichOptions) (*EnrichSummary, error) {
+	query := url.Values{}
+	if opts.Column != "" {
+		query.Set("column", opts.Column)
+	}
+	if opts.Type != "" {
+		query.Set("type", opts.Type)
+	}
+	if len(opts.Fields) > 0 {
+		query.Set("fields", strings.Join(opts.Fields, ","))
+	}
+	path := "/api/v1/enrich"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	resp, err := c.send(ctx, http.MethodPost, path, "text/csv", file)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, newAPIError(resp)
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return nil, fmt.Errorf("bisnode api: failed to read enriched file: %w", err)
+	}
+	count := func(header string) int {
+		n, _ := strconv.Atoi(resp.Header.Get(header))
+		return n
+	}
+	return &EnrichSummary{
+		Rows:     count("X-Enrich-Rows"),
+		Enriched: count("X-Enrich-Enriched"),
+		NotFound: count("X-Enrich-Not-Found"),
+		Failed:   count("X-Enrich-Failed"),
+	}, nil
+}
+
+// SubmitJob starts looking up items in the background. Submitting is not
+// retried, since a retry could start the job twice.
+func (c *Client) SubmitJob(ctx context.Context, items []BatchItem) (*Job, error) {
+	body := struct {
+		Items []BatchItem `json:"items"`
+	}{items}
+
+	var job Job
+	if err := c.do(ctx, http.MethodPost, "/api/v1/jobs", body, &job, false); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Jobs lists the jobs, newest first
+func (c *Client) Jobs(ctx context.Context) ([]Job, error) {
+	var jobs []Job
+	if err := c.do(ctx, http.MethodGet, "/api/v1/jobs", nil, &jobs, true); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Job returns the status and progress of a job
+func (c *Client) Job(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodGet, "/api/v1/jobs/"+url.PathEscape(id), nil, &job, true); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// JobResults returns the results of a finished job. It fails with
+// ErrConflict while the job is queued or running.
+func (c *Client) JobResults(ctx context.Context, id string) (*JobResults, error) {
+	var results JobResults
+	if err := c.do(ctx, http.MethodGet, "/api/v1/jobs/"+url.PathEscape(id)+"/results", nil, &results, true); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// CancelJob stops a queued or running job. It fails with ErrConflict when
+// the job has already finished.
+func (c *Client) CancelJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodPost, "/api/v1/jobs/"+url.PathEscape(id)+"/cancel", nil, &job, false); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WaitForJob polls a job every interval until it has finished, and returns it
+func (c *Client) WaitForJob(ctx context.Context, id string, interval time.Duration) (*Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Finished() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// do sends a request with body encoded as JSON and decodes the response into
+// dst. Retryable failures are retried when retry is set.
+func (c *Client) do(ctx context.Context, method, path string, body, dst interface{}, retry bool) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("bisnode api: failed to encode request: %w", err)
+		}
+	}
+
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if data != nil {
+			reader = bytes.NewReader(data)
+		}
+		resp, err := c.send(ctx, method, path, "application/json", reader)
+		if err == nil && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+				return fmt.Errorf("bisnode api: failed to decode response: %w", err)
+			}
+			return nil
+		}
+
+		var delay time.Duration
+		if err == nil {
+			err = newAPIError(resp)
+			delay = retryAfter(resp)
+		}
+		if !retry || attempt >= c.maxRetries || !retryable(ctx, err) {
+			return err
+		}
+
+		delay = max(delay, wait)
+		wait *= 2
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// send sends a single request. contentType is the type of body, if any.
+func (c *Client) send(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("bisnode api: failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("bisnode api: %w", err)
+	}
+	return resp, nil
+}
+
+// newAPIError reads an error response. Bodies that are not the API's JSON
+// error format are used as the message as-is.
+func newAPIError(resp *http.Response) *APIError {
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	var body struct {
+		Error   string       `json:"error"`
+		Details []FieldError `json:"details"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		apiErr.Message, apiErr.Details = body.Error, body.Details
+	} else {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
+
+// retryable reports whether a failed request may succeed when sent again
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// Network errors
+		return true
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the wait asked for by a Retry-After header in seconds, or 0
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer serves fail as the status of the first failures requests,
+// and an empty job after that
+func newFlakyServer(t *testing.T, failures int32, fail int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(fail)
+			w.Write([]byte(`{"error":"try again"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"job1","status":"queued"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestClientRetriesLookups(t *testing.T) {
+	srv, calls := newFlakyServer(t, 2, http.StatusServiceUnavailable)
+	c := New(srv.URL, Options{RetryWait: time.Millisecond})
+
+	job, err := c.Job(context.Background(), "job1")
+	if err != nil {
+		t.Fatalf("Job() error = %v, want success after retrying", err)
+	}
+	if job.ID != "job1" {
+		t.Errorf("Job() = %+v, want job1", job)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("server received %d requests, want 3", n)
+	}
+}
+
+func TestClientGivesUpAfterMaxRetries(t *testing.T) {
+	srv, calls := newFlakyServer(t, 10, http.StatusTooManyRequests)
+	c := New(srv.URL, Options{MaxRetries: 2, RetryWait: time.Millisecond})
+
+	_, err := c.Job(context.Background(), "job1")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Job() error = %v, want ErrRateLimited", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("server received %d requests, want 1 and 2 retries", n)
+	}
+}
+
+func TestClientDoesNotRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		call   func(*Client) error
+	}{
+		{
+			name:   "job submission",
+			status: http.StatusServiceUnavailable,
+			call: func(c *Client) error {
+				_, err := c.SubmitJob(context.Background(), []BatchItem{{Type: BatchItemMobile, Value: "91234567"}})
+				return err
+			},
+		},
+		{
+			name:   "client error",
+			status: http.StatusBadRequest,
+			call: func(c *Client) error {
+				_, err := c.Job(context.Background(), "job1")
+				return err
+			},
+		},
+		{
+			name:   "internal server error",
+			status: http.StatusInternalServerError,
+			call: func(c *Client) error {
+				_, err := c.Job(context.Background(), "job1")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := newFlakyServer(t, 1, tt.status)
+			c := New(srv.URL, Options{RetryWait: time.Millisecond})
+
+			var apiErr *APIError
+			if err := tt.call(c); !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("error = %v, want an APIError with status %d", err, tt.status)
+			}
+			if n := calls.Load(); n != 1 {
+				t.Errorf("server received %d requests, want 1", n)
+			}
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		want        *APIError
+		is          error
+	}{
+		{
+			name:        "validation error",
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `{"error":"Invalid request","details":[{"field":"vin","message":"must be 17 characters"}]}`,
+			want:        &APIError{StatusCode: 400, Message: "Invalid request", Details: []FieldError{{Field: "vin", Message: "must be 17 characters"}}},
+			is:          ErrInvalidRequest,
+		},
+		{
+			name:        "not found",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"error":"job not found"}`,
+			want:        &APIError{StatusCode: 404, Message: "job not found"},
+			is:          ErrNotFound,
+		},
+		{
+			name:        "plain text",
+			status:      http.StatusInternalServerError,
+			contentType: "text/plain; charset=utf-8",
+			body:        "upstream connect error\n",
+			want:        &APIError{StatusCode: 500, Message: "upstream connect error"},
+			is:          ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := New(srv.URL, Options{MaxRetries: -1}).SearchVehicleByVIN(context.Background(), "x")
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want an APIError", err)
+			}
+			if apiErr.StatusCode != tt.want.StatusCode || apiErr.Message != tt.want.Message || len(apiErr.Details) != len(tt.want.Details) {
+				t.Errorf("error = %+v, want %+v", apiErr, tt.want)
+			}
+			if !errors.Is(err, tt.is) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestClientStopsRetryingWhenCancelled(t *testing.T) {
+	srv, calls := newFlakyServer(t, 10, http.StatusServiceUnavailable)
+	c := New(srv.URL, Options{RetryWait: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Job(ctx, "job1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Job() error = %v, want context.DeadlineExceeded", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Errors matched by errors.Is against an *APIError with the corresponding status
+var (
+	// ErrInvalidRequest is returned when the API rejects the input, with 400 Bad Request
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrUnauthorized is returned when the credentials are missing or wrong
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound is returned when a job or listing does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a job is not in a state that allows the request
+	ErrConflict = errors.New("conflict")
+	// ErrTooLarge is returned when a request has too many items
+	ErrTooLarge = errors.New("request too large")
+	// ErrRateLimited is returned when the API keeps answering 429 Too Many Requests
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnavailable is returned for server errors that remain after retrying
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// FieldError describes why a single input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError is an error response from the API. The API answers errors with
+// {"error": "...", "details": [...]}; Details is set for invalid input.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Details    []FieldError
+}
+
+// Error describes the error, including any rejected fields
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "bisnode api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		b.WriteString(": " + e.Message)
+	}
+	for _, d := range e.Details {
+		fmt.Fprintf(&b, "; %s %s", d.Field, d.Message)
+	}
+	return b.String()
+}
+
+// Is reports whether the status of e corresponds to target, one of the Err* errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInvalidRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnavailable:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// Field returns the message for a rejected field, and false when the field was not rejected
+func (e *APIError) Field(name string) (string, bool) {
+	for _, d := range e.Details {
+		if d.Field == name {
+			return d.Message, true
+		}
+	}
+	return "", false
+}
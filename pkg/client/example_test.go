@@ -0,0 +1,295 @@
+package client_test
+
+import (
+	"bisnode/docs"
+	"bisnode/internal/bisnodefake"
+	"bisnode/internal/config"
+	"bisnode/internal/enrich"
+	"bisnode/internal/geo"
+	"bisnode/internal/handlers"
+	"bisnode/internal/jobs"
+	"bisnode/internal/openapi"
+	"bisnode/internal/routes"
+	bisnodeservice "bisnode/internal/services/bisnode"
+	"bisnode/pkg/client"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+)
+
+// startAPI serves the API backed by a fake Bisnode API, and returns its
+// URL and a function that stops it
+func startAPI() (string, func()) {
+	upstream := httptest.NewServer(bisnodefake.New(bisnodefake.Options{}))
+
+	jobsDir, err := os.MkdirTemp("", "bisnode-client-example")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg := &config.BisnodeConfig{BaseURL: upstream.URL}
+	directoryService := bisnodeservice.NewDirectoryService(bisnodeservice.NewDirectoryClient(cfg), nil)
+	motorVehicleService := bisnodeservice.NewMotorVehicleService(bisnodeservice.NewMotorVehicleClient(cfg))
+	batchService := bisnodeservice.NewBatchService(directoryService, directoryService, motorVehicleService, &config.BatchConfig{MaxItems: 100, Concurrency: 4})
+	zipIndex := geo.NewZipIndex([]geo.ZipCode{{Code: "0155", City: "OSLO", Center: geo.Point{Latitude: 59.9111, Longitude: 10.7503}}})
+	geoService := bisnodeservice.NewGeoSearchService(directoryService, zipIndex, &config.GeoConfig{MaxRadiusMeters: 5000, ZipMarginMeters: 2000, MaxZipCodes: 20}, nil)
+	screeningService := bisnodeservice.NewScreeningService(directoryService, &config.ScreeningConfig{Concurrency: 2, MaxNumbers: 100, RetentionMinutes: 60, MaxRunning: 1, MaxStored: 10})
+
+	jobManager, err := jobs.NewManager(&config.JobsConfig{
+		Dir:            jobsDir,
+		MaxItems:       1000,
+		Concurrency:    4,
+		MaxRunning:     1,
+		RetentionHours: 1,
+	}, handlers.NewBatchJobProcessor(batchService))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	routes.RegisterDirectoryRoutes(mux, handlers.NewDirectoryHandler(directoryService, directoryService))
+	routes.RegisterMotorVehicleRoutes(mux, handlers.NewMotorVehicleHandler(motorVehicleService))
+	routes.RegisterGeoRoutes(mux, handlers.NewGeoHandler(geoService))
+	routes.RegisterScreeningRoutes(mux, handlers.NewScreeningHandler(screeningService))
+	routes.RegisterBatchRoutes(mux, handlers.NewBatchHandler(batchService))
+	routes.RegisterEnrichRoutes(mux, handlers.NewEnrichHandler(enrich.New(batchService.LookupItem, 4, 100)))
+	routes.RegisterJobRoutes(mux, handlers.NewJobHandler(jobManager))
+	doc, err := openapi.Parse(docs.OpenAPI)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Responses that do not match the OpenAPI document fail with a 500 error
+	api := httptest.NewServer(openapi.ValidateContract(mux, doc, openapi.ContractOptions{Fail: true}))
+
+	return api.URL, func() {
+		api.Close()
+		jobManager.Close()
+		upstream.Close()
+		os.RemoveAll(jobsDir)
+	}
+}
+
+func Example() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{Username: "user", Password: "secret"})
+
+	result, err := c.SearchPersonByMobileNumber(context.Background(), "+47 912 34 567", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, listing := range result.Result {
+		fmt.Println(listing.Name, listing.Address.City)
+	}
+	// Output:
+	// Ola Nordmann OSLO
+}
+
+func ExampleClient_SearchOrganization() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{})
+
+	result, err := c.SearchOrganization(context.Background(), "923 609 016")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(result.Result[0].Name)
+	// Output:
+	// Eksempel AS
+}
+
+func ExampleClient_SearchNearby() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{})
+
+	result, err := c.SearchNearby(context.Background(), client.NearbyQuery{
+		Center:        &client.Point{Latitude: 59.9111, Longitude: 10.7503},
+		Radius:        1000,
+		SearchOptions: client.SearchOptions{ListingType: "person"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, listing := range result.Result {
+		fmt.Printf("%s %.0f m\n", listing.Name, listing.DistanceMeters)
+	}
+	// Output:
+	// Ola Nordmann 0 m
+}
+
+func ExampleClient_Wash() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{})
+
+	result, err := c.Wash(context.Background(), client.ChannelTelemarketing, []string{"91234567", "98765432", "12"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, entry := range result.Result {
+		fmt.Println(entry.PhoneNumber, entry.Status)
+	}
+	// Output:
+	// 91234567 reserved
+	// 98765432 contactable
+	// 12 invalid
+}
+
+func ExampleClient_StartScreening() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{})
+	ctx := context.Background()
+
+	screening, err := c.StartScreening(ctx, client.ChannelDirectMail, []string{"91234567", "98765432"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := c.WaitForScreening(ctx, screening.ID, 10*time.Millisecond); err != nil {
+		log.Fatal(err)
+	}
+	report, err := c.ScreeningReport(ctx, screening.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(report.Status, report.Contactable, "of", report.Total)
+	for _, entry := range report.Result {
+		fmt.Println(entry.PhoneNumber, entry.Name, entry.Contactable)
+	}
+	// Output:
+	// completed 2 of 2
+	// 91234567 Ola Nordmann true
+	// 98765432 Kari Nordmann true
+}
+
+func ExampleClient_SearchVehicleByLicenseNumber() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{})
+
+	result, err := c.SearchVehicleByLicenseNumber(context.Background(), "AB 12345")
+	if err != nil {
+		log.Fatal(err)
+	}
+	vehicle := result.Result[0]
+	fmt.Println(vehicle.RegNo, vehicle.BrandName, vehicle.Owner.OrganizationNumber)
+	// Output:
+	// AB12345 BMW 923609016
+}
+
+func ExampleClient_Batch() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{})
+
+	result, err := c.Batch(context.Background(), []client.BatchItem{
+		{Type: client.BatchItemOrganization, Value: "923609016"},
+		{Type: client.BatchItemMobile, Value: "12"},
+		{Type: client.BatchItemPlate, Value: "AB12345"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, item := range result.Items {
+		fmt.Println(item.Type, item.Value, item.Status)
+	}
+	// Output:
+	// orgno 923609016 ok
+	// mobile 12 invalid
+	// plate AB12345 ok
+}
+
+func ExampleClient_Enrich() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{})
+
+	file := strings.NewReader("Customer,Org No\n1,923609016\n2,000000000\n")
+	summary, err := c.Enrich(context.Background(), file, os.Stdout, client.EnrichOptions{
+		Column: "Org No",
+		Fields: []string{"name", "city"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(summary.Enriched, "enriched,", summary.NotFound, "not found")
+	// Output:
+	// Customer,Org No,name,city,error
+	// 1,923609016,Eksempel AS,OSLO,
+	// 2,000000000,,,invalid orgno: organization number must start with 8 or 9
+	// 1 enriched, 0 not found
+}
+
+func ExampleClient_SubmitJob() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{})
+	ctx := context.Background()
+
+	job, err := c.SubmitJob(ctx, []client.BatchItem{
+		{Type: client.BatchItemMobile, Value: "91234567"},
+		{Type: client.BatchItemMobile, Value: "98765432"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	job, err = c.WaitForJob(ctx, job.ID, 10*time.Millisecond)
+	if err != nil {
+		log.Fatal(err)
+	}
+	results, err := c.JobResults(ctx, job.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(job.Status, job.Succeeded)
+	for _, item := range results.Items {
+		result, err := item.SearchResult()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(item.Value, result.Result[0].Name)
+	}
+	// Output:
+	// completed 2
+	// 91234567 Ola Nordmann
+	// 98765432 Kari Nordmann
+}
+
+func ExampleAPIError() {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	c := client.New(apiURL, client.Options{})
+
+	_, err := c.SearchOrganization(context.Background(), "123")
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		message, _ := apiErr.Field("organizationNumber")
+		fmt.Println(apiErr.StatusCode, errors.Is(err, client.ErrInvalidRequest))
+		fmt.Println(message)
+	}
+	// Output:
+	// 400 true
+	// organization number must have 9 digits
+}
@@ -0,0 +1,487 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Freshness describes how old a response served from the API's cache is
+type Freshness struct {
+	// Status is one of "fresh", "stale" or "stale-if-error"
+	Status string `json:"status"`
+	// FetchedAt is when the data was retrieved from Bisnode
+	FetchedAt time.Time `json:"fetchedAt"`
+	// AgeSeconds is how old the data was when it was served
+	AgeSeconds int `json:"ageSeconds"`
+}
+
+// SearchOptions are the Bisnode search options. Options left out use the
+// server's configured defaults.
+type SearchOptions struct {
+	// SearchMode is "exact", "phonetic" or "smart"
+	SearchMode string `json:"searchMode,omitempty"`
+	// ListingType is "all", "company" or "person"
+	ListingType    string `json:"listingType,omitempty"`
+	OnlyFoundWords *bool  `json:"onlyFoundWords,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+	// Channel is "telemarketing", "directMail" or "humanitarian". Listings
+	// are checked against their reservations for it when set.
+	Channel string `json:"channel,omitempty"`
+	// ReservationPolicy is "flag" (default) or "suppress", and only applies with a Channel
+	ReservationPolicy string `json:"reservationPolicy,omitempty"`
+}
+
+// PersonQuery holds the name and address fields of a person search; at least one must be set
+type PersonQuery struct {
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Street    string `json:"street,omitempty"`
+	ZipCode   string `json:"zipCode,omitempty"`
+	City      string `json:"city,omitempty"`
+	SearchOptions
+}
+
+// OrganizationQuery holds the name and filters of an organization name search
+type OrganizationQuery struct {
+	Name    string `json:"name"`
+	City    string `json:"city,omitempty"`
+	ZipCode string `json:"zipCode,omitempty"`
+	SearchOptions
+}
+
+// Age is the age of a person
+type Age struct {
+	Years  int `json:"years"`
+	Months int `json:"months"`
+	Days   int `json:"days"`
+}
+
+// Reservation holds the marketing channels a person has reserved against
+type Reservation struct {
+	DirectMail    bool `json:"directMail"`
+	Telemarketing bool `json:"telemarketing"`
+	Humanitarian  bool `json:"humanitarian"`
+}
+
+// Point is a location in WGS 84 coordinates
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Dates records when Bisnode first and last saw a piece of information
+type Dates struct {
+	FirstAcquired      *time.Time `json:"firstAcquired,omitempty"`
+	LastAcquired       *time.Time `json:"lastAcquired,omitempty"`
+	InformationChanged *time.Time `json:"informationChanged,omitempty"`
+}
+
+// Address is a postal address with its provenance
+type Address struct {
+	Type       string `json:"type,omitempty"`
+	StreetName string `json:"streetName,omitempty"`
+	HouseNo    string `json:"houseNo,omitempty"`
+	Entrance   string `json:"entrance,omitempty"`
+	ZipCode    string `json:"zipCode,omitempty"`
+	City       string `json:"city,omitempty"`
+	Location   *Point `json:"location,omitempty"`
+	Source     string `json:"source,omitempty"`
+	// Quality is "high", "medium", "low" or "unknown"
+	Quality string `json:"quality,omitempty"`
+	Dates   Dates  `json:"dates"`
+}
+
+// Phone is a phone number with its provenance
+type Phone struct {
+	Type    string `json:"type,omitempty"`
+	Number  string `json:"number"`
+	Source  string `json:"source,omitempty"`
+	Quality string `json:"quality,omitempty"`
+	Dates   Dates  `json:"dates"`
+}
+
+// Listing is a person or company in the directory
+type Listing struct {
+	// Type is "person", "company" or "unknown"
+	Type               string `json:"type"`
+	OrganizationNumber string `json:"organizationNumber,omitempty"`
+	// Name is the full name of a person, or the name of a company
+	Name        string      `json:"name"`
+	FirstName   string      `json:"firstName,omitempty"`
+	MiddleName  string      `json:"middleName,omitempty"`
+	LastName    string      `json:"lastName,omitempty"`
+	Born        *time.Time  `json:"born,omitempty"`
+	Dead        *time.Time  `json:"dead,omitempty"`
+	Age         *Age        `json:"age,omitempty"`
+	Gender      string      `json:"gender,omitempty"`
+	Address     Address     `json:"address"`
+	Telephone   string      `json:"telephone,omitempty"`
+	Mobile      string      `json:"mobile,omitempty"`
+	Reservation Reservation `json:"reservation"`
+	// Contactable is set when a Channel was given, and is false when the reservation forbids it
+	Contactable *bool `json:"contactable,omitempty"`
+	// Addresses and Phones are the current and previous registrations
+	Addresses []Address `json:"addresses,omitempty"`
+	Phones    []Phone   `json:"phones,omitempty"`
+}
+
+// SearchResult is the response of a person or organization number search
+type SearchResult struct {
+	Result    []Listing  `json:"result"`
+	Freshness *Freshness `json:"freshness,omitempty"`
+	// Channel and Suppressed are set when results were checked against a marketing channel
+	Channel    string `json:"channel,omitempty"`
+	Suppressed int    `json:"suppressed,omitempty"`
+}
+
+// TimelineEntry is an address or phone number merged from every registration
+// of it, with the period it was registered in
+type TimelineEntry struct {
+	// Kind is "address" or "phone"
+	Kind string `json:"kind"`
+	// Value is the address on one line, or the phone number
+	Value         string     `json:"value"`
+	Address       *Address   `json:"address,omitempty"`
+	From          *time.Time `json:"from,omitempty"`
+	To            *time.Time `json:"to,omitempty"`
+	Current       bool       `json:"current"`
+	Sources       []string   `json:"sources,omitempty"`
+	Quality       string     `json:"quality"`
+	Confidence    float64    `json:"confidence"`
+	Registrations int        `json:"registrations"`
+}
+
+// History is the address and phone history of a listing
+type History struct {
+	Name               string          `json:"name"`
+	OrganizationNumber string          `json:"organizationNumber,omitempty"`
+	Addresses          []TimelineEntry `json:"addresses"`
+	Phones             []TimelineEntry `json:"phones"`
+	// Timeline holds both addresses and phone numbers, oldest first
+	Timeline []TimelineEntry `json:"timeline"`
+}
+
+// HistoryResult is the history of every listing matching a mobile number
+type HistoryResult struct {
+	Result    []History  `json:"result"`
+	Freshness *Freshness `json:"freshness,omitempty"`
+}
+
+// OrganizationSummary is a compact search result for an organization. The
+// organization number can be used to look up the full record.
+type OrganizationSummary struct {
+	OrganizationNumber string `json:"organizationNumber"`
+	Name               string `json:"name"`
+	StreetName         string `json:"streetName,omitempty"`
+	HouseNo            string `json:"houseNo,omitempty"`
+	ZipCode            string `json:"zipCode,omitempty"`
+	City               string `json:"city,omitempty"`
+}
+
+// OrganizationSearchResult is the response of an organization name search
+type OrganizationSearchResult struct {
+	Result    []OrganizationSummary `json:"result"`
+	Freshness *Freshness            `json:"freshness,omitempty"`
+}
+
+// BoundingBox is an area between a south-west and a north-east corner
+type BoundingBox struct {
+	Min Point
+	Max Point
+}
+
+// NearbyQuery is the area of a nearby search: either Center with a Radius,
+// or Box
+type NearbyQuery struct {
+	Center *Point
+	// Radius is in meters around Center
+	Radius float64
+	Box    *BoundingBox
+	SearchOptions
+}
+
+// NearbyListing is a listing found by a nearby search
+type NearbyListing struct {
+	Listing
+	DistanceMeters float64 `json:"distanceMeters"`
+}
+
+// NearbyResult is the response of a nearby search, nearest first. For a
+// bounding box, Center is its center and RadiusMeters reaches its corners.
+type NearbyResult struct {
+	Center       Point           `json:"center"`
+	RadiusMeters float64         `json:"radiusMeters"`
+	Result       []NearbyListing `json:"result"`
+	// Channel and Suppressed are set when results were checked against a marketing channel
+	Channel    string `json:"channel,omitempty"`
+	Suppressed int    `json:"suppressed,omitempty"`
+}
+
+// Marketing channels that listings can be reserved against
+const (
+	ChannelTelemarketing = "telemarketing"
+	ChannelDirectMail    = "directMail"
+	ChannelHumanitarian  = "humanitarian"
+)
+
+// Wash statuses
+const (
+	WashContactable = "contactable"
+	WashReserved    = "reserved"
+	WashNotFound    = "not_found"
+	WashInvalid     = "invalid"
+	WashError       = "error"
+)
+
+// WashEntry is the verdict for one phone number. Only numbers with status
+// WashContactable may be contacted; a number without a listing has no known
+// reservation.
+type WashEntry struct {
+	PhoneNumber string `json:"phoneNumber"`
+	Contactable bool   `json:"contactable"`
+	// Status is one of the Wash* statuses
+	Status      string       `json:"status"`
+	Name        string       `json:"name,omitempty"`
+	Reservation *Reservation `json:"reservation,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// WashResult is the response of washing a list of phone numbers, in request order
+type WashResult struct {
+	Channel     string      `json:"channel"`
+	Contactable int         `json:"contactable"`
+	Result      []WashEntry `json:"result"`
+}
+
+// Screening statuses
+const (
+	ScreeningRunning   = "running"
+	ScreeningCompleted = "completed"
+)
+
+// Screening describes a phone list being checked in the background and its progress
+type Screening struct {
+	ID      string `json:"id"`
+	Channel string `json:"channel"`
+	// Status is one of the Screening* statuses
+	Status      string     `json:"status"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Contactable int        `json:"contactable"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Finished reports whether the screening has completed and its report is ready
+func (s Screening) Finished() bool {
+	return s.Status == ScreeningCompleted
+}
+
+// ScreeningReport is the verdict for every number of a screening, in the order they were submitted
+type ScreeningReport struct {
+	Screening
+	Result []WashEntry `json:"result"`
+}
+
+// VehicleSearchResult is the response of a motor vehicle search. Vehicles
+// are returned with Bisnode's field names.
+type VehicleSearchResult struct {
+	Result  []Vehicle      `json:"Result"`
+	Service VehicleService `json:"Service"`
+	// Identifier is the classified search term, including decoded VIN information
+	Identifier *VehicleIdentifier `json:"identifier,omitempty"`
+	Freshness  *Freshness         `json:"freshness,omitempty"`
+}
+
+// VehicleService describes the Bisnode dataset that answered a vehicle search
+type VehicleService struct {
+	Dataset       string `json:"dataset"`
+	Documentation string `json:"documentation"`
+	Version       string `json:"version"`
+	Timestamp     string `json:"timestamp"`
+	Message       string `json:"message"`
+}
+
+// VehicleIdentifier is a license plate or VIN as classified by the API
+type VehicleIdentifier struct {
+	// Kind is "standard_plate", "trailer_plate", "diplomatic_plate", "personalized_plate" or "vin"
+	Kind string `json:"kind"`
+	// Value is the normalized identifier
+	Value string `json:"value"`
+	// VIN holds the decoded VIN when Kind is "vin"
+	VIN *VINInfo `json:"vin,omitempty"`
+}
+
+// VINInfo is the information decoded from a VIN
+type VINInfo struct {
+	WMI                string `json:"wmi"`
+	Manufacturer       string `json:"manufacturer,omitempty"`
+	Region             string `json:"region"`
+	Country            string `json:"country,omitempty"`
+	ModelYear          int    `json:"modelYear,omitempty"`
+	CheckDigitRequired bool   `json:"checkDigitRequired"`
+	CheckDigitValid    bool   `json:"checkDigitValid"`
+	SerialNumber       string `json:"serialNumber"`
+}
+
+// Vehicle is a motor vehicle record. Only the most used fields are decoded;
+// the full record is available in Raw.
+type Vehicle struct {
+	RegNo              string       `json:"regno"`
+	PersonalPlates     string       `json:"personalplates"`
+	ChassisNo          string       `json:"chassisno"`
+	RegYear            string       `json:"regyear"`
+	ModelYear          string       `json:"modelyear"`
+	BrandName          string       `json:"brandname"`
+	Model              string       `json:"model"`
+	RegDate            string       `json:"regdate"`
+	ColorText          string       `json:"colortext"`
+	RegStatus          string       `json:"regstatus"`
+	LastInspectionDate string       `json:"lastinspectiondate"`
+	NextInspectionDate string       `json:"nextinspectiondate"`
+	Owner              VehicleOwner `json:"Owner"`
+	CoOwner            VehicleOwner `json:"CoOwner"`
+	LeasingUser        VehicleOwner `json:"LeasingUser"`
+	// Raw is the vehicle record as returned by the API
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes a vehicle and keeps the full record in Raw
+func (v *Vehicle) UnmarshalJSON(data []byte) error {
+	type plain Vehicle
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+	v.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// VehicleOwner is the owner, co-owner or leasing user of a vehicle. Companies
+// have an organization number; persons have a birth date.
+type VehicleOwner struct {
+	OrganizationNumber string `json:"organizationnumber"`
+	Born               string `json:"born"`
+	Name               string `json:"name"`
+	Address            string `json:"address"`
+	Zipcode            string `json:"zipcode"`
+	City               string `json:"city"`
+}
+
+// BatchItem is a single lookup in a batch or job
+type BatchItem struct {
+	// Type is one of the BatchItem* types
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Batch item types
+const (
+	BatchItemOrganization = "orgno"
+	BatchItemMobile       = "mobile"
+	BatchItemPlate        = "plate"
+	BatchItemVIN          = "vin"
+)
+
+// Batch item statuses
+const (
+	BatchStatusOK       = "ok"
+	BatchStatusNotFound = "not_found"
+	BatchStatusInvalid  = "invalid"
+	BatchStatusError    = "error"
+)
+
+// BatchItemResult is the result of a single batch item
+type BatchItemResult struct {
+	Index int    `json:"index"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	// Status is one of the BatchStatus* values
+	Status string `json:"status"`
+	// Result is a SearchResult for orgno and mobile items, and a
+	// VehicleSearchResult for plate and vin items; use SearchResult or
+	// VehicleResult to decode it
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Details []FieldError    `json:"details,omitempty"`
+}
+
+// SearchResult decodes the result of an orgno or mobile item
+func (r BatchItemResult) SearchResult() (*SearchResult, error) {
+	var result SearchResult
+	if err := json.Unmarshal(r.Result, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// VehicleResult decodes the result of a plate or vin item
+func (r BatchItemResult) VehicleResult() (*VehicleSearchResult, error) {
+	var result VehicleSearchResult
+	if err := json.Unmarshal(r.Result, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BatchResult is the response of a batch lookup, with the items in request order
+type BatchResult struct {
+	Succeeded int               `json:"succeeded"`
+	NotFound  int               `json:"notFound"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
+
+// EnrichOptions selects the column of a CSV file to look up and the fields to append
+type EnrichOptions struct {
+	// Column is the header of the column to look up; it is guessed from the header when empty
+	Column string
+	// Type is the lookup held by the column, one of the BatchItem* types; it
+	// is guessed from the header when empty
+	Type string
+	// Fields are the columns to append, such as "name" and "city"; the server
+	// appends a default set for the type when empty
+	Fields []string
+}
+
+// EnrichSummary counts the rows of an enriched file
+type EnrichSummary struct {
+	Rows     int
+	Enriched int
+	NotFound int
+	Failed   int
+}
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobCancelled = "cancelled"
+	JobFailed    = "failed"
+)
+
+// Job describes a background batch lookup and its progress
+type Job struct {
+	ID string `json:"id"`
+	// Status is one of the Job* statuses
+	Status      string     `json:"status"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Succeeded   int        `json:"succeeded"`
+	Failed      int        `json:"failed"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Finished reports whether the job has stopped and will not run again
+func (j Job) Finished() bool {
+	return j.Status == JobCompleted || j.Status == JobCancelled || j.Status == JobFailed
+}
+
+// JobResults holds the results of a finished job, in item order
+type JobResults struct {
+	Job   Job               `json:"job"`
+	Items []BatchItemResult `json:"items"`
+}
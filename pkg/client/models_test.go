@@ -0,0 +1,121 @@
+package client_test
+
+import (
+	"bisnode/pkg/client"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeStrict sends a request to the API and decodes the response into dst,
+// failing on any field the client types do not know
+func decodeStrict(t *testing.T, apiURL, method, path, body string, wantStatus int, dst interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, apiURL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var data bytes.Buffer
+	data.ReadFrom(resp.Body)
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("%s %s status = %d, want %d: %s", method, path, resp.StatusCode, wantStatus, data.String())
+	}
+	decodeBytes(t, data.Bytes(), dst)
+}
+
+// decodeBytes decodes data into dst, failing on unknown fields
+func decodeBytes(t *testing.T, data []byte, dst interface{}) {
+	t.Helper()
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		t.Errorf("decoding %T: %v\n%s", dst, err, data)
+	}
+}
+
+// TestModelsMatchAPI decodes the responses of the real handlers into the
+// client types, so a field added to the API without updating the client
+// fails here. The fields of vehicle records are not checked, since Vehicle
+// decodes only the most used of Bisnode's fields on purpose.
+func TestModelsMatchAPI(t *testing.T) {
+	apiURL, stop := startAPI()
+	defer stop()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		dst    interface{}
+	}{
+		{"person search", http.MethodPost, "/api/v1/directory/persons/search", `{"mobileNumber": "91234567", "channel": "telemarketing"}`, http.StatusOK, new(client.SearchResult)},
+		{"person search by name", http.MethodPost, "/api/v1/directory/persons/search", `{"firstName": "Kari", "lastName": "Nordmann"}`, http.StatusOK, new(client.SearchResult)},
+		{"person history", http.MethodGet, "/api/v1/directory/persons/91234567/history", "", http.StatusOK, new(client.HistoryResult)},
+		{"organization search", http.MethodPost, "/api/v1/directory/organizations/search", `{"organizationNumber": "923609016"}`, http.StatusOK, new(client.SearchResult)},
+		{"organization name search", http.MethodPost, "/api/v1/directory/organizations/search-by-name", `{"name": "Eksempel"}`, http.StatusOK, new(client.OrganizationSearchResult)},
+		{"nearby search", http.MethodPost, "/api/v1/directory/nearby", `{"latitude": 59.9111, "longitude": 10.7503, "radius": 1000, "channel": "directMail"}`, http.StatusOK, new(client.NearbyResult)},
+		{"wash", http.MethodPost, "/api/v1/directory/wash", `{"channel": "telemarketing", "phoneNumbers": ["91234567", "98765432", "12"]}`, http.StatusOK, new(client.WashResult)},
+		{"vehicle search", http.MethodPost, "/api/v1/motor-vehicles/search", `{"vin": "WBAKG7C5XBE123456"}`, http.StatusOK, new(client.VehicleSearchResult)},
+		{"batch", http.MethodPost, "/api/v1/batch", `{"items": [{"type": "orgno", "value": "923609016"}, {"type": "plate", "value": "AB12345"}, {"type": "mobile", "value": "1"}]}`, http.StatusOK, new(client.BatchResult)},
+		{"jobs", http.MethodGet, "/api/v1/jobs", "", http.StatusOK, new([]client.Job)},
+		{"invalid input", http.MethodPost, "/api/v1/directory/wash", `{"channel": "email", "phoneNumbers": []}`, http.StatusBadRequest, new(struct {
+			Error   string              `json:"error"`
+			Details []client.FieldError `json:"details"`
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decodeStrict(t, apiURL, tt.method, tt.path, tt.body, tt.status, tt.dst)
+		})
+	}
+
+	// The results of batch items are decoded on demand, so check them too
+	t.Run("batch item results", func(t *testing.T) {
+		var batch client.BatchResult
+		decodeStrict(t, apiURL, http.MethodPost, "/api/v1/batch", `{"items": [{"type": "orgno", "value": "923609016"}, {"type": "vin", "value": "WBAKG7C5XBE123456"}]}`, http.StatusOK, &batch)
+		if len(batch.Items) != 2 {
+			t.Fatalf("items = %+v, want 2", batch.Items)
+		}
+		decodeBytes(t, batch.Items[0].Result, new(client.SearchResult))
+		decodeBytes(t, batch.Items[1].Result, new(client.VehicleSearchResult))
+	})
+
+	c := client.New(apiURL, client.Options{})
+	ctx := context.Background()
+
+	t.Run("screening", func(t *testing.T) {
+		var screening client.Screening
+		decodeStrict(t, apiURL, http.MethodPost, "/api/v1/directory/screenings", `{"channel": "directMail", "phoneNumbers": ["91234567", "12"]}`, http.StatusAccepted, &screening)
+		if _, err := c.WaitForScreening(ctx, screening.ID, 10*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		decodeStrict(t, apiURL, http.MethodGet, "/api/v1/directory/screenings/"+screening.ID, "", http.StatusOK, new(client.Screening))
+		decodeStrict(t, apiURL, http.MethodGet, "/api/v1/directory/screenings/"+screening.ID+"/report?format=json", "", http.StatusOK, new(client.ScreeningReport))
+	})
+
+	t.Run("job", func(t *testing.T) {
+		var job client.Job
+		decodeStrict(t, apiURL, http.MethodPost, "/api/v1/jobs", `{"items": [{"type": "orgno", "value": "923609016"}, {"type": "orgno", "value": "1"}]}`, http.StatusAccepted, &job)
+		if _, err := c.WaitForJob(ctx, job.ID, 10*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		decodeStrict(t, apiURL, http.MethodGet, "/api/v1/jobs/"+job.ID, "", http.StatusOK, new(client.Job))
+		decodeStrict(t, apiURL, http.MethodGet, "/api/v1/jobs/"+job.ID+"/results", "", http.StatusOK, new(client.JobResults))
+	})
+}